@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add owner/repo[@version]",
+	Short: "Add a binary to a --config manifest",
+	Long: `Add owner/repo to the TOML manifest given with --config (default tools.toml),
+creating the manifest if needed. Existing entries and comments are left untouched.
+
+Without a version, the tag of the release that --select picks is pinned. Use --binName
+to set the name the binary is saved as.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeInstalledRepos,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pa, err := utils.ParseArgs(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid argument: %w", err)
+		}
+		path := configFlag
+		if path == "" {
+			path = defaultConfigPath
+		}
+
+		version := pa.Version
+		if version == "latest" {
+			ctx := cmd.Context()
+			client, err := newClient(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize GitHub client: %v", err)
+			}
+			if version, err = pinnedVersion(ctx, client, pa); err != nil {
+				return err
+			}
+		}
+
+		bc := config.BinaryConfig{
+			Key:     pa.Owner + "/" + pa.Repo,
+			Name:    binNameFlag,
+			Version: version,
+		}
+		if err := config.Append(path, bc); err != nil {
+			return err
+		}
+		utils.Logger.Infof("%s Added %s@%s to %s", install.Green("✔"), bc.Key, bc.Version, path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+}
+
+// pinnedVersion returns the tag add pins for pa when it asks for the latest release:
+// the tag the --select policy picks, as installing pa would, or with github-latest the
+// tag of the latest release.
+func pinnedVersion(
+	ctx context.Context,
+	client *github.Client,
+	pa utils.ParsedArgs,
+) (string, error) {
+	version, err := selectedVersion(ctx, client, pa)
+	if err != nil || version != "latest" {
+		return version, err
+	}
+	apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+	defer cancel()
+	release, err := install.ResolveRelease(apiCtx, client, pa, prereleaseFlag, draftsFlag)
+	if err != nil {
+		return "", err
+	}
+	return release.GetTagName(), nil
+}
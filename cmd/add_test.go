@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_addCmd_pinnedVersion(t *testing.T) {
+	utils.CreateLogger(false)
+	configFlag = filepath.Join(t.TempDir(), "tools.toml")
+	binNameFlag = "toml-fmt"
+	defer func() { configFlag, binNameFlag = "", "" }()
+
+	if err := addCmd.RunE(addCmd, []string{"esacteksab/go-pretty-toml@v0.1.1"}); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	if err := addCmd.RunE(addCmd, []string{"esacteksab/go-pretty-toml@v0.2.0"}); err == nil {
+		t.Error("adding an entry twice succeeded, want an error")
+	}
+
+	cfg, err := config.LoadFromFile(configFlag)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	want := config.BinaryConfig{
+		Key:     "esacteksab/go-pretty-toml",
+		Name:    "toml-fmt",
+		Version: "v0.1.1",
+	}
+	if got := cfg.Binaries[want.Key]; got != want || len(cfg.Binaries) != 1 {
+		t.Errorf("manifest = %v, want only %v", cfg.Binaries, want)
+	}
+}
+
+func Test_pinnedVersion(t *testing.T) {
+	utils.CreateLogger(false)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/tool/releases/latest":
+				fmt.Fprint(w, `{"tag_name":"v1.9.0"}`) //nolint:errcheck
+			case "/repos/owner/tool/releases":
+				fmt.Fprint(w, selectTestReleases) //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	tests := []struct {
+		name   string
+		arg    string
+		policy string
+		want   string
+	}{
+		{name: "github latest", arg: "owner/tool", policy: selectGitHubLatest, want: "v1.9.0"},
+		{name: "highest semver", arg: "owner/tool", policy: selectHighestSemver, want: "v2.1.0"},
+		{name: "newest by date", arg: "owner/tool", policy: selectNewestByDate, want: "v1.9.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectFlag = tt.policy
+			defer func() { selectFlag = selectGitHubLatest }()
+
+			pa, err := utils.ParseArgs(tt.arg)
+			if err != nil {
+				t.Fatalf("ParseArgs() error = %v", err)
+			}
+			got, err := pinnedVersion(context.Background(), client, pa)
+			if err != nil {
+				t.Fatalf("pinnedVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("pinnedVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
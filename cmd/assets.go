@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+var assetsCmd = &cobra.Command{
+	Use:   "assets owner/repo[@version]",
+	Short: "List the assets of a release and which one would be installed",
+	Long: `List every asset of a release with its size, whether it matches this system's
+OS/Arch (or --os and --arch) and what it is: a checksum file, signature, SBOM, system
+package and so on. The asset gh install would select is marked, so mismatches can be
+debugged without downloading anything. --content-type is taken into account.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(outputFlag); err != nil {
+			return err
+		}
+		pa, err := utils.ParseArgs(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid argument: %w", err)
+		}
+
+		ctx := cmd.Context()
+		client, err := newClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize GitHub client: %v", err)
+		}
+		apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+		defer cancel()
+		release, err := install.ResolveRelease(apiCtx, client, pa, prereleaseFlag, draftsFlag)
+		if err != nil {
+			return err
+		}
+
+		scan := install.SelectAssets(
+			release.Assets,
+			release.GetTagName(),
+			install.InstallOptions{ContentTypes: contentTypeFlag},
+			utils.ResolveOSFamily(osFamilyFlag),
+			platformOptions().Matcher(),
+		)
+		if outputFlag == outputJSON {
+			return writeJSON(cmd.OutOrStdout(), newAssetReports(scan))
+		}
+		return printAssetsTable(cmd.OutOrStdout(), scan, release.GetTagName())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(assetsCmd)
+}
+
+// assetReport describes a release asset as printed by assets --output json.
+type assetReport struct {
+	Name     string            `json:"name"`
+	Size     int               `json:"size"`
+	Kind     install.AssetKind `json:"kind"`
+	Matches  bool              `json:"matches"`
+	Selected bool              `json:"selected"` // Whether it's the asset that would be installed
+}
+
+// newAssetReports builds the report of every asset in scan.
+func newAssetReports(scan install.AssetSelection) []assetReport {
+	reports := make([]assetReport, 0, len(scan.Assets))
+	for _, scanned := range scan.Assets {
+		reports = append(reports, assetReport{
+			Name:     scanned.Asset.GetName(),
+			Size:     scanned.Asset.GetSize(),
+			Kind:     scanned.Kind,
+			Matches:  scanned.Matches,
+			Selected: scanned.Asset == scan.Main && len(scan.Tied) == 0,
+		})
+	}
+	return reports
+}
+
+// printAssetsTable writes the assets in scan to w as an aligned table, followed by
+// the asset and checksum file that would be used.
+func printAssetsTable(w io.Writer, scan install.AssetSelection, tag string) error {
+	if len(scan.Assets) == 0 {
+		_, err := fmt.Fprintf(w, "Release %s has no assets.\n", tag)
+		return err
+	}
+
+	goos, goarch := platformOptions().Platform()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)               //nolint:mnd
+	fmt.Fprintf(tw, "ASSET\tSIZE\t%s/%s\tKIND\n", goos, goarch) //nolint:errcheck
+	for _, scanned := range scan.Assets {
+		match := "-" // Only binaries and packages are matched against the OS/Arch
+		if scanned.Kind == install.KindCandidate || scanned.Kind == install.KindPackage {
+			match = install.Red("✘")
+			if scanned.Matches {
+				match = install.Green("✔")
+			}
+		}
+		kind := string(scanned.Kind)
+		switch {
+		case slices.Contains(scan.Tied, scanned.Asset):
+			kind += " (tied)"
+		case scanned.Asset == scan.Main:
+			kind += " (selected)"
+		case scanned.Asset == scan.Fallback:
+			kind += " (Rosetta 2)"
+		case scanned.Asset == scan.Checksum:
+			kind += " (used)"
+		}
+		fmt.Fprintf( //nolint:errcheck
+			tw,
+			"%s\t%s\t%s\t%s\n",
+			scanned.Asset.GetName(),
+			utils.HumanBytes(int64(scanned.Asset.GetSize())),
+			match,
+			kind,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	selected := "none, no asset matches"
+	switch {
+	case len(scan.Tied) > 0:
+		selected = "none, several assets match equally well; choose one with --asset"
+	case scan.Main != nil:
+		selected = scan.Main.GetName()
+	}
+	checksum := "none"
+	if scan.Checksum != nil {
+		checksum = scan.Checksum.GetName()
+	}
+	_, err := fmt.Fprintf(w, "\nWould install: %s\nChecksum file: %s\n", selected, checksum)
+	return err
+}
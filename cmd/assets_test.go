@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/internal/testutil"
+)
+
+func Test_printAssetsTable(t *testing.T) {
+	osFlag, archFlag = "linux", "amd64"
+	t.Cleanup(func() { osFlag, archFlag = "", "" })
+
+	scan := install.SelectAssets(
+		testutil.Assets("tool_linux_amd64.tar.gz", "tool_darwin_arm64.tar.gz", "checksums.txt"),
+		"v1.0.0",
+		install.InstallOptions{},
+		"",
+		platformOptions().Matcher(),
+	)
+	var out bytes.Buffer
+	if err := printAssetsTable(&out, scan, "v1.0.0"); err != nil {
+		t.Fatalf("printAssetsTable() error = %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	wantRows := [][]string{
+		{"ASSET", "SIZE", "linux/amd64", "KIND"},
+		{"tool_linux_amd64.tar.gz", "1.0", "KiB", "✔", "binary", "(selected)"},
+		{"tool_darwin_arm64.tar.gz", "2.0", "KiB", "✘", "binary"},
+		{"checksums.txt", "3.0", "KiB", "-", "checksum", "(used)"},
+	}
+	for i, want := range wantRows {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("printAssetsTable() line %d = %q, want %q", i, got, want)
+		}
+	}
+	for _, want := range []string{
+		"Would install: tool_linux_amd64.tar.gz",
+		"Checksum file: checksums.txt",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printAssetsTable() output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	var empty bytes.Buffer
+	if err := printAssetsTable(&empty, install.AssetSelection{}, "v1.0.0"); err != nil {
+		t.Fatalf("printAssetsTable() error = %v", err)
+	}
+	if !strings.Contains(empty.String(), "has no assets") {
+		t.Errorf("printAssetsTable() with no assets = %q", empty.String())
+	}
+}
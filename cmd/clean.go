@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/ghclient"
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+var cleanAllFlag bool // cleanAllFlag is the value from the clean --all flag
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove cached GitHub API responses",
+	Long: `Remove the cache of GitHub API responses and resolved latest releases.
+With --all, the record of binaries installed by gh install is removed too; the
+binaries themselves are left in place.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := cleanTargets(cleanAllFlag)
+		if err != nil {
+			return err
+		}
+		if err := confirmClean(os.Stdin, os.Stderr, install.IsInteractive(), targets); err != nil {
+			return err
+		}
+		freed, err := removeTargets(targets)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Freed %s.\n", utils.HumanBytes(freed))
+		return err
+	},
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(
+		&cleanAllFlag,
+		"all",
+		false,
+		"also remove the record of installed binaries used by list, upgrade and verify",
+	)
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// cleanTargets returns the files and directories clean removes.
+//
+// -all: Whether to include the install-state file.
+// Returns: The paths, and an error if the cache directory can't be determined.
+func cleanTargets(all bool) ([]string, error) {
+	cachePath, err := ghclient.CachePath(cacheDirFlag)
+	if err != nil {
+		return nil, err
+	}
+	targets := []string{cachePath, state.LatestFilePath()}
+	if all {
+		targets = append(targets, state.FilePath())
+	}
+	return targets, nil
+}
+
+// confirmClean asks whether to remove targets, unless --yes was given. Like
+// install.ConfirmSystemPackage, it requires --yes when there's no terminal to answer on.
+//
+// -in: Where the answer is read from.
+// -out: Where the prompt is written to.
+// -interactive: Whether in is a terminal.
+// Returns: An error if the removal wasn't confirmed.
+func confirmClean(in io.Reader, out io.Writer, interactive bool, targets []string) error {
+	if yesFlag {
+		return nil
+	}
+	if !interactive {
+		return errors.New("clean removes files; pass --yes to confirm non-interactively")
+	}
+
+	fmt.Fprintf( //nolint:errcheck
+		out,
+		"About to remove:\n  %s\nContinue? [y/N] ",
+		strings.Join(targets, "\n  "),
+	)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("clean cancelled")
+	}
+}
+
+// removeTargets removes each of targets, files or whole directories. Missing targets
+// are skipped.
+//
+// Returns: The number of bytes the removed files took up, and an error if one of
+// targets couldn't be removed.
+func removeTargets(targets []string) (int64, error) {
+	var freed int64
+	for _, target := range targets {
+		size, err := diskUsage(target)
+		if err != nil {
+			return freed, err
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return freed, fmt.Errorf("failed to remove '%s': %w", target, err)
+		}
+		freed += size
+	}
+	return freed, nil
+}
+
+// diskUsage returns the total size of the regular files at or below path, 0 if path
+// doesn't exist.
+func diskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure '%s': %w", path, err)
+	}
+	return size, nil
+}
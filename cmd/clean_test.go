@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adrg/xdg"
+
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// useTempXDGHomes points the cache and data directories at temporary ones.
+func useTempXDGHomes(t *testing.T) (cacheHome, dataHome string) {
+	t.Helper()
+	cacheHome, dataHome = t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("GH_INSTALL_CACHE_DIR", "")
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	return cacheHome, dataHome
+}
+
+func writeSizedFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func Test_cleanTargets(t *testing.T) {
+	cacheHome, dataHome := useTempXDGHomes(t)
+	cachePath := filepath.Join(cacheHome, "gh-install")
+	latest := filepath.Join(dataHome, "gh-install", "latest.json")
+	installs := filepath.Join(dataHome, "gh-install", "installs.json")
+
+	tests := []struct {
+		name     string
+		all      bool
+		cacheDir string
+		want     []string
+	}{
+		{name: "cache", want: []string{cachePath, latest}},
+		{name: "all", all: true, want: []string{cachePath, latest, installs}},
+		{
+			name:     "--cache-dir",
+			cacheDir: filepath.Join(cacheHome, "custom"),
+			want:     []string{filepath.Join(cacheHome, "custom", "gh-install"), latest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDirFlag = tt.cacheDir
+			defer func() { cacheDirFlag = "" }()
+
+			got, err := cleanTargets(tt.all)
+			if err != nil {
+				t.Fatalf("cleanTargets() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cleanTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_confirmClean(t *testing.T) {
+	targets := []string{"/home/user/.cache/gh-install"}
+
+	tests := []struct {
+		name        string
+		yes         bool
+		interactive bool
+		answer      string
+		wantErr     bool
+		wantPrompt  bool
+	}{
+		{name: "--yes skips the prompt", yes: true},
+		{name: "confirmed", interactive: true, answer: "y\n", wantPrompt: true},
+		{name: "declined", interactive: true, answer: "n\n", wantErr: true, wantPrompt: true},
+		{name: "non-interactive requires --yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yesFlag = tt.yes
+			defer func() { yesFlag = false }()
+
+			var out bytes.Buffer
+			err := confirmClean(strings.NewReader(tt.answer), &out, tt.interactive, targets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("confirmClean() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Contains(out.String(), targets[0]); got != tt.wantPrompt {
+				t.Errorf("confirmClean() prompt = %q, wantPrompt %v", out.String(), tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func Test_removeTargets(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	writeSizedFile(t, filepath.Join(cache, "a"), 100)
+	writeSizedFile(t, filepath.Join(cache, "hosts", "ghe.example.com", "b"), 50)
+	file := filepath.Join(dir, "latest.json")
+	writeSizedFile(t, file, 10)
+	missing := filepath.Join(dir, "missing")
+
+	freed, err := removeTargets([]string{cache, file, missing})
+	if err != nil {
+		t.Fatalf("removeTargets() error = %v", err)
+	}
+	if freed != 160 {
+		t.Errorf("removeTargets() freed %d bytes, want 160", freed)
+	}
+	for _, path := range []string{cache, file} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", path)
+		}
+	}
+}
+
+func Test_cleanCmd(t *testing.T) {
+	cacheHome, _ := useTempXDGHomes(t)
+	writeSizedFile(t, filepath.Join(cacheHome, "gh-install", "response"), 42)
+	record := state.Record{Owner: "owner", Repo: "tool", Path: "/bin/tool"}
+	if err := state.Save(record); err != nil {
+		t.Fatalf("state.Save() error = %v", err)
+	}
+	installsSize, err := diskUsage(state.FilePath())
+	if err != nil {
+		t.Fatalf("diskUsage() error = %v", err)
+	}
+
+	yesFlag, cleanAllFlag = true, true
+	defer func() { yesFlag, cleanAllFlag = false, false }()
+
+	var out bytes.Buffer
+	cleanCmd.SetOut(&out)
+	defer cleanCmd.SetOut(nil)
+	if err := cleanCmd.RunE(cleanCmd, nil); err != nil {
+		t.Fatalf("clean error = %v", err)
+	}
+
+	want := "Freed " + utils.HumanBytes(42+installsSize) + "."
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("clean output = %q, want %q", out.String(), want)
+	}
+	if _, err := os.Stat(state.FilePath()); !os.IsNotExist(err) {
+		t.Error("clean --all kept the install-state file")
+	}
+}
+
+func Test_cleanCmd_sharedCacheDir(t *testing.T) {
+	useTempXDGHomes(t)
+	shared := t.TempDir()
+	t.Setenv("GH_INSTALL_CACHE_DIR", shared)
+	unrelated := filepath.Join(shared, "other-tool", "data")
+	writeSizedFile(t, unrelated, 10)
+	writeSizedFile(t, filepath.Join(shared, "gh-install", "response"), 42)
+
+	yesFlag = true
+	defer func() { yesFlag = false }()
+
+	var out bytes.Buffer
+	cleanCmd.SetOut(&out)
+	defer cleanCmd.SetOut(nil)
+	if err := cleanCmd.RunE(cleanCmd, nil); err != nil {
+		t.Fatalf("clean error = %v", err)
+	}
+
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("clean removed a file it didn't create: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(shared, "gh-install")); !os.IsNotExist(err) {
+		t.Error("clean kept the responses cached in the shared directory")
+	}
+}
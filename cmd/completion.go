@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/state"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for gh install in the given shell and write it to stdout.
+
+To load completions in the current bash session:
+
+  source <(gh install completion bash)
+
+Packagers can write the output to the shell's completion directory instead.`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		root := cmd.Root()
+		switch args[0] {
+		case "bash":
+			return root.GenBashCompletionV2(out, true)
+		case "zsh":
+			return root.GenZshCompletion(out)
+		case "fish":
+			return root.GenFishCompletion(out, true)
+		case "powershell":
+			return root.GenPowerShellCompletionWithDesc(out)
+		default:
+			return fmt.Errorf("unsupported shell '%s'", args[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+// completeInstalledRepos suggests the owner/repo of every recorded install for the first
+// argument, so previously installed binaries can be re-installed or upgraded by name.
+func completeInstalledRepos(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	records, err := state.Load()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var repos []string
+	for _, r := range records {
+		if strings.HasPrefix(r.Key(), toComplete) {
+			repos = append(repos, r.Key())
+		}
+	}
+	return repos, cobra.ShellCompDirectiveNoFileComp
+}
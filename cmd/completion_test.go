@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/state"
+)
+
+func Test_completionCmd_bash(t *testing.T) {
+	var out bytes.Buffer
+	completionCmd.SetOut(&out)
+	defer completionCmd.SetOut(nil)
+
+	if err := completionCmd.RunE(completionCmd, []string{"bash"}); err != nil {
+		t.Fatalf("completion bash error = %v", err)
+	}
+	if name := rootCmd.Name(); !strings.Contains(out.String(), name) {
+		t.Errorf("bash completion does not mention the command name %q", name)
+	}
+}
+
+func Test_completeInstalledRepos(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	for _, r := range []state.Record{
+		{Owner: "owner", Repo: "tool", Tag: "v1.0.0", Path: "/bin/tool", InstalledAt: time.Now()},
+		{Owner: "other", Repo: "thing", Tag: "v2.0.0", Path: "/bin/thing", InstalledAt: time.Now()},
+	} {
+		if err := state.Save(r); err != nil {
+			t.Fatalf("state.Save() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		{name: "all", want: []string{"other/thing", "owner/tool"}},
+		{name: "prefix", toComplete: "ow", want: []string{"owner/tool"}},
+		{name: "no match", toComplete: "nobody/"},
+		{name: "second argument", args: []string{"owner/tool"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := completeInstalledRepos(rootCmd, tt.args, tt.toComplete)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("completeInstalledRepos() = %v, want %v", got, tt.want)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("completeInstalledRepos() directive = %v, want NoFileComp", directive)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// errFailFast is why entries weren't installed after an earlier one failed with --fail-fast.
+var errFailFast = errors.New("an earlier entry failed and --fail-fast is set")
+
+// installResult records the outcome of installing a single manifest entry.
+type installResult struct {
+	Key      string        // owner/repo key from the manifest
+	Asset    install.Asset // The installed asset, populated on success
+	Err      error         // Non-nil if the install failed
+	Duration time.Duration // Time the install took
+}
+
+// installFromConfig installs every binary listed in the TOML manifest at path,
+// up to --concurrency at a time. A failure for one entry doesn't stop the others;
+// a status table is printed at the end and an error is returned if any entry failed.
+// With --fail-fast, no entries are started after the first failure, the ones running
+// are cancelled and that failure's error is returned.
+// The installed tags and asset checksums are then recorded in the manifest's lockfile
+// (see config.LockPath), or, with --frozen, read from it and enforced instead. With
+// --config-sha, the manifest's checksum is verified before anything is installed.
+func installFromConfig(ctx context.Context, client *github.Client, path string) error {
+	var cfg config.Config
+	var err error
+	if configShaFlag != "" {
+		cfg, err = config.LoadFromFileVerified(path, configShaFlag)
+	} else {
+		cfg, err = config.LoadFromFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load config '%s': %w", path, err)
+	}
+	if len(cfg.Binaries) == 0 {
+		return fmt.Errorf("no binaries found in config '%s'", path)
+	}
+	if err := ensureRateBudget(startRate, len(cfg.Binaries)); err != nil {
+		return err
+	}
+
+	lockPath := config.LockPath(path)
+	var locks map[string]config.LockEntry
+	if frozenFlag {
+		if locks, err = loadFrozenLocks(lockPath, cfg); err != nil {
+			return err
+		}
+	}
+
+	// Iterate in a stable order so runs are reproducible
+	keys := make([]string, 0, len(cfg.Binaries))
+	for key := range cfg.Binaries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	base := baseInstallOptions()
+	base.Client = client
+	workers := max(1, min(concurrencyFlag, len(keys)))
+	if workers > 1 {
+		// Concurrent progress bars would clobber each other; log a line per download instead
+		base.Progress = false
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	results := make([]installResult, len(keys))
+	firstFailed := -1
+	var failOnce sync.Once
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i] = notInstalled(ctx, keys[i])
+					continue
+				}
+				var lock *config.LockEntry
+				if entry, ok := locks[keys[i]]; ok {
+					lock = &entry
+				}
+				results[i] = installConfigEntry(ctx, base, cfg.Binaries[keys[i]], lock)
+				if failFastFlag && results[i].Err != nil {
+					failOnce.Do(func() {
+						firstFailed = i
+						cancel(errFailFast)
+					})
+				}
+			}
+		}()
+	}
+	dispatched := 0
+dispatch:
+	for ; dispatched < len(keys); dispatched++ {
+		select {
+		case jobs <- dispatched:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for i := dispatched; i < len(keys); i++ {
+		results[i] = notInstalled(ctx, keys[i])
+	}
+
+	if !frozenFlag && !dryRunFlag {
+		if err := updateLock(lockPath, results); err != nil {
+			utils.Logger.Errorf("Failed to update lockfile: %v", err)
+		}
+	}
+
+	if outputFlag == outputJSON {
+		err = writeJSON(rootCmd.OutOrStdout(), reportsFromResults(results))
+		if err == nil {
+			err = resultsError(results)
+		}
+	} else {
+		err = summarizeResults(rootCmd.OutOrStdout(), results)
+	}
+	if err != nil && firstFailed >= 0 {
+		r := results[firstFailed]
+		return fmt.Errorf("failed to install '%s': %w", r.Key, r.Err)
+	}
+	return err
+}
+
+// notInstalled is the result of an entry that wasn't installed because ctx was done,
+// after an earlier entry failed with --fail-fast or the whole run was cancelled.
+func notInstalled(ctx context.Context, key string) installResult {
+	return installResult{Key: key, Err: fmt.Errorf("not installed: %w", context.Cause(ctx))}
+}
+
+// installConfigEntry installs a single manifest entry on top of the base options,
+// honoring its name, version, path, sha and asset or asset_regex; path and sha take
+// precedence over --path and --sha. A non-nil lock replaces the version with the locked
+// tag and pins the asset and its checksum; otherwise the latest release is the one
+// --select picks.
+func installConfigEntry(
+	ctx context.Context,
+	base install.InstallOptions,
+	bc config.BinaryConfig,
+	lock *config.LockEntry,
+) installResult {
+	arg := bc.Key
+	switch {
+	case lock != nil:
+		arg = bc.Key + "@" + lock.Tag
+	case bc.Version != "":
+		arg = bc.Key + "@" + bc.Version
+	}
+
+	pa, err := utils.ParseArgs(arg)
+	if err != nil {
+		utils.Logger.Errorf("Skipping '%s': %v", bc.Key, err)
+		return installResult{Key: bc.Key, Err: fmt.Errorf("invalid entry: %w", err)}
+	}
+
+	version := pa.Version
+	if lock == nil {
+		if version, err = selectedVersion(ctx, base.Client, pa); err != nil {
+			utils.Logger.Errorf("Failed to install '%s': %v", bc.Key, err)
+			return installResult{Key: bc.Key, Err: err}
+		}
+	}
+
+	opts := base
+	opts.Owner, opts.Repo, opts.Version = pa.Owner, pa.Repo, version
+	opts.BinName = bc.Name
+	opts.Path = pathFlag
+	if bc.Path != "" {
+		opts.Path = bc.Path
+	}
+	if bc.Sha != "" {
+		opts.Sha = bc.Sha
+	}
+	opts.PostInstall = postInstallFlag
+	if bc.PostInstall != "" {
+		opts.PostInstall = bc.PostInstall
+	}
+	opts.Symlink = symlinkFlag
+	opts.Asset = bc.Asset
+	if bc.AssetRegex != "" {
+		// LoadFromFile already rejected invalid patterns
+		opts.AssetRegex = regexp.MustCompile(bc.AssetRegex)
+	}
+	opts.ContentTypes = contentTypeFlag
+	opts.Force = forceFlag
+	opts.Lock = lock
+
+	utils.Logger.Infof("Installing %s", arg)
+	start := time.Now()
+	asset, err := install.Release(ctx, opts)
+	if err != nil {
+		utils.Logger.Errorf("Failed to install '%s': %v", bc.Key, err)
+	}
+	return installResult{Key: bc.Key, Asset: asset, Err: err, Duration: time.Since(start)}
+}
+
+// loadFrozenLocks reads the lockfile at lockPath for --frozen and checks that it pins
+// every entry of cfg.
+func loadFrozenLocks(lockPath string, cfg config.Config) (map[string]config.LockEntry, error) {
+	locks, err := config.LoadLock(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("--frozen requires a lockfile: %w", err)
+	}
+	var missing []string
+	for key := range cfg.Binaries {
+		if _, ok := locks[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf(
+			"lockfile '%s' is missing %s; run without --frozen to update it",
+			lockPath,
+			strings.Join(missing, ", "),
+		)
+	}
+	return locks, nil
+}
+
+// updateLock records the release asset installed for every successful result in the
+// lockfile at lockPath. Entries that failed keep their previously locked asset.
+func updateLock(lockPath string, results []installResult) error {
+	previous, err := config.LoadLock(lockPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	entries := make([]config.LockEntry, 0, len(results))
+	for _, r := range results {
+		switch {
+		case r.Err == nil && r.Asset.Digest != "":
+			entries = append(entries, config.LockEntry{
+				Key:       r.Key,
+				Tag:       r.Asset.Tag,
+				Asset:     r.Asset.Name,
+				Checksum:  r.Asset.Digest,
+				Algorithm: install.LockAlgorithm,
+			})
+		case previous != nil:
+			if entry, ok := previous[r.Key]; ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if err := config.WriteLock(lockPath, entries); err != nil {
+		return err
+	}
+	utils.Logger.Debugf("Updated lockfile '%s'", lockPath)
+	return nil
+}
+
+// summarizeResults writes a status table of the installed and failed entries to w
+// and returns an error if at least one failed.
+func summarizeResults(w io.Writer, results []installResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)           //nolint:mnd
+	fmt.Fprintln(tw, "STATUS\tREPOSITORY\tVERSION\tRESULT") //nolint:errcheck
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(tw, "%s\t%s\t\t%v\n", install.Red("✘"), r.Key, r.Err) //nolint:errcheck
+			continue
+		}
+		fmt.Fprintf( //nolint:errcheck
+			tw,
+			"%s\t%s\t%s\t%s\n",
+			install.Green("✔"),
+			r.Key,
+			r.Asset.Tag,
+			r.Asset.Path,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return resultsError(results)
+}
+
+// resultsError returns an error counting the failed entries, or nil if all succeeded.
+func resultsError(results []installResult) error {
+	var failed int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d binaries failed to install", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,495 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/adrg/xdg"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_summarizeResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []installResult
+		wantErr bool
+	}{
+		{
+			name: "all succeeded",
+			results: []installResult{
+				{Key: "owner/a", Asset: install.Asset{Path: "/bin/a"}},
+				{Key: "owner/b", Asset: install.Asset{Path: "/bin/b"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "one failed",
+			results: []installResult{
+				{Key: "owner/a", Asset: install.Asset{Path: "/bin/a"}},
+				{Key: "owner/b", Err: errors.New("boom")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := summarizeResults(&out, tt.results); (err != nil) != tt.wantErr {
+				t.Errorf("summarizeResults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, r := range tt.results {
+				if !strings.Contains(out.String(), r.Key) {
+					t.Errorf("summarizeResults() table is missing %s:\n%s", r.Key, out.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_installFromConfigMissingFile(t *testing.T) {
+	err := installFromConfig(context.Background(), nil, "does-not-exist.toml")
+	if err == nil {
+		t.Error("installFromConfig() expected error for missing config, got nil")
+	}
+}
+
+func Test_installFromConfig_configShaMismatch(t *testing.T) {
+	defer func() { configShaFlag = "" }()
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	content := []byte("['owner/tool']\nversion = 'v1.0.0'\n")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// No client: the mismatch must fail before anything is looked up
+	configShaFlag = strings.Repeat("0", 64)
+	err := installFromConfig(context.Background(), nil, path)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("installFromConfig() error = %v, want a checksum mismatch", err)
+	}
+}
+
+func Test_validateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		args    []string
+		wantErr bool
+	}{
+		{name: "single arg", args: []string{"owner/repo"}, wantErr: false},
+		{name: "no args", args: []string{}, wantErr: true},
+		{name: "config no args", config: "tools.toml", args: []string{}, wantErr: false},
+		{name: "config with arg", config: "tools.toml", args: []string{"owner/repo"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFlag = tt.config
+			defer func() { configFlag = "" }()
+			if err := validateArgs(rootCmd, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("validateArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_installFromConfigConcurrent(t *testing.T) {
+	utils.GetOSArch()
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	binDir := t.TempDir()
+	pathFlag = binDir
+	concurrencyFlag = 3
+	defer func() { pathFlag, concurrencyFlag = "", defaultConcurrency }()
+
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+				var id int
+				_, _ = fmt.Sscanf(strings.Split(r.URL.Path, "/")[3], "tool%d", &id)
+				_, _ = fmt.Fprintf(
+					w,
+					`{"tag_name":"v1.0.0","assets":[{"id":%d,"name":%q,"size":4,`+
+						`"content_type":"application/octet-stream"}]}`,
+					id,
+					assetName,
+				)
+			case strings.Contains(r.URL.Path, "/releases/assets/"):
+				fmt.Fprint(w, "bin!") //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	manifest := filepath.Join(t.TempDir(), "tools.toml")
+	var toml strings.Builder
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(&toml, "['owner/tool%d']\nname = 'tool%d'\nversion = 'latest'\n\n", i, i)
+	}
+	if err := os.WriteFile(manifest, []byte(toml.String()), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	if err := installFromConfig(context.Background(), client, manifest); err != nil {
+		t.Fatalf("installFromConfig() error = %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		path := filepath.Join(binDir, fmt.Sprintf("tool%d", i))
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be installed: %v", path, err)
+		}
+	}
+}
+
+func Test_installFromConfig_entryPaths(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	defaultDir, binDir, localBinDir := t.TempDir(), t.TempDir(), t.TempDir()
+	pathFlag = defaultDir
+	defer func() { pathFlag = "" }()
+
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+				_, _ = fmt.Fprintf(w, `{"tag_name":"v1.0.0","assets":[{"id":1,"name":%q,"size":4,`+
+					`"content_type":"application/octet-stream"}]}`, assetName)
+			case strings.HasSuffix(r.URL.Path, "/releases/assets/1"):
+				fmt.Fprint(w, "bin!") //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	manifest := filepath.Join(t.TempDir(), "tools.toml")
+	toml := fmt.Sprintf("['owner/a']\nversion = 'latest'\npath = %q\n\n"+
+		"['owner/b']\nversion = 'latest'\npath = %q\n\n"+
+		"['owner/c']\nname = 'c'\nversion = 'latest'\n", binDir, localBinDir)
+	if err := os.WriteFile(manifest, []byte(toml), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	if err := installFromConfig(context.Background(), client, manifest); err != nil {
+		t.Fatalf("installFromConfig() error = %v", err)
+	}
+	for _, path := range []string{
+		filepath.Join(binDir, "tool"),
+		filepath.Join(localBinDir, "tool"),
+		filepath.Join(defaultDir, "c"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be installed: %v", path, err)
+		}
+	}
+}
+
+func Test_installFromConfig_failureMode(t *testing.T) {
+	utils.GetOSArch()
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.Contains(r.URL.Path, "/broken/"):
+				http.NotFound(w, r)
+			case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+				_, _ = fmt.Fprintf(w, `{"tag_name":"v1.0.0","assets":[{"id":1,"name":%q,"size":4,`+
+					`"content_type":"application/octet-stream"}]}`, assetName)
+			case strings.HasSuffix(r.URL.Path, "/releases/assets/1"):
+				fmt.Fprint(w, "bin!") //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	// Entries are installed in key order, one at a time
+	manifest := filepath.Join(t.TempDir(), "tools.toml")
+	toml := "['owner/a']\nname = 'a'\nversion = 'latest'\n\n" +
+		"['owner/broken']\nname = 'broken'\nversion = 'latest'\n\n" +
+		"['owner/c']\nname = 'c'\nversion = 'latest'\n\n" +
+		"['owner/d']\nname = 'd'\nversion = 'latest'\n"
+	if err := os.WriteFile(manifest, []byte(toml), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		failFast      bool
+		wantErr       string
+		wantInstalled []string
+		wantMissing   []string
+	}{
+		{
+			name:          "keep going",
+			wantErr:       "1 of 4 binaries failed to install",
+			wantInstalled: []string{"a", "c", "d"},
+			wantMissing:   []string{"broken"},
+		},
+		{
+			name:          "fail fast",
+			failFast:      true,
+			wantErr:       "failed to install 'owner/broken'",
+			wantInstalled: []string{"a"},
+			wantMissing:   []string{"broken", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", t.TempDir())
+			xdg.Reload()
+			t.Cleanup(xdg.Reload)
+			binDir := t.TempDir()
+			pathFlag, concurrencyFlag, failFastFlag = binDir, 1, tt.failFast
+			defer func() { pathFlag, concurrencyFlag, failFastFlag = "", defaultConcurrency, false }()
+
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			defer rootCmd.SetOut(nil)
+			err := installFromConfig(context.Background(), client, manifest)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("installFromConfig() error = %v, want %q", err, tt.wantErr)
+			}
+			for _, name := range tt.wantInstalled {
+				if _, err := os.Stat(filepath.Join(binDir, name)); err != nil {
+					t.Errorf("expected %s to be installed: %v", name, err)
+				}
+			}
+			for _, name := range tt.wantMissing {
+				if _, err := os.Stat(filepath.Join(binDir, name)); !os.IsNotExist(err) {
+					t.Errorf("expected %s not to be installed, stat error = %v", name, err)
+				}
+			}
+			if tt.failFast && !strings.Contains(out.String(), errFailFast.Error()) {
+				t.Errorf("summary = %q, want the skipped entries listed", out.String())
+			}
+		})
+	}
+}
+
+func Test_installConfigEntry_postInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks are sh scripts")
+	}
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+				_, _ = fmt.Fprintf(
+					w,
+					`{"tag_name":"v1.0.0","assets":[{"id":1,"name":%q,"size":4}]}`,
+					assetName,
+				)
+			case strings.HasSuffix(r.URL.Path, "/releases/assets/1"):
+				fmt.Fprint(w, "bin!") //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+	record := `echo "$GH_INSTALL_PATH $GH_INSTALL_BIN $GH_INSTALL_VERSION" > "$HOOK_OUT"`
+
+	tests := []struct {
+		name        string
+		flagHook    string
+		entryHook   string
+		ignoreError bool
+		wantOut     bool
+		wantErr     bool
+	}{
+		{name: "no hook"},
+		{name: "--post-install", flagHook: record, wantOut: true},
+		{
+			name:      "post_install overrides the flag",
+			flagHook:  "exit 1",
+			entryHook: record,
+			wantOut:   true,
+		},
+		{name: "failing hook", flagHook: "echo oops; exit 3", wantErr: true},
+		{name: "ignored failing hook", flagHook: "exit 3", ignoreError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pathFlag, postInstallFlag, ignoreHookFlag = t.TempDir(), tt.flagHook, tt.ignoreError
+			defer func() { pathFlag, postInstallFlag, ignoreHookFlag = "", "", false }()
+			hookOut := filepath.Join(t.TempDir(), "hook.out")
+			t.Setenv("HOOK_OUT", hookOut)
+
+			entry := config.BinaryConfig{
+				Key:         "owner/tool",
+				Version:     "latest",
+				PostInstall: tt.entryHook,
+			}
+			base := baseInstallOptions()
+			base.Client = client
+			result := installConfigEntry(context.Background(), base, entry, nil)
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("installConfigEntry() error = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			got, err := os.ReadFile(hookOut)
+			if !tt.wantOut {
+				if err == nil {
+					t.Errorf("hook ran and wrote %q, want it not to", got)
+				}
+				return
+			}
+			binPath := filepath.Join(pathFlag, "tool")
+			if want := binPath + " tool v1.0.0\n"; string(got) != want {
+				t.Errorf("hook environment = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func Test_installConfigEntry_shaAndAsset(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	pathFlag = t.TempDir()
+	defer func() { pathFlag = "" }()
+
+	body := "bin!"
+	sum := sha512.Sum512([]byte(body))
+	native := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	sums := fmt.Sprintf("%x  %s\n%x  tool-static\n", sum, native, sum)
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[
+		{"id":1,"name":%q,"size":4},
+		{"id":2,"name":"tool-static","size":4},
+		{"id":3,"name":"checksums.txt","size":%d}
+	]}`, native, len(sums))
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+				fmt.Fprint(w, release) //nolint:errcheck
+			case strings.HasSuffix(r.URL.Path, "/releases/assets/2"):
+				fmt.Fprint(w, body) //nolint:errcheck
+			case strings.HasSuffix(r.URL.Path, "/releases/assets/3"):
+				fmt.Fprint(w, sums) //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	entry := config.BinaryConfig{
+		Key:     "owner/tool",
+		Version: "latest",
+		Sha:     "sha512",
+		Asset:   "tool-static",
+	}
+	base := baseInstallOptions()
+	base.Client = client
+	result := installConfigEntry(context.Background(), base, entry, nil)
+	if result.Err != nil {
+		t.Fatalf("installConfigEntry() error = %v", result.Err)
+	}
+	if result.Asset.Name != "tool-static" {
+		t.Errorf("installConfigEntry() installed %q, want tool-static", result.Asset.Name)
+	}
+	if result.Asset.Algorithm != "sha512" || result.Asset.Checksum != fmt.Sprintf("%x", sum) {
+		t.Errorf("installConfigEntry() verified %s %s, want the sha512 checksum",
+			result.Asset.Algorithm, result.Asset.Checksum)
+	}
+}
+
+func Test_installFromConfig_lockfile(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	pathFlag = t.TempDir()
+	defer func() { pathFlag, frozenFlag, forceFlag = "", false, false }()
+
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[{"id":1,"name":%q,"size":4,`+
+		`"content_type":"application/octet-stream"}]}`, assetName)
+	body := "bin!"
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/releases/latest"),
+				strings.HasSuffix(r.URL.Path, "/releases/tags/v1.0.0"):
+				fmt.Fprint(w, release) //nolint:errcheck
+			case strings.HasSuffix(r.URL.Path, "/releases/assets/1"):
+				fmt.Fprint(w, body) //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	manifest := filepath.Join(t.TempDir(), "tools.toml")
+	err := os.WriteFile(manifest, []byte("['owner/tool']\nversion = 'latest'\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	lockPath := config.LockPath(manifest)
+
+	frozenFlag = true
+	if err := installFromConfig(context.Background(), client, manifest); err == nil {
+		t.Fatal("installFromConfig() with --frozen and no lockfile succeeded")
+	}
+
+	frozenFlag = false
+	if err := installFromConfig(context.Background(), client, manifest); err != nil {
+		t.Fatalf("installFromConfig() error = %v", err)
+	}
+	locks, err := config.LoadLock(lockPath)
+	if err != nil {
+		t.Fatalf("LoadLock() error = %v", err)
+	}
+	lock := locks["owner/tool"]
+	if lock.Tag != "v1.0.0" || lock.Asset != assetName || lock.Algorithm != install.LockAlgorithm {
+		t.Errorf("lock entry = %+v", lock)
+	}
+
+	frozenFlag, forceFlag = true, true // Reinstall over the binary installed above
+	if err := installFromConfig(context.Background(), client, manifest); err != nil {
+		t.Fatalf("installFromConfig() with --frozen error = %v", err)
+	}
+
+	body = "bad!" // Same size, different content
+	err = installFromConfig(context.Background(), client, manifest)
+	if err == nil {
+		t.Fatal("installFromConfig() with --frozen accepted a changed asset")
+	}
+	if after, _ := config.LoadLock(lockPath); after["owner/tool"] != lock {
+		t.Errorf("--frozen changed the lockfile: %+v", after["owner/tool"])
+	}
+}
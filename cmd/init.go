@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// defaultConfigPath is the manifest written by init when no path is given.
+const defaultConfigPath = "tools.toml"
+
+var (
+	initForceFlag     bool // initForceFlag is the value from the init --force flag
+	initFromStateFlag bool // initFromStateFlag is the value from the init --from-state flag
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [path]",
+	Short: "Write a starter --config manifest",
+	Long: `Write a commented starter TOML manifest for --config to path (default tools.toml).
+With --from-state the manifest lists every binary installed by gh install, pinned to
+its installed release tag.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := defaultConfigPath
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		var binaries []config.BinaryConfig
+		if initFromStateFlag {
+			records, err := state.Load()
+			if err != nil {
+				return err
+			}
+			binaries = binariesFromRecords(records)
+		}
+
+		if err := writeStarterConfig(path, binaries, initForceFlag); err != nil {
+			return err
+		}
+		utils.Logger.Info(install.Green("✔") + " Wrote " + path)
+		return nil
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForceFlag, "force", false, "overwrite an existing file")
+	initCmd.Flags().BoolVar(
+		&initFromStateFlag,
+		"from-state",
+		false,
+		"list the binaries already installed by gh install",
+	)
+	rootCmd.AddCommand(initCmd)
+}
+
+// binariesFromRecords converts install records into manifest entries, named after the
+// installed binary (or its --symlink link) and pinned to the installed tag.
+func binariesFromRecords(records []state.Record) []config.BinaryConfig {
+	binaries := make([]config.BinaryConfig, 0, len(records))
+	for _, r := range records {
+		bc := config.BinaryConfig{Key: r.Key(), Version: r.Tag}
+		switch {
+		case r.Link != "":
+			bc.Name = filepath.Base(r.Link)
+		case r.Path != "": // Empty for system packages
+			bc.Name = filepath.Base(r.Path)
+		}
+		binaries = append(binaries, bc)
+	}
+	return binaries
+}
+
+// writeStarterConfig writes a starter manifest listing binaries to path. An existing
+// file is only replaced when force is set.
+func writeStarterConfig(path string, binaries []config.BinaryConfig, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(filepath.Clean(path), flags, 0o644) //nolint:mnd,gosec
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("'%s' already exists; pass --force to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", path, err)
+	}
+	if _, err := f.Write(config.Starter(path, binaries)); err != nil {
+		f.Close() //nolint:errcheck
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/state"
+)
+
+func Test_binariesFromRecords(t *testing.T) {
+	records := []state.Record{
+		{Owner: "owner", Repo: "tool", Tag: "v1.0.0", Path: "/bin/toolbox"},
+		{Owner: "owner", Repo: "linked", Tag: "v2.0.0", Path: "/bin/l-v2.0.0", Link: "/bin/l"},
+		{Owner: "owner", Repo: "pkg", Tag: "v3.0.0"},
+	}
+	want := []config.BinaryConfig{
+		{Key: "owner/tool", Name: "toolbox", Version: "v1.0.0"},
+		{Key: "owner/linked", Name: "l", Version: "v2.0.0"},
+		{Key: "owner/pkg", Version: "v3.0.0"},
+	}
+	if got := binariesFromRecords(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("binariesFromRecords() = %v, want %v", got, want)
+	}
+}
+
+func Test_writeStarterConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	binaries := []config.BinaryConfig{{Key: "owner/tool", Name: "tool", Version: "v1.0.0"}}
+
+	if err := writeStarterConfig(path, binaries, false); err != nil {
+		t.Fatalf("writeStarterConfig() error = %v", err)
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if got := cfg.Binaries["owner/tool"]; got != binaries[0] {
+		t.Errorf("manifest entry = %v, want %v", got, binaries[0])
+	}
+
+	if err := writeStarterConfig(path, nil, false); err == nil {
+		t.Error("writeStarterConfig() overwrote an existing file without force")
+	}
+	if err := writeStarterConfig(path, nil, true); err != nil {
+		t.Fatalf("writeStarterConfig() with force error = %v", err)
+	}
+	if cfg, err = config.LoadFromFile(path); err != nil || len(cfg.Binaries) != 0 {
+		t.Errorf("forced manifest = %v (error %v), want no binaries", cfg, err)
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/state"
+)
+
+var listJSONFlag bool // listJSONFlag is the value from the list --json flag
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List binaries installed by gh install",
+	Long: `List binaries installed by gh install in $XDG_BIN_HOME (or --path),
+along with the repository and release tag they were installed from.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := state.Load()
+		if err != nil {
+			return err
+		}
+
+		installed := installedInDir(records, listDir())
+		if listJSONFlag {
+			return printRecordsJSON(cmd.OutOrStdout(), installed)
+		}
+		return printRecordsTable(cmd.OutOrStdout(), installed)
+	},
+}
+
+func init() {
+	listCmd.Flags().BoolVar(&listJSONFlag, "json", false, "output as JSON")
+	rootCmd.AddCommand(listCmd)
+}
+
+// listDir returns the directory whose managed binaries should be listed.
+func listDir() string {
+	dir, err := install.ResolveTargetDir(pathFlag)
+	if err != nil {
+		return filepath.Clean(pathFlag)
+	}
+	return dir
+}
+
+// installedInDir returns the records whose binary lives in dir and still exists on disk.
+func installedInDir(records []state.Record, dir string) []state.Record {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+
+	installed := make([]state.Record, 0, len(records))
+	for _, r := range records {
+		if filepath.Dir(r.Path) != absDir {
+			continue
+		}
+		if _, err := os.Stat(r.Path); err != nil {
+			continue
+		}
+		installed = append(installed, r)
+	}
+	return installed
+}
+
+// printRecordsTable writes records to w as an aligned table.
+func printRecordsTable(w io.Writer, records []state.Record) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "No binaries installed by gh install.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)       //nolint:mnd
+	fmt.Fprintln(tw, "NAME\tREPOSITORY\tVERSION\tPATH") //nolint:errcheck
+	for _, r := range records {
+		fmt.Fprintf( //nolint:errcheck
+			tw,
+			"%s\t%s\t%s\t%s\n",
+			filepath.Base(r.Path),
+			r.Key(),
+			r.Tag,
+			r.Path,
+		)
+	}
+	return tw.Flush()
+}
+
+// printRecordsJSON writes records to w as an indented JSON array.
+func printRecordsJSON(w io.Writer, records []state.Record) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/esacteksab/gh-install/state"
+)
+
+func Test_installedInDir(t *testing.T) {
+	binDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	present := filepath.Join(binDir, "tool")
+	if err := os.WriteFile(present, []byte("bin"), 0o755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	elsewhere := filepath.Join(otherDir, "other")
+	if err := os.WriteFile(elsewhere, []byte("bin"), 0o755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	records := []state.Record{
+		{Owner: "owner", Repo: "tool", Tag: "v1.0.0", Path: present},
+		{Owner: "owner", Repo: "gone", Tag: "v1.0.0", Path: filepath.Join(binDir, "gone")},
+		{Owner: "owner", Repo: "other", Tag: "v1.0.0", Path: elsewhere},
+	}
+
+	got := installedInDir(records, binDir)
+	want := []state.Record{records[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("installedInDir() = %v, want %v", got, want)
+	}
+}
+
+func Test_printRecords(t *testing.T) {
+	records := []state.Record{
+		{Owner: "owner", Repo: "tool", Tag: "v1.2.3", Path: "/home/user/.local/bin/tool"},
+	}
+
+	var table bytes.Buffer
+	if err := printRecordsTable(&table, records); err != nil {
+		t.Fatalf("printRecordsTable() error = %v", err)
+	}
+	for _, want := range []string{"NAME", "owner/tool", "v1.2.3", "/home/user/.local/bin/tool"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("printRecordsTable() output missing %q:\n%s", want, table.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := printRecordsJSON(&out, records); err != nil {
+		t.Fatalf("printRecordsJSON() error = %v", err)
+	}
+	var decoded []state.Record
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("printRecordsJSON() produced invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, records) {
+		t.Errorf("printRecordsJSON() = %v, want %v", decoded, records)
+	}
+
+	var empty bytes.Buffer
+	if err := printRecordsTable(&empty, nil); err != nil {
+		t.Fatalf("printRecordsTable() error = %v", err)
+	}
+	if !strings.Contains(empty.String(), "No binaries installed") {
+		t.Errorf("printRecordsTable() with no records = %q", empty.String())
+	}
+}
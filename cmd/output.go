@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/esacteksab/gh-install/install"
+)
+
+const (
+	outputText = "text" // Human readable output with progress bars
+	outputJSON = "json" // A JSON result on stdout, logs on stderr only
+)
+
+// installReport is the result of a single install as printed by --output json.
+type installReport struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Tag   string `json:"tag,omitempty"`
+	Asset string `json:"asset,omitempty"`
+	Path  string `json:"path,omitempty"`
+	// Extra are the further binaries installed with --extract or --all-binaries
+	Extra     []string `json:"extra,omitempty"`
+	Checksum  string   `json:"checksum,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty"`
+	Verified  bool     `json:"verified"`
+	Duration  string   `json:"duration"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// validateOutput checks the value of the --output flag.
+func validateOutput(output string) error {
+	switch output {
+	case outputText, outputJSON:
+		return nil
+	default:
+		return fmt.Errorf(
+			"invalid output format '%s': must be '%s' or '%s'",
+			output,
+			outputText,
+			outputJSON,
+		)
+	}
+}
+
+// newInstallReport builds the report for an install of owner/repo that took duration
+// and, if err is non-nil, failed.
+func newInstallReport(
+	owner, repo string,
+	asset install.Asset,
+	duration time.Duration,
+	err error,
+) installReport {
+	report := installReport{
+		Owner:     owner,
+		Repo:      repo,
+		Tag:       asset.Tag,
+		Asset:     asset.Name,
+		Path:      asset.Path,
+		Extra:     asset.Extra,
+		Checksum:  asset.Checksum,
+		Algorithm: asset.Algorithm,
+		Verified:  err == nil && asset.Checksum != "",
+		Duration:  duration.Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
+
+// reportsFromResults converts the results of a --config run into reports.
+func reportsFromResults(results []installResult) []installReport {
+	reports := make([]installReport, 0, len(results))
+	for _, r := range results {
+		owner, repo, _ := strings.Cut(r.Key, "/")
+		reports = append(reports, newInstallReport(owner, repo, r.Asset, r.Duration, r.Err))
+	}
+	return reports
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/esacteksab/gh-install/install"
+)
+
+func Test_validateOutput(t *testing.T) {
+	tests := []struct {
+		output  string
+		wantErr bool
+	}{
+		{output: "text"},
+		{output: "json"},
+		{output: "yaml", wantErr: true},
+		{output: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			if err := validateOutput(tt.output); (err != nil) != tt.wantErr {
+				t.Errorf("validateOutput(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_newInstallReport(t *testing.T) {
+	asset := install.Asset{
+		Name:      "tool_linux_amd64.tar.gz",
+		Path:      "/bin/tool",
+		Tag:       "v1.0.0",
+		Checksum:  "abc123",
+		Algorithm: "sha256",
+	}
+
+	tests := []struct {
+		name         string
+		asset        install.Asset
+		err          error
+		wantVerified bool
+		wantError    string
+	}{
+		{name: "verified", asset: asset, wantVerified: true},
+		{
+			name:  "no checksum",
+			asset: install.Asset{Name: asset.Name, Path: asset.Path, Tag: asset.Tag},
+		},
+		{name: "failed", err: errors.New("boom"), wantError: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newInstallReport("owner", "tool", tt.asset, 1500*time.Millisecond, tt.err)
+			if report.Verified != tt.wantVerified {
+				t.Errorf("Verified = %t, want %t", report.Verified, tt.wantVerified)
+			}
+			if report.Error != tt.wantError {
+				t.Errorf("Error = %q, want %q", report.Error, tt.wantError)
+			}
+			if report.Duration != "1.5s" {
+				t.Errorf("Duration = %q, want %q", report.Duration, "1.5s")
+			}
+			if report.Path != tt.asset.Path || report.Tag != tt.asset.Tag {
+				t.Errorf("report = %+v, does not describe asset %+v", report, tt.asset)
+			}
+		})
+	}
+}
+
+func Test_reportsFromResults(t *testing.T) {
+	results := []installResult{
+		{
+			Key:      "owner/a",
+			Asset:    install.Asset{Path: "/bin/a", Tag: "v1.0.0"},
+			Duration: time.Second,
+		},
+		{Key: "owner/b", Err: errors.New("boom")},
+	}
+
+	var out bytes.Buffer
+	if err := writeJSON(&out, reportsFromResults(results)); err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+
+	var got []installReport
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array of reports: %v\n%s", err, out.String())
+	}
+	if len(got) != len(results) {
+		t.Fatalf("got %d reports, want %d", len(got), len(results))
+	}
+	if got[0].Owner != "owner" || got[0].Repo != "a" || got[0].Path != "/bin/a" {
+		t.Errorf("first report = %+v", got[0])
+	}
+	if got[1].Error != "boom" {
+		t.Errorf("second report error = %q, want %q", got[1].Error, "boom")
+	}
+}
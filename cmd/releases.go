@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// defaultReleasesLimit is how many releases the releases command lists by default.
+const defaultReleasesLimit = 10
+
+// releasesPerPage is the largest page size GitHub allows when listing releases.
+const releasesPerPage = 100
+
+var releasesLimitFlag int // releasesLimitFlag is the value from the releases --limit flag
+
+var releasesCmd = &cobra.Command{
+	Use:   "releases owner/repo",
+	Short: "List the releases of a repository",
+	Long: `List the newest releases of a repository with their tag, publication date,
+whether they're a pre-release, how many assets they have and whether one of them
+matches this system's OS/Arch (or --os and --arch). Any listed tag can be installed
+with owner/repo@tag.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(outputFlag); err != nil {
+			return err
+		}
+		if releasesLimitFlag < 1 {
+			return errors.New("--limit must be at least 1")
+		}
+		owner, repo, err := utils.ParseOwnerRepo(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid argument: %w", err)
+		}
+
+		ctx := cmd.Context()
+		client, err := newClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize GitHub client: %v", err)
+		}
+		apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+		defer cancel()
+		releases, err := listRecentReleases(apiCtx, client, owner, repo, releasesLimitFlag)
+		if err != nil {
+			return err
+		}
+
+		summaries := summarizeReleases(releases, platformOptions().Matcher())
+		if outputFlag == outputJSON {
+			return writeJSON(cmd.OutOrStdout(), summaries)
+		}
+		return printReleasesTable(cmd.OutOrStdout(), summaries)
+	},
+}
+
+func init() {
+	releasesCmd.Flags().IntVarP(
+		&releasesLimitFlag,
+		"limit",
+		"L",
+		defaultReleasesLimit,
+		"maximum number of releases to list",
+	)
+	rootCmd.AddCommand(releasesCmd)
+}
+
+// releaseSummary describes a release as listed by the releases command.
+type releaseSummary struct {
+	Tag        string    `json:"tag"`
+	Published  time.Time `json:"published"`
+	Prerelease bool      `json:"prerelease"`
+	Assets     int       `json:"assets"`
+	Matches    bool      `json:"matches"` // Whether an asset matches the OS/Arch
+}
+
+// listRecentReleases returns the newest releases of owner/repo, skipping drafts, and
+// only requests as many pages as needed.
+//
+// -limit: The maximum number of releases to return.
+// Returns: The releases, newest first as GitHub lists them.
+func listRecentReleases(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	limit int,
+) ([]*github.RepositoryRelease, error) {
+	var recent []*github.RepositoryRelease
+	opts := &github.ListOptions{PerPage: min(limit, releasesPerPage)}
+	for {
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, install.WithRateLimit(fmt.Errorf(
+				"failed to list releases of %s/%s: %w",
+				owner,
+				repo,
+				err,
+			), resp)
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue // Drafts can't be installed
+			}
+			recent = append(recent, release)
+			if len(recent) == limit {
+				return recent, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return recent, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// summarizeReleases builds the releaseSummary of every release, matching their assets
+// with matcher.
+func summarizeReleases(
+	releases []*github.RepositoryRelease,
+	matcher *utils.Matcher,
+) []releaseSummary {
+	summaries := make([]releaseSummary, 0, len(releases))
+	for _, release := range releases {
+		summaries = append(summaries, releaseSummary{
+			Tag:        release.GetTagName(),
+			Published:  install.ReleaseTime(release),
+			Prerelease: release.GetPrerelease(),
+			Assets:     len(release.Assets),
+			Matches:    hasPlatformAsset(release.Assets, matcher),
+		})
+	}
+	return summaries
+}
+
+// hasPlatformAsset reports whether one of assets is a binary, archive or package matcher
+// accepts; checksums, signatures and SBOMs are ignored.
+func hasPlatformAsset(assets []*github.ReleaseAsset, matcher *utils.Matcher) bool {
+	for _, asset := range assets {
+		name := asset.GetName()
+		if utils.IsChecksumFile(name) || utils.IsSignatureFile(name) ||
+			utils.IsMetadataAsset(name) {
+			continue
+		}
+		if matcher.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// printReleasesTable writes summaries to w as an aligned table.
+func printReleasesTable(w io.Writer, summaries []releaseSummary) error {
+	if len(summaries) == 0 {
+		_, err := fmt.Fprintln(w, "No releases found.")
+		return err
+	}
+
+	goos, goarch := platformOptions().Platform()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintf(                                  //nolint:errcheck
+		tw,
+		"TAG\tPUBLISHED\tPRERELEASE\tASSETS\t%s/%s\n",
+		goos,
+		goarch,
+	)
+	for _, s := range summaries {
+		published := "-"
+		if !s.Published.IsZero() {
+			published = s.Published.Format(time.DateOnly)
+		}
+		fmt.Fprintf( //nolint:errcheck
+			tw,
+			"%s\t%s\t%s\t%d\t%s\n",
+			s.Tag,
+			published,
+			yesNo(s.Prerelease),
+			s.Assets,
+			yesNo(s.Matches),
+		)
+	}
+	return tw.Flush()
+}
+
+// yesNo formats b for a table column.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
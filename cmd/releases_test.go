@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_listRecentReleases(t *testing.T) {
+	pages := map[string]string{
+		"":  `[{"tag_name":"v3.0.0"},{"tag_name":"v2.9.0","draft":true},{"tag_name":"v2.1.0"}]`,
+		"2": `[{"tag_name":"v2.0.0"},{"tag_name":"v1.0.0"}]`,
+	}
+	var requests int
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				next := fmt.Sprintf(`<http://%s%s?page=2>; rel="next"`, r.Host, r.URL.Path)
+				w.Header().Set("Link", next)
+			}
+			fmt.Fprint(w, pages[page]) //nolint:errcheck
+		}),
+	)
+
+	tests := []struct {
+		name         string
+		limit        int
+		wantTags     []string
+		wantRequests int
+	}{
+		{
+			name:         "first page suffices",
+			limit:        2,
+			wantTags:     []string{"v3.0.0", "v2.1.0"},
+			wantRequests: 1,
+		},
+		{
+			name:         "follows pagination",
+			limit:        3,
+			wantTags:     []string{"v3.0.0", "v2.1.0", "v2.0.0"},
+			wantRequests: 2,
+		},
+		{
+			name:         "fewer releases than the limit",
+			limit:        10,
+			wantTags:     []string{"v3.0.0", "v2.1.0", "v2.0.0", "v1.0.0"},
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			releases, err := listRecentReleases(
+				context.Background(), client, "owner", "repo", tt.limit,
+			)
+			if err != nil {
+				t.Fatalf("listRecentReleases() error = %v", err)
+			}
+			var tags []string
+			for _, release := range releases {
+				tags = append(tags, release.GetTagName())
+			}
+			if strings.Join(tags, ",") != strings.Join(tt.wantTags, ",") {
+				t.Errorf("listRecentReleases() tags = %v, want %v", tags, tt.wantTags)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("listRecentReleases() made %d requests, want %d",
+					requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func Test_printReleasesTable(t *testing.T) {
+	utils.GetOSArch()
+	platform := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	published := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	releases := []*github.RepositoryRelease{
+		{
+			TagName:     github.Ptr("v2.0.0-rc.1"),
+			Prerelease:  github.Ptr(true),
+			PublishedAt: &github.Timestamp{Time: published},
+			Assets: []*github.ReleaseAsset{
+				{Name: github.Ptr("tool_" + platform + ".tar.gz")},
+				{Name: github.Ptr("checksums.txt")},
+			},
+		},
+		{
+			TagName: github.Ptr("v1.0.0"),
+			Assets:  []*github.ReleaseAsset{{Name: github.Ptr("tool_plan9_mips.tar.gz")}},
+		},
+	}
+
+	summaries := summarizeReleases(releases, utils.NewMatcher(runtime.GOOS, runtime.GOARCH))
+	if !summaries[0].Matches || summaries[1].Matches {
+		t.Errorf("summarizeReleases() matches = %t, %t, want true, false",
+			summaries[0].Matches, summaries[1].Matches)
+	}
+
+	var out bytes.Buffer
+	if err := printReleasesTable(&out, summaries); err != nil {
+		t.Fatalf("printReleasesTable() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("printReleasesTable() printed %d lines, want 3:\n%s", len(lines), out.String())
+	}
+	wantRows := [][]string{
+		{"TAG", "PUBLISHED", "PRERELEASE", "ASSETS", runtime.GOOS + "/" + runtime.GOARCH},
+		{"v2.0.0-rc.1", "2025-03-14", "yes", "2", "yes"},
+		{"v1.0.0", "-", "no", "1", "no"},
+	}
+	for i, want := range wantRows {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("printReleasesTable() line %d = %q, want %q", i, got, want)
+		}
+	}
+
+	var empty bytes.Buffer
+	if err := printReleasesTable(&empty, nil); err != nil {
+		t.Fatalf("printReleasesTable() error = %v", err)
+	}
+	if !strings.Contains(empty.String(), "No releases found") {
+		t.Errorf("printReleasesTable() with no releases = %q", empty.String())
+	}
+}
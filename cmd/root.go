@@ -5,51 +5,107 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/adrg/xdg"
 	"github.com/fatih/color"
 	"github.com/google/go-github/v80/github"
-	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
 	"github.com/esacteksab/gh-install/ghclient"
+	"github.com/esacteksab/gh-install/install"
 	"github.com/esacteksab/gh-install/utils"
 )
 
 // Build information variables populated at build time
 var (
-	binNameFlag string // binNameFlag is the value from the --binName flag
-	pathFlag    string // pathFlag is the value from the --path flag
-	shaFlag     string // shaFlag is the value from the --sha flag
-	Version     string // Application version
-	Date        string // Build date
-	Commit      string // Git commit hash
-	BuiltBy     string // Builder identifier
-	green       = color.New(color.FgGreen).SprintFunc()
-	red         = color.New(color.FgRed).SprintFunc()
-	yellow      = color.New(color.FgYellow).SprintFunc()
+	allBinariesFlag bool          // allBinariesFlag is the value from the --all-binaries flag
+	allowWeakFlag   bool          // allowWeakFlag is the value from the --allow-weak-checksum flag
+	apiURLFlag      string        // apiURLFlag is the value from the --github-api-url flag
+	archAliasFlag   []string      // archAliasFlag holds the pairs from the --arch-alias flag
+	archFlag        string        // archFlag is the value from the --arch flag
+	assetFlag       string        // assetFlag is the value from the --asset flag
+	assetRegexFlag  string        // assetRegexFlag is the value from the --asset-regex flag
+	binNameFlag     string        // binNameFlag is the value from the --binName flag
+	cacheDirFlag    string        // cacheDirFlag is the value from the --cache-dir flag
+	concurrencyFlag int           // concurrencyFlag is the value from the --concurrency flag
+	configFlag      string        // configFlag is the value from the --config flag
+	configShaFlag   string        // configShaFlag is the value from the --config-sha flag
+	contentTypeFlag []string      // contentTypeFlag holds the values of the --content-type flag
+	defaultAlgoFlag string        // defaultAlgoFlag is the value from the --default-algo flag
+	downloadOnly    bool          // downloadOnly is the value from the --download-only flag
+	draftsFlag      bool          // draftsFlag is the value from the --include-drafts flag
+	dryRunFlag      bool          // dryRunFlag is the value from the --dry-run flag
+	extractFlag     []string      // extractFlag holds the names from the --extract flag
+	failFastFlag    bool          // failFastFlag is the value from the --fail-fast flag
+	forceFlag       bool          // forceFlag is the value from the --force flag
+	frozenFlag      bool          // frozenFlag is the value from the --frozen flag
+	hostFlag        string        // hostFlag is the value from the --host flag
+	ignoreHookFlag  bool          // ignoreHookFlag is the value from the --ignore-hook-errors flag
+	keepGoingFlag   bool          // keepGoingFlag is the value from the --keep-going flag
+	noCacheFlag     bool          // noCacheFlag is the value from the --no-cache flag
+	noRosettaFlag   bool          // noRosettaFlag is the value from the --no-rosetta-fallback flag
+	osFlag          string        // osFlag is the value from the --os flag
+	osFamilyFlag    string        // osFamilyFlag is the value from the --os-family flag
+	outputFlag      string        // outputFlag is the value from the --output flag
+	quietFlag       bool          // quietFlag is the value from the --quiet flag
+	gpgKeyFlag      []string      // gpgKeyFlag holds the public key files from the --gpg-key flag
+	keepArchiveFlag string        // keepArchiveFlag is the directory from the --keep-archive flag
+	logFileFlag     string        // logFileFlag is the value from the --log-file flag
+	logFileOnlyFlag bool          // logFileOnlyFlag is the value from the --log-file-only flag
+	logFormatFlag   string        // logFormatFlag is the value from the --log-format flag
+	minRateFlag     int           // minRateFlag is the value from the --min-rate-remaining flag
+	pathFlag        string        // pathFlag is the value from the --path flag
+	postInstallFlag string        // postInstallFlag is the value from the --post-install flag
+	prereleaseFlag  bool          // prereleaseFlag is the value from the --prerelease flag
+	printPathFlag   bool          // printPathFlag is the value from the --print-path flag
+	requireSumFlag  bool          // requireSumFlag is the value from the --require-checksum flag
+	selectFlag      string        // selectFlag is the value from the --select flag
+	shaFlag         string        // shaFlag is the value from the --sha flag
+	sourceFlag      bool          // sourceFlag is the value from the --source flag
+	sudoFlag        bool          // sudoFlag is the value from the --sudo flag
+	symlinkFlag     bool          // symlinkFlag is the value from the --symlink flag
+	timeoutFlag     time.Duration // timeoutFlag is the value from the --timeout flag
+	uploadURLFlag   string        // uploadURLFlag is the value from the --github-upload-url flag
+	verboseFlag     bool          // verboseFlag is the value from the --verbose flag
+	yesFlag         bool          // yesFlag is the value from the --yes flag
+	verifySigFlag   bool          // verifySigFlag is the value from the --verify-signature flag
+	verifyProvFlag  bool          // verifyProvFlag is the value from the --verify-provenance flag
+	Version         string        // Application version
+	Date            string        // Build date
+	Commit          string        // Git commit hash
+	BuiltBy         string        // Builder identifier
+	// showProgress enables download progress, see install.InstallOptions.Progress;
+	// it's replaced by a log line per download while several run concurrently.
+	showProgress = true
+	// startRate is the core rate limit reported when the GitHub client was created; nil
+	// when it couldn't be retrieved.
+	startRate *github.Rate
 )
 
-// Asset represents a successfully downloaded and verified release asset
-type Asset struct {
-	Name     string // Original filename of the downloaded asset from GitHub
-	Path     string // Local path where the asset was saved
-	MIMEType string // MIME content type of the asset
-}
-
-// Environment variable name for enabling debug logging during initialization
-const ghInstallInitDebugEnv = "GH_INSTALL_INIT_DEBUG"
+const (
+	// Environment variable name for enabling debug logging during initialization
+	ghInstallInitDebugEnv = "GH_INSTALL_INIT_DEBUG"
+	// Environment variable that disables colored output when set, see https://no-color.org
+	noColorEnv = "NO_COLOR"
+	// Default number of --config entries installed in parallel
+	defaultConcurrency = 4
+	// Default time a single GitHub API call may take before it's abandoned
+	defaultTimeout = 60 * time.Second
+	// Rough number of GitHub API requests a single install makes: the release lookup and
+	// the downloads of its asset and checksum file
+	requestsPerInstall = 3
+)
 
 // init is automatically called when the package is loaded.
 func init() {
 	// Creates initial logger with log level info
 	utils.CreateLogger(false)
+	rootCmd.RunE = runInstall
 	rootCmd.Version = utils.BuildVersion(Version, Commit, Date, BuiltBy)
 	rootCmd.SetVersionTemplate(`{{printf "Version %s" .Version}}`)
 
@@ -73,7 +129,47 @@ func init() {
 		"binName",
 		"b",
 		"",
-		"name to save binary as",
+		"name to save binary as; may be a template, e.g. 'tool_{{.Version}}_{{.OS}}'",
+	)
+	// Explicit asset selection
+	rootCmd.PersistentFlags().StringVar(
+		&assetFlag,
+		"asset",
+		"",
+		"exact name of the release asset to install, bypassing OS/Arch matching",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&assetRegexFlag,
+		"asset-regex",
+		"",
+		"regular expression selecting the release asset to install, bypassing OS/Arch matching",
+	)
+	// Extra architecture names used in asset names
+	rootCmd.PersistentFlags().StringSliceVar(
+		&archAliasFlag,
+		"arch-alias",
+		nil,
+		"extra name release assets use for an architecture, e.g. amd64=x64 (repeatable)",
+	)
+	// Target platform, for installing binaries meant for another machine
+	rootCmd.PersistentFlags().StringVar(
+		&osFlag,
+		"os",
+		"",
+		"operating system to match assets for, as in GOOS (default: this system's)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&archFlag,
+		"arch",
+		"",
+		"architecture to match assets for, as in GOARCH (default: this system's)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&osFamilyFlag,
+		"os-family",
+		"",
+		"OS family .deb, .rpm and .apk packages must fit: debian, rhel, suse or alpine "+
+			"(default: detected from /etc/os-release)",
 	)
 	rootCmd.PersistentFlags().
 		StringVarP(
@@ -83,6 +179,313 @@ func init() {
 			"",
 			usageMessage,
 		)
+	rootCmd.PersistentFlags().StringVar(
+		&defaultAlgoFlag,
+		"default-algo",
+		"",
+		"algorithm of checksum files whose name doesn't tell it, e.g. checksums.txt "+
+			"(default: guessed from the checksum length, else "+
+			utils.DefaultAlgorithmForGenericChecksums+")",
+	)
+	// Config manifest
+	rootCmd.PersistentFlags().StringVarP(
+		&configFlag,
+		"config",
+		"c",
+		"",
+		"TOML manifest of binaries to install (replaces the owner/repo argument)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&configShaFlag,
+		"config-sha",
+		"",
+		"with --config, the manifest's sha256 (or sha224, sha384, sha512) checksum; "+
+			"nothing is installed if it doesn't match",
+	)
+	// GitHub Enterprise Server host
+	rootCmd.PersistentFlags().StringVar(
+		&hostFlag,
+		"host",
+		"",
+		"GitHub Enterprise Server host or API URL. Default: $GITHUB_API_URL, $GH_HOST or github.com",
+	)
+	// API mirrors and proxies
+	rootCmd.PersistentFlags().StringVar(
+		&apiURLFlag,
+		"github-api-url",
+		"",
+		"absolute URL to send GitHub API requests to instead, e.g. a caching proxy",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&uploadURLFlag,
+		"github-upload-url",
+		"",
+		"absolute URL to send GitHub upload requests to instead",
+	)
+	// Source code archives
+	rootCmd.PersistentFlags().BoolVar(
+		&sourceFlag,
+		"source",
+		false,
+		"download the release's source code archive to --path instead of a binary",
+	)
+	// Content type filter
+	rootCmd.PersistentFlags().StringSliceVar(
+		&contentTypeFlag,
+		"content-type",
+		nil,
+		"only match assets with this content type, e.g. application/gzip (repeatable)",
+	)
+	// Keep downloaded archives
+	rootCmd.PersistentFlags().StringVar(
+		&keepArchiveFlag,
+		"keep-archive",
+		"",
+		"copy the verified release archive to this directory before extracting it "+
+			"(current directory when given without a value; use --keep-archive=DIR)",
+	)
+	rootCmd.PersistentFlags().Lookup("keep-archive").NoOptDefVal = "."
+	// Archives with several binaries
+	rootCmd.Flags().StringSliceVar(
+		&extractFlag,
+		"extract",
+		nil,
+		"names of the binaries to install from the release archive, "+
+			"e.g. foo,foo-helper (repeatable)",
+	)
+	rootCmd.Flags().BoolVar(
+		&allBinariesFlag,
+		"all-binaries",
+		false,
+		"install every executable in the release archive under its own name",
+	)
+	// Post-install hook
+	rootCmd.Flags().StringVar(
+		&postInstallFlag,
+		"post-install",
+		"",
+		"shell command to run after installing, with $GH_INSTALL_PATH, $GH_INSTALL_BIN "+
+			"and $GH_INSTALL_VERSION set",
+	)
+	rootCmd.Flags().BoolVar(
+		&ignoreHookFlag,
+		"ignore-hook-errors",
+		false,
+		"only warn when the --post-install command fails instead of failing the install",
+	)
+	// Log format
+	rootCmd.PersistentFlags().StringVar(
+		&logFormatFlag,
+		"log-format",
+		utils.LogFormatText,
+		"format of log messages on stderr: text, json or logfmt",
+	)
+	// Log file
+	rootCmd.PersistentFlags().StringVar(
+		&logFileFlag,
+		"log-file",
+		"",
+		"also append log messages to this file",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&logFileOnlyFlag,
+		"log-file-only",
+		false,
+		"with --log-file, write log messages to the file only instead of also to stderr",
+	)
+	// HTTP cache location
+	rootCmd.PersistentFlags().StringVar(
+		&cacheDirFlag,
+		"cache-dir",
+		"",
+		"directory to keep the gh-install cache of GitHub API responses in. "+
+			"Default: $XDG_CACHE_HOME. Env: GH_INSTALL_CACHE_DIR",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&noCacheFlag,
+		"no-cache",
+		false,
+		"send every GitHub API request to GitHub instead of using cached responses",
+	)
+	// Fail-closed checksum verification
+	rootCmd.PersistentFlags().BoolVar(
+		&requireSumFlag,
+		"require-checksum",
+		false,
+		"fail instead of warning when no checksum can be verified. Env: "+
+			install.RequireChecksumEnv,
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&allowWeakFlag,
+		"allow-weak-checksum",
+		false,
+		"verify with md5, sha1 or crc32 when the checksum file only offers those "+
+			"instead of refusing to install",
+	)
+	// Signature verification
+	rootCmd.PersistentFlags().BoolVar(
+		&verifySigFlag,
+		"verify-signature",
+		false,
+		"require a valid cosign signature for the downloaded asset (needs cosign on $PATH)",
+	)
+	// SLSA provenance verification
+	rootCmd.PersistentFlags().BoolVar(
+		&verifyProvFlag,
+		"verify-provenance",
+		false,
+		"require valid SLSA provenance for the downloaded asset (needs slsa-verifier on $PATH)",
+	)
+	// GPG keys for checksum file signatures
+	rootCmd.PersistentFlags().StringSliceVar(
+		&gpgKeyFlag,
+		"gpg-key",
+		nil,
+		"armored GPG public key file used to verify the checksum file's signature (repeatable)",
+	)
+	// Parallel installs from a manifest
+	rootCmd.PersistentFlags().IntVar(
+		&concurrencyFlag,
+		"concurrency",
+		defaultConcurrency,
+		"number of binaries from --config to install in parallel",
+	)
+	// Failure handling of manifest installs, which only the install itself does
+	rootCmd.Flags().BoolVar(
+		&failFastFlag,
+		"fail-fast",
+		false,
+		"with --config, stop installing after the first entry fails",
+	)
+	rootCmd.Flags().BoolVar(
+		&keepGoingFlag,
+		"keep-going",
+		false,
+		"with --config, install the remaining entries when one fails (the default)",
+	)
+	rootCmd.MarkFlagsMutuallyExclusive("fail-fast", "keep-going")
+	// Rate limit budget
+	rootCmd.Flags().IntVar(
+		&minRateFlag,
+		"min-rate-remaining",
+		0,
+		"refuse to start when fewer GitHub API requests remain than this and the planned "+
+			"installs need",
+	)
+	// Rosetta 2 fallback on Apple Silicon
+	rootCmd.PersistentFlags().BoolVar(
+		&noRosettaFlag,
+		"no-rosetta-fallback",
+		false,
+		"on Apple Silicon, don't fall back to a darwin/amd64 asset when no arm64 one exists",
+	)
+	// Pre-releases
+	rootCmd.PersistentFlags().BoolVar(
+		&prereleaseFlag,
+		"prerelease",
+		false,
+		"install the newest release, including pre-releases, when no version is given",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&draftsFlag,
+		"include-drafts",
+		false,
+		"install a draft release requested by its tag (needs a token with push access)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&selectFlag,
+		"select",
+		selectGitHubLatest,
+		"release to install when no version is given: "+selectGitHubLatest+", "+
+			selectHighestSemver+" or "+selectNewestByDate,
+	)
+	// Dry run
+	rootCmd.PersistentFlags().BoolVar(
+		&dryRunFlag,
+		"dry-run",
+		false,
+		"print what would be installed without downloading or writing anything",
+	)
+	// Overwriting
+	rootCmd.Flags().BoolVar(
+		&forceFlag,
+		"force",
+		false,
+		"overwrite an existing file at the target path instead of refusing to install",
+	)
+	// Lockfile
+	rootCmd.PersistentFlags().BoolVar(
+		&frozenFlag,
+		"frozen",
+		false,
+		"with --config, install exactly the tags and checksums recorded in its lockfile",
+	)
+	// System packages
+	rootCmd.PersistentFlags().BoolVar(
+		&downloadOnly,
+		"download-only",
+		false,
+		"save a matched .deb/.rpm/.apk package to --path instead of installing it",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&sudoFlag,
+		"sudo",
+		false,
+		"run the system package manager with sudo when installing a .deb/.rpm/.apk package",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&yesFlag,
+		"yes",
+		"y",
+		false,
+		"install system packages without asking for confirmation",
+	)
+	// Versioned installs
+	rootCmd.PersistentFlags().BoolVar(
+		&symlinkFlag,
+		"symlink",
+		false,
+		"save the binary as <name>-<tag> and point a <name> symlink at it",
+	)
+	// Quiet
+	rootCmd.PersistentFlags().BoolVarP(
+		&quietFlag,
+		"quiet",
+		"q",
+		false,
+		"only print errors; hides the progress bar and informational messages",
+	)
+	// Verbose
+	rootCmd.PersistentFlags().BoolVarP(
+		&verboseFlag,
+		"verbose",
+		"v",
+		false,
+		"enable debug logging. Env: "+ghInstallInitDebugEnv,
+	)
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	// Output format
+	rootCmd.PersistentFlags().StringVarP(
+		&outputFlag,
+		"output",
+		"o",
+		outputText,
+		"output format: text or json (a JSON result on stdout, logs on stderr)",
+	)
+	rootCmd.Flags().BoolVar(
+		&printPathFlag,
+		"print-path",
+		false,
+		"print only the installed binary's path on stdout, e.g. for $(gh install owner/repo "+
+			"--print-path)",
+	)
+	// API timeout
+	rootCmd.PersistentFlags().DurationVar(
+		&timeoutFlag,
+		"timeout",
+		defaultTimeout,
+		"maximum duration of a single GitHub API call, 0 to disable",
+	)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -95,8 +498,11 @@ func Execute() {
 		ghInstallInitDebugEnv,
 		initialVerbose,
 	)
-	utils.GetOSArch()
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the context so in-flight requests and downloads stop promptly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		utils.Logger.Errorf("error: %s", err)
 		os.Exit(1)
 	}
@@ -110,498 +516,319 @@ var rootCmd = &cobra.Command{
 	Long: `gh installs binaries published on GitHub releases.
 Detects Operating System and Architecture to download and
 install the appropriate binary. Includes checksum verification if available.`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		a := args[0]
-		pa, err := utils.ParseArgs(a)
-		if err != nil {
-			return fmt.Errorf("invalid argument: %w", err)
+	Args:              validateArgs,
+	ValidArgsFunction: completeInstalledRepos,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		configureColor()
+		if verboseFlag {
+			// GH_INSTALL_INIT_DEBUG covers logging before the flags are parsed
+			utils.CreateLogger(true)
 		}
-
-		ctx := context.Background()
-		client, err := ghclient.NewClient(ctx)
-		if err != nil {
-			utils.Logger.Errorf("Failed to initialize GitHub client: %v", err)
-			return fmt.Errorf("failed to initialize GitHub client: %v", err)
+		if err := utils.SetLogFormat(logFormatFlag); err != nil {
+			return err
 		}
-		ghclient.CheckRateLimit(ctx, client)
-
-		var assets []*github.ReleaseAsset
-		var releaseTag string
-
-		if pa.Version == "latest" || pa.Version == "" {
-			utils.Logger.Printf("Fetching assets for latest release of %s/%s", pa.Owner, pa.Repo)
-			release, err := getLatestRelease(ctx, client, pa.Owner, pa.Repo)
-			if err != nil {
-				return fmt.Errorf("could not get latest release: %w", err)
-			}
-			assets = release.Assets
-			releaseTag = release.GetTagName()
-			utils.Logger.Printf("Latest release tag: %s", releaseTag)
-		} else {
-			utils.Logger.Printf("Fetching assets for release tag '%s' of %s/%s", pa.Version, pa.Owner, pa.Repo)
-			release, err := getTaggedRelease(ctx, client, pa.Owner, pa.Repo, pa.Version)
-			if err != nil {
-				return fmt.Errorf("could not get release for tag '%s': %w", pa.Version, err)
+		if logFileOnlyFlag && logFileFlag == "" {
+			return errors.New("--log-file-only requires --log-file")
+		}
+		if logFileFlag != "" {
+			if err := utils.SetLogFile(logFileFlag, logFileOnlyFlag); err != nil {
+				utils.Logger.Warnf("Logging to stderr only: %v", err)
 			}
-			assets = release.Assets
-			releaseTag = release.GetTagName()
 		}
-
-		if len(assets) == 0 {
-			return fmt.Errorf("no assets found for release '%s'", releaseTag)
+		if quietFlag {
+			showProgress = false
+			utils.SetQuiet()
 		}
-
-		downloadedAsset, err := findDownloadAndVerifyAsset(
-			ctx,
-			client,
-			pa.Owner,
-			pa.Repo,
-			assets,
-			http.DefaultClient,
-		)
-		if err != nil {
+		if err := applyArchAliases(archAliasFlag); err != nil {
 			return err
 		}
-
-		utils.Logger.Debugf("Successfully downloaded and verified: %s", downloadedAsset.Name)
-		utils.Logger.Debugf("Asset saved to: %s", downloadedAsset.Path)
-		utils.Logger.Debugf("Asset MIME Type: %s", downloadedAsset.MIMEType)
-		utils.Logger.Debugf("chmod'ing %s", downloadedAsset.Name)
-		utils.ChmodFile(downloadedAsset.Path)
-		utils.Logger.Debug(">>> Next steps (unpacking, installation) are not yet implemented. <<<")
+		if osFamilyFlag != "" {
+			if _, ok := utils.PackageExtForFamily(osFamilyFlag); !ok {
+				return fmt.Errorf(
+					"invalid --os-family '%s': use debian, rhel, suse or alpine",
+					osFamilyFlag,
+				)
+			}
+		}
+		if err := validateSelect(selectFlag, prereleaseFlag); err != nil {
+			return err
+		}
+		if defaultAlgoFlag != "" {
+			if _, err := utils.GetHasher(defaultAlgoFlag); err != nil {
+				return fmt.Errorf("invalid --default-algo: %w", err)
+			}
+		}
+		if platform := platformOptions(); platform.CrossTarget() {
+			goos, goarch := platform.Platform()
+			utils.Logger.Infof("Matching assets for %s/%s", goos, goarch)
+		}
 		return nil
 	},
 }
 
-func getLatestRelease(
-	ctx context.Context,
-	client *github.Client,
-	owner, repo string,
-) (*github.RepositoryRelease, error) {
-	release, resp, err := client.Repositories.GetLatestRelease(ctx, owner, repo)
-	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return nil, fmt.Errorf("repository %s/%s not found or has no releases", owner, repo)
-		}
-		rateLimitInfo := ""
-		if resp != nil {
-			rateLimitInfo = resp.Rate.String()
-		}
-		return nil, fmt.Errorf(
-			"failed to get latest release: %w (Rate Limit: %s)",
-			err,
-			rateLimitInfo,
-		)
+// runInstall installs the release given as argument, or every entry of the --config
+// manifest. It's assigned to rootCmd in init, as installing refers back to rootCmd.
+func runInstall(cmd *cobra.Command, args []string) error {
+	if err := validateOutput(outputFlag); err != nil {
+		return err
 	}
-	if release == nil {
-		return nil, errors.New("received nil release object from GitHub API")
+	if frozenFlag && configFlag == "" {
+		return errors.New("--frozen requires --config")
 	}
-	return release, nil
-}
-
-func getTaggedRelease(
-	ctx context.Context,
-	client *github.Client,
-	owner, repo, tag string,
-) (*github.RepositoryRelease, error) {
-	release, resp, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	if configShaFlag != "" && configFlag == "" {
+		return errors.New("--config-sha requires --config")
+	}
+	if (failFastFlag || keepGoingFlag) && configFlag == "" {
+		return errors.New("--fail-fast and --keep-going require --config")
+	}
+	assetRegex, err := validateAssetFlags(assetFlag, assetRegexFlag)
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return nil, fmt.Errorf("release with tag '%s' not found in %s/%s", tag, owner, repo)
-		}
-		rateLimitInfo := ""
-		if resp != nil {
-			rateLimitInfo = resp.Rate.String()
-		}
-		return nil, fmt.Errorf(
-			"failed to get release by tag '%s': %w (Rate Limit: %s)",
-			tag,
-			err,
-			rateLimitInfo,
-		)
+		return err
 	}
-	if release == nil {
-		return nil, fmt.Errorf("received nil release object for tag '%s' from GitHub API", tag)
+	if sourceFlag && (assetFlag != "" || assetRegexFlag != "" || configFlag != "") {
+		return errors.New("--source can't be used with --asset, --asset-regex or --config")
 	}
-	return release, nil
-}
-
-// downloadAndSaveAsset downloads a specific release asset and saves it to targetSavePath.
-// Returns the path where the file was saved (which is targetSavePath on success) and any error.
-func downloadAndSaveAsset(
-	ctx context.Context,
-	client *github.Client,
-	owner, repo string,
-	asset *github.ReleaseAsset,
-	httpClient *http.Client,
-	targetSavePath string,
-) (filePath string, err error) {
-	if asset == nil || asset.Name == nil || asset.ID == nil || asset.Size == nil {
-		return "", errors.New("asset has missing information (name, id, or size)")
+	if err := validateExtractFlags(extractFlag, allBinariesFlag); err != nil {
+		return err
 	}
-
-	assetName := *asset.Name
-	assetID := *asset.ID
-	assetSize := *asset.Size
-
-	utils.Logger.Debugf(
-		"Initiating download for asset: %s (ID: %d, Size: %d) to target path: %s",
-		assetName,
-		assetID,
-		assetSize,
-		targetSavePath,
-	)
-
-	rc, redirectURL, err := client.Repositories.DownloadReleaseAsset(
-		ctx,
-		owner,
-		repo,
-		assetID,
-		httpClient,
-	)
-	if err != nil {
-		return "", fmt.Errorf("error initiating download for '%s': %w", assetName, err)
-	}
-	if rc == nil {
-		if redirectURL != "" {
-			utils.Logger.Warnf(
-				"Download for '%s' resulted in a redirect URL (%s) but no reader.",
-				assetName,
-				redirectURL,
-			)
-			return "", fmt.Errorf(
-				"download resulted in redirect URL '%s' instead of data stream",
-				redirectURL,
-			)
-		}
-		return "", fmt.Errorf(
-			"download request for '%s' returned no data stream and no error",
-			assetName,
-		)
+	if printPathFlag && (outputFlag == outputJSON || configFlag != "") {
+		return errors.New("--print-path can't be used with --output json or --config")
 	}
-	defer rc.Close() //nolint:errcheck
-
-	if redirectURL != "" {
-		utils.Logger.Warnf(
-			"Received both a reader and a redirect URL ('%s') for asset '%s'. Proceeding with download.",
-			redirectURL,
-			assetName,
-		)
+	if outputFlag == outputJSON || printPathFlag {
+		// Keep stdout clean for the JSON result or the path
+		showProgress = false
 	}
 
-	// Use the provided targetSavePath to save the file
-	err = saveAssetToFile(rc, targetSavePath, assetName, int64(assetSize))
-	if err != nil {
-		// Error already contains context from saveAssetToFile
-		return targetSavePath, err // Return targetSavePath even on error for potential cleanup
+	var pa utils.ParsedArgs
+	if configFlag == "" {
+		pa, err = utils.ParseArgs(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid argument: %w", err)
+		}
 	}
 
-	// Return the path where the file was saved
-	return targetSavePath, nil
-}
-
-// saveAssetToFile saves asset data from a reader to a local file with progress display.
-// localPath is the exact path where the file should be created.
-// displayName is the original asset name for the progress bar.
-func saveAssetToFile(rc io.ReadCloser, localPath, displayName string, assetSize int64) error {
-	utils.Logger.Debugf("Saving asset '%s' to specific local path '%s'", displayName, localPath)
-
-	// Create the output file at the specified localPath
-	file, err := os.Create(localPath) //nolint:gosec
+	ctx := cmd.Context()
+	client, err := newClient(ctx)
 	if err != nil {
-		return fmt.Errorf("error creating file '%s': %w", localPath, err)
+		utils.Logger.Errorf("Failed to initialize GitHub client: %v", err)
+		return fmt.Errorf("failed to initialize GitHub client: %v", err)
 	}
-	var fileClosed bool
-	defer func() {
-		if !fileClosed {
-			file.Close() //nolint:errcheck,gosec
-		}
-	}()
 
-	bar := progressbar.NewOptions64(
-		assetSize,
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionSetWidth(35), //nolint:mnd
-		progressbar.OptionSetDescription(
-			fmt.Sprintf("[cyan]Downloading %s...[reset]", displayName),
-		),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
-		progressbar.OptionClearOnFinish(),
-	)
-
-	_, copyErr := io.Copy(io.MultiWriter(file, bar), rc)
-	closeErr := file.Close()
-	fileClosed = true
-
-	if copyErr != nil {
-		utils.Logger.Errorf(
-			"Error during download/copy for '%s' to '%s': %v",
-			displayName,
-			localPath,
-			copyErr,
-		)
-		_ = os.Remove(localPath) // Attempt cleanup on copy error
-		return fmt.Errorf("error saving data for '%s' to '%s': %w", displayName, localPath, copyErr)
-	}
-	if closeErr != nil {
-		utils.Logger.Errorf("Error closing file '%s' after download: %v", localPath, closeErr)
-		// Do not return error here if copy was successful, but log it.
+	if configFlag != "" {
+		return installFromConfig(ctx, client, configFlag)
 	}
-	if !utils.IsChecksumFile(localPath) {
-		utils.Logger.Debugf(green("✔")+" Successfully downloaded %s to %s", displayName, localPath)
-		utils.Logger.Print(green("✔") + " Successfully downloaded")
+	if err := ensureRateBudget(startRate, 1); err != nil {
+		return err
 	}
-	return nil
-}
-
-// need to address gocyclo
-// funlen 52 > 50 -- maybe not an issue
-func findDownloadAndVerifyAsset( //nolint:gocyclo,funlen
-	ctx context.Context,
-	client *github.Client,
-	owner, repo string,
-	assets []*github.ReleaseAsset,
-	httpClient *http.Client,
-) (Asset, error) {
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+	version, err := selectedVersion(ctx, client, pa)
+	if err != nil {
+		return err
 	}
 
-	var mainAssetToDownload *github.ReleaseAsset
-	var checksumAssetToDownload *github.ReleaseAsset
-
-	utils.Logger.Debugf(
-		"Scanning %d assets to find matching binary/archive and checksum file...",
-		len(assets),
-	)
-
-	for _, asset := range assets {
-		if asset == nil || asset.Name == nil || asset.ID == nil {
-			utils.Logger.Debug("Skipping asset with missing name or ID.")
-			continue
-		}
-		assetName := *asset.Name
-		if utils.IsChecksumFile(assetName) {
-			if checksumAssetToDownload == nil {
-				utils.Logger.Debugf("Found potential checksum file: %s", assetName)
-				checksumAssetToDownload = asset
-			} else {
-				utils.Logger.Warnf("Found multiple checksum files. Using '%s', ignoring '%s'.", *checksumAssetToDownload.Name, assetName)
-			}
-			continue
+	opts := baseInstallOptions()
+	opts.Owner, opts.Repo, opts.Version = pa.Owner, pa.Repo, version
+	opts.Client = client
+	opts.BinName = binNameFlag
+	opts.Path = pathFlag
+	opts.Symlink = symlinkFlag
+	opts.Asset = assetFlag
+	opts.AssetRegex = assetRegex
+	opts.ContentTypes = contentTypeFlag
+	opts.Source = sourceFlag
+	opts.Force = forceFlag
+	opts.Extract = extractFlag
+	opts.AllBinaries = allBinariesFlag
+	opts.PostInstall = postInstallFlag
+
+	start := time.Now()
+	asset, err := install.Install(ctx, opts)
+	if outputFlag == outputJSON {
+		report := newInstallReport(pa.Owner, pa.Repo, asset, time.Since(start), err)
+		if jsonErr := writeJSON(cmd.OutOrStdout(), report); jsonErr != nil && err == nil {
+			return jsonErr
 		}
-		if utils.MatchFile(assetName) {
-			if mainAssetToDownload == nil {
-				utils.Logger.Debugf("Found potential main asset: %s", assetName)
-				mainAssetToDownload = asset
-			} else {
-				utils.Logger.Warnf("Found multiple matching assets. Using '%s', ignoring '%s'.", *mainAssetToDownload.Name, assetName)
-			}
+	}
+	if err == nil && printPathFlag {
+		if asset.Path == "" {
+			// System packages are installed wherever the package manager puts them
+			utils.Logger.Warn("--print-path: the system package manager chose where to install")
+			return nil
 		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), asset.Path)
 	}
+	return err
+}
 
-	if mainAssetToDownload == nil {
-		utils.Logger.Error("No asset matching OS/Arch found.")
-		return Asset{}, errors.New("no suitable asset found for download")
+// baseInstallOptions returns the install options set by the flags that apply to every
+// install of a run, whether of the owner/repo argument, a --config entry or an upgrade.
+func baseInstallOptions() install.InstallOptions {
+	opts := install.InstallOptions{
+		Prerelease:        prereleaseFlag,
+		IncludeDrafts:     draftsFlag,
+		OS:                osFlag,
+		OSFamily:          osFamilyFlag,
+		Arch:              archFlag,
+		NoRosettaFallback: noRosettaFlag,
+		KeepArchive:       keepArchiveFlag,
+		Sha:               shaFlag,
+		DefaultAlgorithm:  defaultAlgoFlag,
+		RequireChecksum:   requireSumFlag,
+		AllowWeakChecksum: allowWeakFlag,
+		GPGKeys:           gpgKeyFlag,
+		VerifySignature:   verifySigFlag,
+		VerifyProvenance:  verifyProvFlag,
+		DownloadOnly:      downloadOnly,
+		Sudo:              sudoFlag,
+		Yes:               yesFlag,
+		IgnoreHookErrors:  ignoreHookFlag,
+		DryRun:            dryRunFlag,
+		Record:            true,
+		Progress:          showProgress,
+		Timeout:           timeoutFlag,
+		NoCache:           noCacheFlag,
+		Host:              hostFlag,
+		APIURL:            apiURLFlag,
+		UploadURL:         uploadURLFlag,
+		CacheDir:          cacheDirFlag,
 	}
-
-	utils.Logger.Debugf("Selected main asset for download: %s", *mainAssetToDownload.Name)
-	if checksumAssetToDownload != nil {
-		utils.Logger.Debugf("Selected checksum file: %s", *checksumAssetToDownload.Name)
-	} else {
-		utils.Logger.Warn(yellow("No checksum file found. Proceeding without verification."))
+	if outputFlag != outputJSON && !printPathFlag {
+		opts.Out = rootCmd.OutOrStdout()
 	}
-
-	// Determine Save Path for Main Asset
-	var finalMainAssetSaveName string
-	if binNameFlag != "" { // User specified --binName
-		finalMainAssetSaveName = binNameFlag
-	} else {
-		// final main asset name (fman)
-		fman := utils.ParseBinaryName(*mainAssetToDownload.Name)
-		finalMainAssetSaveName = fman
+	if install.IsInteractive() {
+		opts.ChooseAsset = install.PromptAsset(os.Stdin, os.Stderr)
 	}
+	return opts
+}
 
-	var targetMainAssetDir string
-	switch {
-	case pathFlag != "" && pathFlag != ".": // User specified --path directory
-		targetMainAssetDir = filepath.Clean(pathFlag)
-	case pathFlag == ".": // User specified current directory
-		targetMainAssetDir = "."
-	default: // Default to XDG Bin Home
-		targetMainAssetDir = xdg.BinHome
+// configureColor disables colored output when NO_COLOR is set. fatih/color already
+// disables it when stdout isn't a terminal.
+func configureColor() {
+	if os.Getenv(noColorEnv) != "" {
+		color.NoColor = true
 	}
+}
 
-	// Ensure the target directory exists (unless it's current dir)
-	if targetMainAssetDir != "." {
-		if err := os.MkdirAll(targetMainAssetDir, 0o750); err != nil { //nolint:mnd
-			return Asset{}, fmt.Errorf(
-				"failed to create target directory '%s': %w",
-				targetMainAssetDir,
-				err,
-			)
+// applyArchAliases registers the GOARCH=alias pairs given with --arch-alias, so the
+// OS/Arch patterns compiled for installs afterwards match assets using those names.
+func applyArchAliases(aliases []string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	for _, pair := range aliases {
+		goArch, alias, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid --arch-alias '%s', want GOARCH=alias, e.g. amd64=x64", pair)
+		}
+		err := utils.AddArchAlias(strings.TrimSpace(goArch), strings.TrimSpace(alias))
+		if err != nil {
+			return err
 		}
 	}
-	targetMainAssetSavePath := filepath.Join(targetMainAssetDir, finalMainAssetSaveName)
-	utils.Logger.Debugf(
-		"Main asset ('%s') will be saved as: %s",
-		*mainAssetToDownload.Name,
-		targetMainAssetSavePath,
-	)
+	return nil
+}
 
-	// Download Main Asset
-	downloadedMainAssetActualPath, err := downloadAndSaveAsset(
-		ctx, client, owner, repo, mainAssetToDownload, httpClient, targetMainAssetSavePath,
-	)
+// platformOptions returns the install options set by --os, --arch and
+// --no-rosetta-fallback, which select the platform assets are matched for.
+func platformOptions() install.InstallOptions {
+	return install.InstallOptions{OS: osFlag, Arch: archFlag, NoRosettaFallback: noRosettaFlag}
+}
+
+// newClient creates the GitHub client and reports the remaining rate limit, giving
+// each of these API calls at most --timeout.
+func newClient(ctx context.Context) (*github.Client, error) {
+	apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+	defer cancel()
+	client, err := ghclient.NewClientWithOptions(apiCtx, ghclient.ClientOptions{
+		MaxRetries: install.RateLimitRetries,
+		NoCache:    noCacheFlag,
+		Host:       hostFlag,
+		APIURL:     apiURLFlag,
+		UploadURL:  uploadURLFlag,
+		CacheDir:   cacheDirFlag,
+	})
 	if err != nil {
-		// downloadAndSaveAsset now includes targetMainAssetSavePath in its error reporting if relevant
-		return Asset{}, fmt.Errorf(
-			"failed to download main asset '%s': %w",
-			*mainAssetToDownload.Name,
-			err,
-		)
+		return nil, err
 	}
-	// downloadedMainAssetActualPath should be == targetMainAssetSavePath on success
-
-	// Download Checksum File and Verify (if found)
-	if checksumAssetToDownload != nil {
-		// Checksum file is always downloaded to the current directory with its original name
-		targetChecksumAssetSavePath := filepath.Clean(filepath.Base(*checksumAssetToDownload.Name))
-		utils.Logger.Debugf(
-			"Checksum asset ('%s') will be saved as: %s",
-			*checksumAssetToDownload.Name,
-			targetChecksumAssetSavePath,
-		)
+	startRate, _ = ghclient.CheckRateLimit(apiCtx, client) // Failures are logged, not fatal
+	return client, nil
+}
 
-		actualChecksumAssetPath, checksumErr := downloadAndSaveAsset(
-			ctx,
-			client,
-			owner,
-			repo,
-			checksumAssetToDownload,
-			httpClient,
-			targetChecksumAssetSavePath,
+// ensureRateBudget refuses to start when --min-rate-remaining is set and fewer than that
+// many GitHub API requests remain, unless they still cover the planned installs.
+//
+// - rate: The core rate limit, nil when it's unknown.
+// - installs: The number of binaries about to be installed.
+// Returns: An error naming the reset time when there aren't enough requests left.
+func ensureRateBudget(rate *github.Rate, installs int) error {
+	if minRateFlag <= 0 {
+		return nil
+	}
+	if rate == nil {
+		utils.Logger.Warn(
+			"Couldn't get the GitHub API rate limit; not checking --min-rate-remaining",
 		)
-		if checksumErr != nil {
-			utils.Logger.Errorf(
-				red(
-					"Failed to download checksum file '%s': %v. Checksum verification will be SKIPPED.",
-				),
-				*checksumAssetToDownload.Name,
-				checksumErr,
-			)
-			utils.Logger.Warnf(
-				yellow("Integrity of '%s' (at %s) is NOT confirmed."),
-				*mainAssetToDownload.Name, downloadedMainAssetActualPath,
-			)
-			// Proceed without verification in this case
-		} else {
-			// Pass the actual path of the (potentially renamed/relocated) main asset
-			// and its original name for checksum lookup
-			verifyErr := verifyAssetChecksum(downloadedMainAssetActualPath, *mainAssetToDownload.Name, actualChecksumAssetPath, shaFlag)
-			if verifyErr != nil {
-				// Verification failed. verifyAssetChecksum handles cleanup of downloadedMainAssetActualPath.
-				return Asset{}, verifyErr // verifyErr already contains context
-			}
-			// Verification successful, checksum file (actualChecksumAssetPath) removed by verifyAssetChecksum.
-			_ = os.Remove(actualChecksumAssetPath)
-		}
+		return nil
 	}
-
-	return Asset{
-		Name:     *mainAssetToDownload.Name,
-		Path:     downloadedMainAssetActualPath,
-		MIMEType: *mainAssetToDownload.ContentType,
-	}, nil
+	needed := installs * requestsPerInstall
+	if rate.Remaining >= minRateFlag || rate.Remaining >= needed {
+		return nil
+	}
+	return fmt.Errorf(
+		"only %d of %d GitHub API requests remain, fewer than --min-rate-remaining %d "+
+			"and the ~%d needed for %d install(s); the limit resets at %s",
+		rate.Remaining,
+		rate.Limit,
+		minRateFlag,
+		needed,
+		installs,
+		rate.Reset.Time.Local().Format("15:04:05 MST"),
+	)
 }
 
-func verifyAssetChecksum(
-	mainAssetDiskPath, mainAssetOriginalName, checksumAssetPath, shaFlag string,
-) error {
-	utils.Logger.Debug("Verifying checksum...")
-	var algoToUse string
-	var expectedChecksum string
-	var err error
-
-	if shaFlag != "" {
-		// User specified an algorithm
-		algoToUse = shaFlag
-		utils.Logger.Debugf("Using specified algorithm '%s' from --sha flag.", algoToUse)
-		expectedChecksum, err = utils.ParseChecksumFile(checksumAssetPath, mainAssetOriginalName)
-		if err != nil {
-			return fmt.Errorf(
-				"failed to parse checksum file '%s' for target '%s' (using --sha=%s): %w",
-				checksumAssetPath,
-				mainAssetOriginalName,
-				shaFlag,
-				err,
-			)
-		}
-	} else {
-		// Determine algorithm and get expected checksum via VerifyChecksum's parsing logic
-		// We call VerifyChecksum primarily to determine the algorithm and get the expected sum.
-		// The actual hashing and comparison will be done once outside this if/else.
-
-		// Temporarily, let's just determine the algorithm first
-		var determinedAlgoFromExtOrGeneric string
-		algoFromExt, found := utils.GetAlgorithmFromFilename(checksumAssetPath)
-		if found {
-			determinedAlgoFromExtOrGeneric = algoFromExt
-			utils.Logger.Debugf("Using algorithm '%s' derived from checksum file extension: %s", determinedAlgoFromExtOrGeneric, checksumAssetPath)
-		} else {
-			determinedAlgoFromExtOrGeneric = utils.DefaultAlgorithmForGenericChecksums
-			utils.Logger.Debugf("Checksum file '%s' has no algorithm extension. Using default/hint: '%s'", checksumAssetPath, determinedAlgoFromExtOrGeneric)
-		}
-
-		// Ensure determined algo is supported
-		if _, err := utils.GetHasher(determinedAlgoFromExtOrGeneric); err != nil {
-			return fmt.Errorf("algorithm '%s' (derived or default) is not supported: %w", determinedAlgoFromExtOrGeneric, err)
-		}
-		algoToUse = determinedAlgoFromExtOrGeneric
-
-		expectedChecksum, err = utils.ParseChecksumFile(checksumAssetPath, mainAssetOriginalName)
-		if err != nil {
-			return fmt.Errorf("failed to parse checksum file '%s' for target '%s' (algorithm hint: %s): %w",
-				checksumAssetPath, mainAssetOriginalName, algoToUse, err)
-		}
+// validateAssetFlags checks the --asset and --asset-regex values, which are mutually
+// exclusive and select a single asset, so they can't be combined with --config.
+// Returns: The compiled --asset-regex, nil when it's not set.
+func validateAssetFlags(asset, assetRegex string) (*regexp.Regexp, error) {
+	if asset == "" && assetRegex == "" {
+		return nil, nil
 	}
-
-	utils.Logger.Debugf(
-		"Calculating %s checksum for local asset: %s",
-		strings.ToUpper(algoToUse),
-		mainAssetDiskPath,
-	)
-	actualChecksum, err := utils.HashFile(mainAssetDiskPath, algoToUse)
+	switch {
+	case asset != "" && assetRegex != "":
+		return nil, errors.New("--asset and --asset-regex can't be used together")
+	case configFlag != "":
+		return nil, errors.New("--asset and --asset-regex can't be used with --config")
+	case asset != "":
+		return nil, nil
+	}
+	re, err := regexp.Compile(assetRegex)
 	if err != nil {
-		return fmt.Errorf("failed to calculate actual checksum for asset '%s' using %s: %w",
-			mainAssetDiskPath, algoToUse, err)
+		return nil, fmt.Errorf("invalid --asset-regex '%s': %w", assetRegex, err)
 	}
+	return re, nil
+}
 
-	if !strings.EqualFold(expectedChecksum, actualChecksum) {
-		return fmt.Errorf(
-			"checksum mismatch for asset '%s' (original name '%s') using algorithm '%s': expected '%s', got '%s'",
-			mainAssetDiskPath,
-			mainAssetOriginalName,
-			algoToUse,
-			expectedChecksum,
-			actualChecksum,
+// validateExtractFlags checks that --extract and --all-binaries aren't combined with
+// each other or with flags that assume a single binary.
+func validateExtractFlags(extract []string, allBinaries bool) error {
+	if len(extract) == 0 && !allBinaries {
+		return nil
+	}
+	switch {
+	case len(extract) > 0 && allBinaries:
+		return errors.New("--extract and --all-binaries can't be used together")
+	case binNameFlag != "" || symlinkFlag || sourceFlag || configFlag != "":
+		return errors.New(
+			"--extract and --all-binaries can't be used with " +
+				"--binName, --symlink, --source or --config",
 		)
 	}
-
-	utils.Logger.Debugf(
-		green("✔")+" Checksum VALID for '%s' (original name: '%s') using algorithm %s.",
-		mainAssetDiskPath,
-		mainAssetOriginalName,
-		algoToUse,
-	)
-	utils.Logger.Print(green("✔") + " Checksum verified!")
 	return nil
 }
+
+// validateArgs requires exactly one owner/repo argument, or none when --config is used.
+func validateArgs(cmd *cobra.Command, args []string) error {
+	if configFlag != "" {
+		return cobra.NoArgs(cmd, args)
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
@@ -3,170 +3,273 @@ package cmd
 
 import (
 	"bytes"
-	"errors"
-	"io"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/charmbracelet/log"
+	"github.com/fatih/color"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/utils"
 )
 
-func Test_saveAssetToFile(t *testing.T) {
-	// Create a temporary directory for test files
-	tempDir, err := os.MkdirTemp("", "asset-test")
+func Test_configureColor(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+	t.Setenv(noColorEnv, "1")
+
+	configureColor()
+	for _, s := range []string{install.Green("✔"), install.Red("✘"), install.Yellow("!")} {
+		if strings.Contains(s, "\x1b[") {
+			t.Errorf("colored output %q contains ANSI escape codes with %s set", s, noColorEnv)
+		}
+	}
+}
+
+func Test_verboseFlag(t *testing.T) {
+	if f := rootCmd.PersistentFlags().ShorthandLookup("v"); f == nil || f.Name != "verbose" {
+		t.Fatalf("-v is not the shorthand for --verbose: %v", f)
+	}
+
+	utils.CreateLogger(false)
+	verboseFlag = true
+	defer func() {
+		verboseFlag = false
+		utils.CreateLogger(false)
+	}()
+
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err != nil {
+		t.Fatalf("PersistentPreRunE() error = %v", err)
+	}
+	if got := utils.Logger.GetLevel(); got != log.DebugLevel {
+		t.Errorf("log level after --verbose = %s, want %s", got, log.DebugLevel)
+	}
+}
+
+func Test_logFileFlags(t *testing.T) {
+	utils.CreateLogger(false)
+	defer func() {
+		logFileFlag, logFileOnlyFlag = "", false
+		utils.CreateLogger(false)
+		utils.Logger.SetOutput(os.Stderr)
+	}()
+
+	logFileOnlyFlag = true
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err == nil {
+		t.Error("PersistentPreRunE() accepted --log-file-only without --log-file")
+	}
+
+	logFileFlag = filepath.Join(t.TempDir(), "gh-install.log")
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err != nil {
+		t.Fatalf("PersistentPreRunE() error = %v", err)
+	}
+	utils.Logger.Info("logged to file")
+	got, err := os.ReadFile(logFileFlag)
 	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(got), "logged to file") {
+		t.Errorf("log file = %q, want the message", got)
 	}
-	defer os.RemoveAll(tempDir)
+}
 
-	// Test data
-	testData := []byte("This is test data for the download simulation")
-	testSize := int64(len(testData))
+func Test_defaultAlgoFlag(t *testing.T) {
+	defer func() { defaultAlgoFlag = "" }()
 
-	type args struct {
-		rc          io.ReadCloser
-		localPath   string
-		displayName string
-		assetSize   int64
+	defaultAlgoFlag = "xxh3"
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err == nil {
+		t.Error("PersistentPreRunE() accepted an unsupported --default-algo")
+	}
+	defaultAlgoFlag = "sha512"
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err != nil {
+		t.Errorf("PersistentPreRunE() error = %v with --default-algo sha512", err)
 	}
+}
+
+func Test_osFamilyFlag(t *testing.T) {
+	defer func() { osFamilyFlag = "" }()
+
+	osFamilyFlag = "arch"
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err == nil {
+		t.Error("PersistentPreRunE() accepted an unsupported --os-family")
+	}
+	osFamilyFlag = "rhel"
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err != nil {
+		t.Errorf("PersistentPreRunE() error = %v with --os-family rhel", err)
+	}
+	if got := baseInstallOptions().OSFamily; got != "rhel" {
+		t.Errorf("baseInstallOptions().OSFamily = %q, want rhel", got)
+	}
+}
+
+func Test_printPathFlag(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	binary := []byte("#!/bin/sh\necho tool\n")
+	assetName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[
+		{"id":1,"name":%q,"size":%d,"content_type":"application/octet-stream"}
+	]}`, assetName, len(binary))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/repos/owner/tool/releases/latest"):
+			fmt.Fprint(w, release) //nolint:errcheck
+		case strings.HasSuffix(r.URL.Path, "/releases/assets/1"):
+			_, _ = w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	apiURLFlag = server.URL + "/"
+	t.Setenv("GH_INSTALL_TOKEN", "test-token")
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetContext(context.Background())
+	defer func() {
+		apiURLFlag = ""
+		rootCmd.SetOut(nil)
+		printPathFlag, quietFlag, noCacheFlag, pathFlag = false, false, false, ""
+		utils.CreateLogger(false)
+	}()
+
+	printPathFlag, quietFlag, noCacheFlag, pathFlag = true, true, true, t.TempDir()
+	if err := rootCmd.PersistentPreRunE(rootCmd, nil); err != nil {
+		t.Fatalf("PersistentPreRunE() error = %v", err)
+	}
+	if err := runInstall(rootCmd, []string{"owner/tool"}); err != nil {
+		t.Fatalf("runInstall() error = %v", err)
+	}
+	want := filepath.Join(pathFlag, utils.ExecutableName("tool", runtime.GOOS)) + "\n"
+	if out.String() != want {
+		t.Errorf("stdout = %q, want %q", out.String(), want)
+	}
+
+	outputFlag = outputJSON
+	defer func() { outputFlag = outputText }()
+	if err := runInstall(rootCmd, []string{"owner/tool"}); err == nil {
+		t.Error("runInstall() accepted --print-path with --output json")
+	}
+}
+
+func Test_failFastFlags(t *testing.T) {
+	flags := map[string]*bool{"fail-fast": &failFastFlag, "keep-going": &keepGoingFlag}
+	for name, flag := range flags {
+		*flag = true
+		err := runInstall(rootCmd, []string{"owner/tool"})
+		*flag = false
+		if err == nil || !strings.Contains(err.Error(), "require --config") {
+			t.Errorf("runInstall() with --%s and no --config error = %v, want an error", name, err)
+		}
+	}
+	if rootCmd.PersistentFlags().Lookup("fail-fast") != nil {
+		t.Error("--fail-fast is a persistent flag, want it on the install command only")
+	}
+}
+
+func Test_applyArchAliases(t *testing.T) {
+	asset := fmt.Sprintf("tool_%s_weird%s.tar.gz", runtime.GOOS, runtime.GOARCH)
 
 	tests := []struct {
 		name    string
-		args    args
-		setup   func()
+		aliases []string
 		wantErr bool
 	}{
-		{
-			name: "successful download",
-			args: args{
-				rc:          io.NopCloser(bytes.NewReader(testData)),
-				localPath:   filepath.Join(tempDir, "successful.txt"),
-				displayName: "test-file.txt",
-				assetSize:   testSize,
-			},
-			wantErr: false,
-		},
-		{
-			name: "read error",
-			args: args{
-				rc:          io.NopCloser(&errorReader{err: errors.New("simulated read error")}),
-				localPath:   filepath.Join(tempDir, "read-error.txt"),
-				displayName: "error-file.txt",
-				assetSize:   100, // Arbitrary size
-			},
-			wantErr: true,
-		},
-		{
-			name: "invalid path",
-			args: args{
-				rc:          io.NopCloser(bytes.NewReader(testData)),
-				localPath:   filepath.Join(tempDir, "non-existent-dir", "invalid.txt"),
-				displayName: "invalid-path.txt",
-				assetSize:   testSize,
-			},
-			wantErr: true,
-		},
-		{
-			name: "zero-size file",
-			args: args{
-				rc:          io.NopCloser(bytes.NewReader([]byte{})),
-				localPath:   filepath.Join(tempDir, "empty.txt"),
-				displayName: "empty-file.txt",
-				assetSize:   0,
-			},
-			wantErr: false,
-		},
-		{
-			name: "close error",
-			args: args{
-				rc:          &errorCloser{Reader: bytes.NewReader(testData)},
-				localPath:   filepath.Join(tempDir, "close-error.txt"),
-				displayName: "close-error.txt",
-				assetSize:   testSize,
-			},
-			wantErr: false, // The function continues even if close fails
-		},
-		{
-			name: "path exists and is directory",
-			args: args{
-				rc:          io.NopCloser(bytes.NewReader(testData)),
-				localPath:   tempDir, // Try to write to directory path
-				displayName: "dir-path.txt",
-				assetSize:   testSize,
-			},
-			setup: func() {
-				// Ensure temp dir exists
-				os.MkdirAll(tempDir, 0o755)
-			},
-			wantErr: true,
-		},
+		{name: "valid alias", aliases: []string{runtime.GOARCH + "=weird" + runtime.GOARCH}},
+		{name: "missing separator", aliases: []string{"amd64"}, wantErr: true},
+		{name: "empty alias", aliases: []string{"amd64="}, wantErr: true},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setup != nil {
-				tt.setup()
-			}
-
-			err := saveAssetToFile(
-				tt.args.rc,
-				tt.args.localPath,
-				tt.args.displayName,
-				tt.args.assetSize,
-			)
-
-			// Check if error matches expectation
+			err := applyArchAliases(tt.aliases)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("saveAssetToFile() error = %v, wantErr %v", err, tt.wantErr)
-				return
+				t.Fatalf("applyArchAliases() error = %v, wantErr %v", err, tt.wantErr)
 			}
-
-			// For successful cases, verify the file was created with correct content
-			if !tt.wantErr && err == nil {
-				// Check file exists
-				fileInfo, err := os.Stat(tt.args.localPath)
-				if err != nil {
-					t.Errorf("Expected file %s to exist, but got error: %v", tt.args.localPath, err)
-					return
-				}
-
-				// Check file size
-				if tt.args.assetSize != fileInfo.Size() && tt.name != "close error" {
-					t.Errorf("Expected file size %d, got %d", tt.args.assetSize, fileInfo.Size())
-				}
-
-				// For cases with testData, verify content
-				if tt.args.assetSize > 0 {
-					content, err := os.ReadFile(tt.args.localPath)
-					if err != nil {
-						t.Errorf("Failed to read file content: %v", err)
-						return
-					}
-
-					if !bytes.Equal(content, testData) && tt.name != "close error" {
-						t.Errorf("File content mismatch. Expected %s, got %s", testData, content)
-					}
-				}
+			matcher := utils.NewMatcher(runtime.GOOS, runtime.GOARCH)
+			if !tt.wantErr && !matcher.Match(asset) {
+				t.Errorf("Match(%q) = false after registering the alias", asset)
 			}
 		})
 	}
 }
 
-// Custom error types for testing
-
-// errorReader is a reader that always returns an error
-type errorReader struct {
-	err error
-}
+func Test_validateAssetFlags(t *testing.T) {
+	defer func() { configFlag = "" }()
 
-func (e *errorReader) Read(p []byte) (n int, err error) {
-	return 0, e.err
+	tests := []struct {
+		name      string
+		asset     string
+		regex     string
+		config    string
+		wantRegex bool
+		wantErr   bool
+	}{
+		{name: "neither"},
+		{name: "asset", asset: "tool.zip"},
+		{name: "regex", regex: `tool_.*\.zip`, wantRegex: true},
+		{name: "both", asset: "tool.zip", regex: "tool", wantErr: true},
+		{name: "invalid regex", regex: "tool(", wantErr: true},
+		{name: "with config", asset: "tool.zip", config: "tools.toml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFlag = tt.config
+			re, err := validateAssetFlags(tt.asset, tt.regex)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateAssetFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (re != nil) != tt.wantRegex {
+				t.Errorf("validateAssetFlags() regex = %v, want regex %t", re, tt.wantRegex)
+			}
+		})
+	}
 }
 
-// errorCloser is a ReadCloser that returns an error on Close
-type errorCloser struct {
-	io.Reader
-}
+func Test_ensureRateBudget(t *testing.T) {
+	t.Cleanup(func() { minRateFlag = 0 })
+	rate := func(remaining int) *github.Rate {
+		return &github.Rate{
+			Limit:     5000,
+			Remaining: remaining,
+			Reset:     github.Timestamp{Time: time.Now().Add(time.Hour)},
+		}
+	}
 
-func (e *errorCloser) Close() error {
-	return errors.New("simulated close error")
+	tests := []struct {
+		name     string
+		minRate  int
+		rate     *github.Rate
+		installs int
+		wantErr  bool
+	}{
+		{name: "not requested", rate: rate(0), installs: 30},
+		{name: "above the threshold", minRate: 50, rate: rate(100), installs: 30},
+		{name: "below the threshold but enough", minRate: 50, rate: rate(10), installs: 1},
+		{name: "below the threshold", minRate: 50, rate: rate(10), installs: 30, wantErr: true},
+		{name: "unknown rate", minRate: 50, installs: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minRateFlag = tt.minRate
+			err := ensureRateBudget(tt.rate, tt.installs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureRateBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "resets at") {
+				t.Errorf("ensureRateBudget() error = %q, want the reset time", err)
+			}
+		})
+	}
 }
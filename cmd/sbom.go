@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/state"
+)
+
+// cycloneDXSpecVersion is the version of the CycloneDX specification sbom emits.
+const cycloneDXSpecVersion = "1.5"
+
+// cycloneDXHashAlgs maps our checksum algorithms to their CycloneDX names; checksums of
+// other algorithms (sha224, blake2s, crc32) can't be expressed and are left out.
+var cycloneDXHashAlgs = map[string]string{
+	"md5":      "MD5",
+	"sha1":     "SHA-1",
+	"sha256":   "SHA-256",
+	"sha384":   "SHA-384",
+	"sha512":   "SHA-512",
+	"sha3-256": "SHA3-256",
+	"sha3-384": "SHA3-384",
+	"sha3-512": "SHA3-512",
+	"blake2b":  "BLAKE2b-512",
+}
+
+// cycloneDXBOM is the subset of a CycloneDX JSON document sbom fills in. The types are
+// written out here rather than taken from github.com/CycloneDX/cyclonedx-go, as the
+// handful of fields used don't warrant another dependency.
+type cycloneDXBOM struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+// cycloneDXMetadata tells when the document was created and by which tool.
+type cycloneDXMetadata struct {
+	Timestamp string         `json:"timestamp"`
+	Tools     cycloneDXTools `json:"tools"`
+}
+
+// cycloneDXTools lists the tools that created the document, gh-install itself.
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// cycloneDXComponent is an installed binary, or the tool in cycloneDXTools.
+type cycloneDXComponent struct {
+	Type               string              `json:"type"`
+	BOMRef             string              `json:"bom-ref,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version,omitempty"`
+	PURL               string              `json:"purl,omitempty"`
+	Hashes             []cycloneDXHash     `json:"hashes,omitempty"`
+	ExternalReferences []cycloneDXExtRef   `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+// cycloneDXHash is the checksum a binary was verified against, Alg being one of the
+// names in cycloneDXHashAlgs.
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cycloneDXExtRef links a component to where it came from: its download URL
+// ("distribution") or repository ("vcs").
+type cycloneDXExtRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// cycloneDXProperty is a name/value pair without a CycloneDX field of its own, named
+// "gh-install:<what>".
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Print a CycloneDX SBOM of the binaries installed by gh install",
+	Long: `Print a CycloneDX JSON software bill of materials of the binaries installed by
+gh install in $XDG_BIN_HOME (or --path), or of the entries of the --config manifest
+wherever they're installed. Every binary is listed with the repository and release tag
+it was installed from, the asset's download URL and its verified checksum.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := state.Load()
+		if err != nil {
+			return err
+		}
+
+		var installed []state.Record
+		if configFlag != "" {
+			cfg, err := config.LoadFromFile(configFlag)
+			if err != nil {
+				return fmt.Errorf("failed to load config '%s': %w", configFlag, err)
+			}
+			installed = installedFromConfig(records, cfg)
+		} else {
+			installed = installedInDir(records, listDir())
+		}
+		return printSBOM(cmd.OutOrStdout(), buildSBOM(installed, time.Now().UTC()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sbomCmd)
+}
+
+// installedFromConfig returns the records of the entries of cfg whose binary still
+// exists on disk.
+func installedFromConfig(records []state.Record, cfg config.Config) []state.Record {
+	installed := make([]state.Record, 0, len(cfg.Binaries))
+	for _, r := range records {
+		if _, ok := cfg.Binaries[r.Key()]; !ok {
+			continue
+		}
+		if _, err := os.Stat(r.Path); err != nil {
+			continue
+		}
+		installed = append(installed, r)
+	}
+	return installed
+}
+
+// buildSBOM describes records as a CycloneDX document, one component per installed
+// binary identified by a pkg:github package URL.
+//
+// -records: The installs to list.
+// -now: The time the document is created at.
+func buildSBOM(records []state.Record, now time.Time) cycloneDXBOM {
+	version := Version
+	if version == "" {
+		version = "dev"
+	}
+	bom := cycloneDXBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: now.Format(time.RFC3339),
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{
+					{Type: "application", Name: "gh-install", Version: version},
+				},
+			},
+		},
+		Components: make([]cycloneDXComponent, 0, len(records)),
+	}
+
+	for _, r := range records {
+		purl := githubPURL(r.Owner, r.Repo, r.Tag)
+		c := cycloneDXComponent{
+			Type:    "application",
+			BOMRef:  purl,
+			Name:    r.Repo,
+			Version: r.Tag,
+			PURL:    purl,
+			Properties: []cycloneDXProperty{
+				{Name: "gh-install:asset", Value: r.AssetName},
+				{Name: "gh-install:path", Value: r.Path},
+			},
+		}
+		if alg, ok := cycloneDXHashAlgs[strings.ToLower(r.Algorithm)]; ok && r.Checksum != "" {
+			c.Hashes = []cycloneDXHash{{Alg: alg, Content: strings.ToLower(r.Checksum)}}
+		}
+		if r.DownloadURL != "" {
+			c.ExternalReferences = append(
+				c.ExternalReferences,
+				cycloneDXExtRef{Type: "distribution", URL: r.DownloadURL},
+			)
+			// The repository lives on the same host the asset was downloaded from
+			if repoURL, _, ok := strings.Cut(r.DownloadURL, "/releases/download/"); ok {
+				c.ExternalReferences = append(
+					c.ExternalReferences,
+					cycloneDXExtRef{Type: "vcs", URL: repoURL},
+				)
+			}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	return bom
+}
+
+// githubPURL returns the package URL of a release, e.g. "pkg:github/owner/repo@v1.0.0".
+// The owner and repository are lower-cased, as the purl spec requires for GitHub.
+func githubPURL(owner, repo, tag string) string {
+	return fmt.Sprintf(
+		"pkg:github/%s/%s@%s",
+		strings.ToLower(owner),
+		strings.ToLower(repo),
+		url.PathEscape(tag),
+	)
+}
+
+// printSBOM writes bom to w as indented JSON.
+func printSBOM(w io.Writer, bom cycloneDXBOM) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bom)
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/state"
+)
+
+func Test_buildSBOM(t *testing.T) {
+	records := []state.Record{
+		{
+			Owner:     "Owner",
+			Repo:      "Tool",
+			Tag:       "v1.2.3",
+			AssetName: "tool_linux_amd64.tar.gz",
+			Path:      "/home/user/.local/bin/tool",
+			Checksum:  "ABC123",
+			Algorithm: "sha256",
+			DownloadURL: "https://github.com/Owner/Tool/releases/download/v1.2.3/" +
+				"tool_linux_amd64.tar.gz",
+		},
+		{
+			Owner:     "owner",
+			Repo:      "other",
+			Tag:       "release/2024+1",
+			AssetName: "other",
+			Path:      "/home/user/.local/bin/other",
+			Checksum:  "deadbeef",
+			Algorithm: "crc32", // Not expressible in CycloneDX
+		},
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := printSBOM(&out, buildSBOM(records, now)); err != nil {
+		t.Fatalf("printSBOM() error = %v", err)
+	}
+	var got cycloneDXBOM
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("printSBOM() produced invalid JSON: %v", err)
+	}
+
+	if got.BOMFormat != "CycloneDX" || got.SpecVersion != cycloneDXSpecVersion {
+		t.Errorf("document is %s %s, want CycloneDX %s",
+			got.BOMFormat, got.SpecVersion, cycloneDXSpecVersion)
+	}
+	if got.Metadata.Timestamp != "2026-01-02T03:04:05Z" {
+		t.Errorf("timestamp = %s, want 2026-01-02T03:04:05Z", got.Metadata.Timestamp)
+	}
+	if len(got.Components) != len(records) {
+		t.Fatalf("got %d components, want one per install: %+v",
+			len(got.Components), got.Components)
+	}
+
+	want := []cycloneDXComponent{
+		{
+			Type:    "application",
+			BOMRef:  "pkg:github/owner/tool@v1.2.3",
+			Name:    "Tool",
+			Version: "v1.2.3",
+			PURL:    "pkg:github/owner/tool@v1.2.3",
+			Hashes:  []cycloneDXHash{{Alg: "SHA-256", Content: "abc123"}},
+			ExternalReferences: []cycloneDXExtRef{
+				{Type: "distribution", URL: records[0].DownloadURL},
+				{Type: "vcs", URL: "https://github.com/Owner/Tool"},
+			},
+			Properties: []cycloneDXProperty{
+				{Name: "gh-install:asset", Value: "tool_linux_amd64.tar.gz"},
+				{Name: "gh-install:path", Value: "/home/user/.local/bin/tool"},
+			},
+		},
+		{
+			Type:    "application",
+			BOMRef:  "pkg:github/owner/other@release%2F2024+1",
+			Name:    "other",
+			Version: "release/2024+1",
+			PURL:    "pkg:github/owner/other@release%2F2024+1",
+			Properties: []cycloneDXProperty{
+				{Name: "gh-install:asset", Value: "other"},
+				{Name: "gh-install:path", Value: "/home/user/.local/bin/other"},
+			},
+		},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got.Components[i], want[i]) {
+			t.Errorf("component %d = %+v, want %+v", i, got.Components[i], want[i])
+		}
+	}
+}
+
+func Test_installedFromConfig(t *testing.T) {
+	binDir := t.TempDir()
+	present := filepath.Join(binDir, "tool")
+	if err := os.WriteFile(present, []byte("bin"), 0o755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	records := []state.Record{
+		{Owner: "owner", Repo: "tool", Tag: "v1.0.0", Path: present},
+		{Owner: "owner", Repo: "gone", Tag: "v1.0.0", Path: filepath.Join(binDir, "gone")},
+		{Owner: "owner", Repo: "unlisted", Tag: "v1.0.0", Path: present},
+	}
+	cfg := config.Config{Binaries: map[string]config.BinaryConfig{
+		"owner/tool": {Key: "owner/tool"},
+		"owner/gone": {Key: "owner/gone"},
+	}}
+
+	got := installedFromConfig(records, cfg)
+	want := []state.Record{records[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("installedFromConfig() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// Policies of the --select flag, deciding which release "latest" means when no version
+// is given.
+const (
+	selectGitHubLatest  = "github-latest"         // The release GitHub marks as latest
+	selectHighestSemver = "highest-semver-stable" // The highest stable semantic version
+	selectNewestByDate  = "newest-by-date"        // The most recently published stable release
+)
+
+// validateSelect checks the value of the --select flag. --prerelease already picks the
+// newest release, so it's only allowed with the default policy.
+func validateSelect(policy string, prerelease bool) error {
+	switch policy {
+	case selectGitHubLatest:
+		return nil
+	case selectHighestSemver, selectNewestByDate:
+		if prerelease {
+			return fmt.Errorf("--select %s can't be used with --prerelease", policy)
+		}
+		return nil
+	default:
+		return fmt.Errorf(
+			"invalid --select '%s': use %s, %s or %s",
+			policy,
+			selectGitHubLatest,
+			selectHighestSemver,
+			selectNewestByDate,
+		)
+	}
+}
+
+// selectRelease picks the release policy selects among releases. Drafts and
+// pre-releases are ignored, and so are tags that aren't semantic versions with
+// highest-semver-stable.
+//
+// -releases: The releases of a repository, in any order.
+// -policy: highest-semver-stable or newest-by-date.
+// Returns: The selected release, or nil if none qualifies.
+func selectRelease(
+	releases []*github.RepositoryRelease,
+	policy string,
+) *github.RepositoryRelease {
+	var best *github.RepositoryRelease
+	var bestVersion utils.Semver
+	for _, r := range releases {
+		if r.GetDraft() || r.GetPrerelease() {
+			continue
+		}
+		switch policy {
+		case selectHighestSemver:
+			v, ok := utils.ParseSemver(r.GetTagName())
+			if !ok || v.Prerelease != "" {
+				continue
+			}
+			if best == nil || v.Compare(bestVersion) > 0 {
+				best, bestVersion = r, v
+			}
+		case selectNewestByDate:
+			if best == nil || install.ReleaseTime(r).After(install.ReleaseTime(best)) {
+				best = r
+			}
+		}
+	}
+	return best
+}
+
+// resolveSelectedTag returns the tag of the release of owner/repo the --select policy
+// picks, listing its releases. The github-latest policy asks GitHub for its latest
+// release instead.
+func resolveSelectedTag(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, policy string,
+) (string, error) {
+	if policy == selectGitHubLatest {
+		release, err := install.GetLatestRelease(ctx, client, owner, repo)
+		if err != nil {
+			return "", err
+		}
+		return release.GetTagName(), nil
+	}
+
+	releases, err := install.ListReleases(ctx, client, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	release := selectRelease(releases, policy)
+	if release == nil {
+		return "", fmt.Errorf(
+			"no release of %s/%s qualifies for --select %s (checked %d releases)",
+			owner,
+			repo,
+			policy,
+			len(releases),
+		)
+	}
+	utils.Logger.Infof("Selected %s of %s/%s (%s)", release.GetTagName(), owner, repo, policy)
+	return release.GetTagName(), nil
+}
+
+// selectedVersion returns the version to install for pa: the tag the --select policy
+// picks when pa asks for the latest release, and pa.Version otherwise. The
+// github-latest policy leaves resolving the latest release to install.
+func selectedVersion(
+	ctx context.Context,
+	client *github.Client,
+	pa utils.ParsedArgs,
+) (string, error) {
+	if selectFlag == selectGitHubLatest || (pa.Version != "" && pa.Version != "latest") {
+		return pa.Version, nil
+	}
+	apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+	defer cancel()
+	return resolveSelectedTag(apiCtx, client, pa.Owner, pa.Repo, selectFlag)
+}
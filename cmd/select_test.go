@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// selectTestReleases is a release list whose highest semantic version, newest stable
+// release and the release GitHub marks as latest all differ.
+const selectTestReleases = `[
+	{"tag_name":"v3.0.0-rc.1","prerelease":true,"published_at":"2024-06-01T00:00:00Z"},
+	{"tag_name":"v2.2.0","draft":true,"created_at":"2024-05-20T00:00:00Z"},
+	{"tag_name":"v1.9.1","published_at":"2024-05-01T00:00:00Z"},
+	{"tag_name":"v2.3.0-beta.1","published_at":"2024-04-01T00:00:00Z"},
+	{"tag_name":"v2.1.0","published_at":"2024-03-01T00:00:00Z"},
+	{"tag_name":"nightly","published_at":"2024-02-01T00:00:00Z"},
+	{"tag_name":"v1.9.0","published_at":"2024-01-01T00:00:00Z"}
+]`
+
+func Test_resolveSelectedTag(t *testing.T) {
+	utils.CreateLogger(false)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/tool/releases/latest":
+				fmt.Fprint(w, `{"tag_name":"v1.9.0"}`) //nolint:errcheck
+			case "/repos/owner/tool/releases":
+				fmt.Fprint(w, selectTestReleases) //nolint:errcheck
+			case "/repos/owner/empty/releases":
+				fmt.Fprint(w, `[{"tag_name":"v1.0.0-rc.1","prerelease":true}]`) //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+
+	tests := []struct {
+		name    string
+		repo    string
+		policy  string
+		want    string
+		wantErr string
+	}{
+		{name: "github latest", repo: "tool", policy: selectGitHubLatest, want: "v1.9.0"},
+		{name: "highest semver", repo: "tool", policy: selectHighestSemver, want: "v2.1.0"},
+		{name: "newest by date", repo: "tool", policy: selectNewestByDate, want: "v1.9.1"},
+		{
+			name:    "only pre-releases",
+			repo:    "empty",
+			policy:  selectHighestSemver,
+			wantErr: "no release of owner/empty qualifies",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSelectedTag(
+				context.Background(), client, "owner", tt.repo, tt.policy,
+			)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveSelectedTag() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSelectedTag() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSelectedTag() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateSelect(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		prerelease bool
+		wantErr    bool
+	}{
+		{name: "github latest", policy: selectGitHubLatest},
+		{name: "github latest with prerelease", policy: selectGitHubLatest, prerelease: true},
+		{name: "highest semver", policy: selectHighestSemver},
+		{name: "newest by date", policy: selectNewestByDate},
+		{name: "with prerelease", policy: selectNewestByDate, prerelease: true, wantErr: true},
+		{name: "unknown", policy: "oldest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSelect(tt.policy, tt.prerelease); (err != nil) != tt.wantErr {
+				t.Errorf("validateSelect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// latestTagTTL is how long upgrade reuses a resolved latest release tag, so repeated
+// runs, e.g. with --dry-run, don't query every repository again.
+const latestTagTTL = 5 * time.Minute
+
+var upgradeRefreshFlag bool // upgradeRefreshFlag is the value from the upgrade --refresh flag
+
+// upgradeSummary counts the outcomes of an upgrade run.
+type upgradeSummary struct {
+	UpToDate int // Records already at the latest release
+	Upgraded int // Records upgraded (or that would be, with --dry-run)
+	Failed   int // Records that could not be checked or upgraded
+}
+
+// String formats the summary, e.g. "3 up to date, 1 upgraded".
+func (s upgradeSummary) String() string {
+	verb := "upgraded"
+	if dryRunFlag {
+		verb = "would be upgraded"
+	}
+	result := fmt.Sprintf("%d up to date, %d %s", s.UpToDate, s.Upgraded, verb)
+	if s.Failed > 0 {
+		result = fmt.Sprintf("%s, %d failed", result, s.Failed)
+	}
+	return result
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [owner/repo]",
+	Short: "Upgrade installed binaries to their latest release",
+	Long: `Upgrade binaries installed by gh install to their latest release.
+Without an argument every recorded install is checked; with owner/repo only that one is.
+A binary is re-installed, at the same path, only when its latest release tag differs
+from the installed one. Latest release tags are reused for a few minutes; pass
+--refresh to look them up again.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeInstalledRepos,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := state.Load()
+		if err != nil {
+			return err
+		}
+
+		var only string
+		if len(args) == 1 {
+			only = args[0]
+		}
+		records, err = selectRecords(records, only)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			utils.Logger.Info("No binaries installed by gh install.")
+			return nil
+		}
+
+		ctx := cmd.Context()
+		client, err := newClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize GitHub client: %v", err)
+		}
+
+		var summary upgradeSummary
+		for _, r := range records {
+			upgradeRecord(ctx, client, r, &summary)
+		}
+
+		utils.Logger.Info(summary.String())
+		if summary.Failed > 0 {
+			return fmt.Errorf("%d of %d binaries failed to upgrade", summary.Failed, len(records))
+		}
+		return nil
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(
+		&upgradeRefreshFlag,
+		"refresh",
+		false,
+		"look up every latest release again instead of reusing recently resolved tags",
+	)
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+// selectRecords returns all records when only is empty, otherwise the single record
+// matching the owner/repo in only.
+func selectRecords(records []state.Record, only string) ([]state.Record, error) {
+	if only == "" {
+		return records, nil
+	}
+
+	pa, err := utils.ParseArgs(only)
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument: %w", err)
+	}
+	for _, r := range records {
+		if r.Owner == pa.Owner && r.Repo == pa.Repo {
+			return []state.Record{r}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s/%s was not installed by gh install", pa.Owner, pa.Repo)
+}
+
+// upgradeRecord checks a single recorded install against its latest release and
+// re-installs it in place when the tags differ, updating summary accordingly.
+func upgradeRecord(
+	ctx context.Context,
+	client *github.Client,
+	r state.Record,
+	summary *upgradeSummary,
+) {
+	latestTag, err := resolveLatestTag(ctx, client, r.Owner, r.Repo)
+	if err != nil {
+		utils.Logger.Errorf("Failed to check %s: %v", r.Key(), err)
+		summary.Failed++
+		return
+	}
+
+	if latestTag == r.Tag {
+		utils.Logger.Infof("%s %s is up to date (%s)", install.Green("✔"), r.Key(), r.Tag)
+		summary.UpToDate++
+		return
+	}
+
+	if dryRunFlag {
+		utils.Logger.Infof("%s would be upgraded: %s -> %s", r.Key(), r.Tag, latestTag)
+		summary.Upgraded++
+		return
+	}
+
+	utils.Logger.Infof("Upgrading %s: %s -> %s", r.Key(), r.Tag, latestTag)
+	opts := baseInstallOptions()
+	opts.Owner, opts.Repo, opts.Version = r.Owner, r.Repo, latestTag
+	opts.Client = client
+	opts.BinName = filepath.Base(r.Path)
+	opts.Path = filepath.Dir(r.Path)
+	opts.Force = true // Replacing the installed binary is the point of upgrading
+	if r.Link != "" {
+		// Install the new version next to the old one and repoint the link
+		opts.BinName = filepath.Base(r.Link)
+		opts.Path = filepath.Dir(r.Link)
+		opts.Symlink = true
+	}
+	if _, err := install.Release(ctx, opts); err != nil {
+		utils.Logger.Errorf("Failed to upgrade %s: %v", r.Key(), err)
+		summary.Failed++
+		return
+	}
+	summary.Upgraded++
+}
+
+// resolveLatestTag returns the latest release tag of owner/repo, as chosen by --select.
+// A tag resolved less than latestTagTTL ago is reused unless --refresh is given.
+func resolveLatestTag(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+) (string, error) {
+	// Include the host, as GitHub Enterprise Server may serve the same owner/repo
+	key := client.BaseURL.Host + "/" + owner + "/" + repo
+	if selectFlag != selectGitHubLatest {
+		key += "#" + selectFlag // Other policies may pick another release
+	}
+	if !upgradeRefreshFlag {
+		if tag, ok := state.CachedLatest(key, latestTagTTL); ok {
+			utils.Logger.Debugf("Reusing latest release of %s/%s: %s", owner, repo, tag)
+			return tag, nil
+		}
+	}
+
+	apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+	tag, err := resolveSelectedTag(apiCtx, client, owner, repo, selectFlag)
+	cancel()
+	if err != nil {
+		return "", err
+	}
+	if err := state.SaveLatest(key, tag, latestTagTTL); err != nil {
+		utils.Logger.Debugf("Failed to remember latest release of %s/%s: %v", owner, repo, err)
+	}
+	return tag, nil
+}
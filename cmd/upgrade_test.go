@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/adrg/xdg"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/state"
+)
+
+func Test_selectRecords(t *testing.T) {
+	records := []state.Record{
+		{Owner: "owner", Repo: "a", Tag: "v1.0.0", Path: "/bin/a"},
+		{Owner: "owner", Repo: "b", Tag: "v2.0.0", Path: "/bin/b"},
+	}
+
+	tests := []struct {
+		name    string
+		only    string
+		want    []state.Record
+		wantErr bool
+	}{
+		{name: "all records", only: "", want: records},
+		{name: "single record", only: "owner/b", want: []state.Record{records[1]}},
+		{name: "version is ignored", only: "owner/a@v9.9.9", want: []state.Record{records[0]}},
+		{name: "not installed", only: "owner/c", wantErr: true},
+		{name: "invalid argument", only: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectRecords(records, tt.only)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectRecords() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_upgradeSummary_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary upgradeSummary
+		dryRun  bool
+		want    string
+	}{
+		{
+			name:    "upgraded",
+			summary: upgradeSummary{UpToDate: 3, Upgraded: 1},
+			want:    "3 up to date, 1 upgraded",
+		},
+		{
+			name:    "dry run",
+			summary: upgradeSummary{UpToDate: 2, Upgraded: 2},
+			dryRun:  true,
+			want:    "2 up to date, 2 would be upgraded",
+		},
+		{
+			name:    "with failures",
+			summary: upgradeSummary{UpToDate: 1, Upgraded: 0, Failed: 2},
+			want:    "1 up to date, 0 upgraded, 2 failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRunFlag = tt.dryRun
+			defer func() { dryRunFlag = false }()
+			if got := tt.summary.String(); got != tt.want {
+				t.Errorf("upgradeSummary.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveLatestTag(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	defer func() { upgradeRefreshFlag = false }()
+
+	var calls atomic.Int32
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/repos/owner/tool/releases/latest" {
+				http.NotFound(w, r)
+				return
+			}
+			n := calls.Add(1)
+			fmt.Fprintf(w, `{"tag_name":"v1.0.%d"}`, n) //nolint:errcheck
+		}),
+	)
+
+	tests := []struct {
+		name      string
+		refresh   bool
+		want      string
+		wantCalls int32
+	}{
+		{name: "first lookup", want: "v1.0.1", wantCalls: 1},
+		{name: "reused", want: "v1.0.1", wantCalls: 1},
+		{name: "refresh", refresh: true, want: "v1.0.2", wantCalls: 2},
+		{name: "reuses refreshed tag", want: "v1.0.2", wantCalls: 2},
+	}
+	for _, tt := range tests {
+		upgradeRefreshFlag = tt.refresh
+		got, err := resolveLatestTag(context.Background(), client, "owner", "tool")
+		if err != nil {
+			t.Fatalf("%s: resolveLatestTag() error = %v", tt.name, err)
+		}
+		if got != tt.want || calls.Load() != tt.wantCalls {
+			t.Errorf("%s: resolveLatestTag() = %q after %d API calls, want %q after %d",
+				tt.name, got, calls.Load(), tt.want, tt.wantCalls)
+		}
+	}
+}
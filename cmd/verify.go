@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v80/github"
+	"github.com/spf13/cobra"
+
+	"github.com/esacteksab/gh-install/ghclient"
+	"github.com/esacteksab/gh-install/install"
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+var verifyFileFlag string // verifyFileFlag is the value from the verify --file flag
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify owner/repo[@version]",
+	Short: "Verify an installed binary against its release's checksum file",
+	Long: `Verify a local file against the checksum file of a release without downloading
+the release asset again. The file defaults to the binary recorded for owner/repo and
+the version to its installed tag. Exits non-zero if the checksum doesn't match.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeInstalledRepos,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pa, err := utils.ParseArgs(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid argument: %w", err)
+		}
+		record, found, err := state.Find(pa.Owner, pa.Repo)
+		if err != nil {
+			return err
+		}
+
+		filePath := verifyFileFlag
+		if filePath == "" {
+			if !found {
+				return fmt.Errorf(
+					"%s/%s was not installed by gh install; pass --file",
+					pa.Owner,
+					pa.Repo,
+				)
+			}
+			filePath = record.Path
+		}
+		if found && !strings.Contains(args[0], "@") {
+			pa.Version = record.Tag // Verify the installed release, not the latest one
+		}
+		var assetName string
+		if found && (filePath == record.Path || filePath == record.Link) {
+			assetName = record.AssetName
+		}
+
+		ctx := cmd.Context()
+		client, err := newClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize GitHub client: %v", err)
+		}
+		algorithm, _, err := verifyInstalledFile(
+			ctx,
+			client,
+			ghclient.NewDownloadClient(),
+			pa,
+			filePath,
+			assetName,
+		)
+		if err != nil {
+			utils.Logger.Error(install.Red("✘") + " " + filePath + " is INVALID")
+			return err
+		}
+		utils.Logger.Infof("%s %s is valid (%s)", install.Green("✔"), filePath, algorithm)
+		return nil
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(
+		&verifyFileFlag,
+		"file",
+		"",
+		"local file to verify. Default: the installed binary of owner/repo",
+	)
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// verifyInstalledFile downloads only the checksum file of the release described by pa
+// and verifies filePath against the entry for assetName, or, when assetName is empty,
+// for the asset an install would select. As an archive's checksum can't match the
+// binary extracted from it, files installed from archives are looked up by their own
+// name, which works for releases that checksum their binaries.
+// Returns: The algorithm and checksum that were verified.
+func verifyInstalledFile(
+	ctx context.Context,
+	client *github.Client,
+	httpClient *http.Client,
+	pa utils.ParsedArgs,
+	filePath, assetName string,
+) (algorithm, checksum string, err error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return "", "", fmt.Errorf("cannot verify '%s': %w", filePath, err)
+	}
+
+	apiCtx, cancel := install.WithAPITimeout(ctx, timeoutFlag)
+	release, err := install.ResolveRelease(apiCtx, client, pa, prereleaseFlag, draftsFlag)
+	cancel()
+	if err != nil {
+		return "", "", err
+	}
+	opts := baseInstallOptions()
+	matcher := opts.Matcher()
+	plan, err := install.PlanInstall(release.Assets, release.GetTagName(), opts, matcher)
+	if err != nil {
+		return "", "", err
+	}
+	if plan.Checksum == nil {
+		return "", "", fmt.Errorf("release '%s' has no checksum file", release.GetTagName())
+	}
+	if assetName == "" {
+		assetName = plan.Main.GetName()
+	}
+
+	checksumDir, err := os.MkdirTemp("", "gh-install-checksum-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(checksumDir) //nolint:errcheck
+	checksumPath, err := install.DownloadAndSaveAsset(
+		ctx,
+		client,
+		pa.Owner,
+		pa.Repo,
+		plan.Checksum,
+		httpClient,
+		filepath.Join(checksumDir, filepath.Base(plan.Checksum.GetName())),
+		showProgress,
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download checksum file: %w", err)
+	}
+
+	lookupName := assetName
+	var alternatives []string
+	if utils.IsArchive(assetName) {
+		lookupName = filepath.Base(filePath)
+		alternatives = append(alternatives, install.ArchiveStem(assetName))
+	}
+	algorithm, checksum, err = install.VerifyAssetChecksum(
+		ctx, filePath, lookupName, checksumPath, shaFlag, defaultAlgoFlag, allowWeakFlag,
+		install.AssetDigest{}, matcher, alternatives...,
+	)
+	if errors.Is(err, utils.ErrChecksumNotFound) && lookupName != assetName {
+		return "", "", fmt.Errorf(
+			"'%s' was extracted from '%s', and '%s' only lists the archive: %w",
+			filePath,
+			assetName,
+			plan.Checksum.GetName(),
+			err,
+		)
+	}
+	return algorithm, checksum, err
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_verifyInstalledFile(t *testing.T) {
+	utils.GetOSArch()
+	binary := []byte("#!/bin/sh\necho tool\n")
+	filePath := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(filePath, binary, 0o600); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	sum, err := utils.HashFile(filePath, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash binary: %v", err)
+	}
+	rawName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	archiveName := rawName + ".tar.gz"
+
+	tests := []struct {
+		name      string
+		assetName string
+		checksums string // Empty when the release has no checksum file
+		wantErr   bool
+	}{
+		{name: "raw binary valid", checksums: fmt.Sprintf("%s  %s\n", sum, rawName)},
+		{
+			name:      "raw binary invalid",
+			checksums: fmt.Sprintf("%064d  %s\n", 0, rawName),
+			wantErr:   true,
+		},
+		{name: "no checksum file", wantErr: true},
+		{
+			name:      "extracted binary listed",
+			assetName: archiveName,
+			checksums: fmt.Sprintf("%064d  %s\n%s  tool\n", 0, archiveName, sum),
+		},
+		{
+			name:      "only archive listed",
+			assetName: archiveName,
+			checksums: fmt.Sprintf("%s  %s\n", sum, archiveName),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := fmt.Sprintf(
+				`{"name":%q,"id":1,"size":%d,"content_type":"application/octet-stream"}`,
+				rawName,
+				len(binary),
+			)
+			if tt.checksums != "" {
+				assets += fmt.Sprintf(
+					`,{"name":"checksums.txt","id":2,"size":%d}`,
+					len(tt.checksums),
+				)
+			}
+			client := testutil.NewGitHubClient(
+				t,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case strings.HasSuffix(r.URL.Path, "/releases/tags/v1.0.0"):
+						fmt.Fprintf(w, `{"tag_name":"v1.0.0","assets":[%s]}`, assets) //nolint:errcheck
+					case strings.HasSuffix(r.URL.Path, "/assets/1"):
+						t.Error("verifyInstalledFile() downloaded the release asset")
+					case strings.HasSuffix(r.URL.Path, "/assets/2"):
+						fmt.Fprint(w, tt.checksums) //nolint:errcheck
+					default:
+						http.NotFound(w, r)
+					}
+				}),
+			)
+
+			pa := utils.ParsedArgs{Owner: "owner", Repo: "tool", Version: "v1.0.0"}
+			algorithm, checksum, err := verifyInstalledFile(
+				context.Background(), client, http.DefaultClient, pa, filePath, tt.assetName,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyInstalledFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (algorithm != "sha256" || checksum != sum) {
+				t.Errorf("verifyInstalledFile() = %s %s, want sha256 %s", algorithm, checksum, sum)
+			}
+		})
+	}
+}
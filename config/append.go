@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	kt "github.com/knadh/koanf/parsers/toml/v2"
+)
+
+// Append adds bc as a new table at the end of the manifest at path, leaving the
+// existing entries and comments untouched, or writes a starter manifest holding just
+// bc when path doesn't exist. The table is encoded with the TOML parser LoadFromFile
+// uses, and the result is validated the way LoadFromFile validates it before the file is
+// replaced, so add never writes a manifest that --config then rejects.
+// Returns: An error if the manifest already lists bc.Key, wouldn't load afterwards or
+// can't be read or written.
+func Append(path string, bc BinaryConfig) error {
+	existing, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return writeValidated(path, bc.Key, Starter(path, []BinaryConfig{bc}))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+
+	parser := kt.Parser()
+	current, err := parser.Unmarshal(existing)
+	if err != nil {
+		return fmt.Errorf("failed to parse '%s': %w", path, err)
+	}
+	if _, ok := current[bc.Key]; ok {
+		return fmt.Errorf("'%s' is already listed in '%s'", bc.Key, path)
+	}
+
+	table := map[string]any{}
+	if bc.Name != "" {
+		table["name"] = bc.Name
+	}
+	if bc.Version != "" {
+		table["version"] = bc.Version
+	}
+	encoded, err := parser.Marshal(map[string]any{bc.Key: table})
+	if err != nil {
+		return fmt.Errorf("failed to encode '%s': %w", bc.Key, err)
+	}
+
+	var b bytes.Buffer
+	b.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.Write(encoded)
+	return writeValidated(path, bc.Key, b.Bytes())
+}
+
+// writeValidated checks that data, the manifest at path with key added, loads like
+// LoadFromFile would load it, then writes it with writeFileAtomic.
+func writeValidated(path, key string, data []byte) error {
+	raw, err := kt.Parser().Unmarshal(data)
+	if err == nil {
+		_, err = parseBinaries(path, raw)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add '%s' to '%s': %w", key, path, err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into
+// place, so an interrupted write never leaves a truncated manifest behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for '%s': %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("failed to write '%s': %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { //nolint:mnd,gosec
+		return fmt.Errorf("failed to set permissions on '%s': %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	existing := `# My tools
+['esacteksab/go-pretty-toml']
+name = 'toml-fmt'
+version = 'v0.1.1'`
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	added := BinaryConfig{Key: "esacteksab/gh-actlock", Name: "gh-actlock", Version: "v0.4.0"}
+	if err := Append(path, added); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := Append(path, BinaryConfig{Key: "owner/pinned", Version: "^1.0.0"}); err != nil {
+		t.Fatalf("Append() without a name error = %v", err)
+	}
+	if err := Append(path, BinaryConfig{Key: added.Key, Version: "v0.5.0"}); err == nil {
+		t.Error("Append() of an existing entry succeeded, want an error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if !strings.HasPrefix(string(data), existing+"\n") {
+		t.Errorf("Append() changed the existing content:\n%s", data)
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if len(cfg.Binaries) != 3 {
+		t.Errorf("LoadFromFile() found %d binaries, want 3: %v", len(cfg.Binaries), cfg.Binaries)
+	}
+	if got := cfg.Binaries[added.Key]; got != added {
+		t.Errorf("appended entry = %v, want %v", got, added)
+	}
+}
+
+func TestAppend_newFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	bc := BinaryConfig{Key: "owner/tool", Version: "v1.0.0"}
+	if err := Append(path, bc); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if got := cfg.Binaries[bc.Key]; got != bc || len(cfg.Binaries) != 1 {
+		t.Errorf("LoadFromFile() = %v, want only %v", cfg.Binaries, bc)
+	}
+}
+
+func TestAppend_dottedRepo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	if err := os.WriteFile(path, []byte("['owner/tool']\nversion = 'v1.0.0'\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	bc := BinaryConfig{Key: "vercel/next.js", Version: "v15.0.0"}
+	if err := Append(path, bc); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if got := cfg.Binaries[bc.Key]; got != bc || len(cfg.Binaries) != 2 {
+		t.Errorf("LoadFromFile() = %v, want %v among 2 entries", cfg.Binaries, bc)
+	}
+}
+
+func TestAppend_invalidManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	existing := "['owner/broken']\nname = 'broken'\n"
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	err := Append(path, BinaryConfig{Key: "owner/tool", Version: "v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "'owner/broken': missing version") {
+		t.Fatalf("Append() error = %v, want the manifest's invalid entry", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if string(data) != existing {
+		t.Errorf("Append() rewrote a manifest it rejected:\n%s", data)
+	}
+}
@@ -3,39 +3,172 @@
 package config
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	kt "github.com/knadh/koanf/parsers/toml/v2"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
+
+	"github.com/esacteksab/gh-install/utils"
 )
 
 type BinaryConfig struct {
 	Key     string `koanf:"key"`
 	Name    string `koanf:"name"`
 	Version string `koanf:"version"`
+	Path    string `koanf:"path"` // Target directory overriding --path; empty for the default
+	Sha     string `koanf:"sha"`  // Checksum algorithm overriding --sha
+
+	// Asset or AssetRegex select the asset to install instead of OS/Arch matching
+	Asset      string `koanf:"asset"`
+	AssetRegex string `koanf:"asset_regex"`
+
+	// PostInstall is a shell command run after installing, overriding --post-install
+	PostInstall string `koanf:"post_install"`
 }
 
 type Config struct {
 	Binaries map[string]BinaryConfig `koanf:"binaries"`
 }
 
+// LoadFromFile reads the TOML manifest at path. Every top-level key must be an
+// 'owner/repo' table with a string version (a tag, semver constraint or "latest") and
+// optionally a string name, path, sha (checksum algorithm), post_install (a command run
+// after installing) and either asset or asset_regex; no other keys are allowed. name is
+// optional like --binName: without it the binary is named after the release asset.
+// Returns: The manifest, or an error listing every offending entry.
 func LoadFromFile(path string) (Config, error) {
-	k := koanf.New(".")
+	// Keys are never split on a delimiter that can't appear in owner/repo, so
+	// repositories with a dot in their name like 'vercel/next.js' stay one table
+	k := koanf.New("\x00")
 
 	if err := k.Load(file.Provider(path), kt.Parser()); err != nil {
 		return Config{}, err
 	}
+	return parseBinaries(path, k.Raw())
+}
 
+// parseBinaries validates and converts the tables of the manifest at path, parsed into
+// raw, the way LoadFromFile does.
+// Returns: The manifest, or an error listing every offending entry.
+func parseBinaries(path string, raw map[string]any) (Config, error) {
 	config := Config{
 		Binaries: make(map[string]BinaryConfig),
 	}
 
-	for _, key := range k.MapKeys("") {
-		src := BinaryConfig{
-			Key:     key,
-			Name:    k.String(key + ".name"),
-			Version: k.String(key + ".version"),
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		src, err := parseEntry(key, raw[key])
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
 		}
 		config.Binaries[key] = src
 	}
+	if len(problems) > 0 {
+		return Config{}, fmt.Errorf(
+			"invalid entries in '%s':\n  %s",
+			path,
+			strings.Join(problems, "\n  "),
+		)
+	}
 	return config, nil
 }
+
+// LoadFromFileVerified is like LoadFromFile, but first checks that the manifest at path
+// hashes to expectedSha, so a manifest shared across a team can't be tampered with
+// unnoticed. The algorithm is guessed from the checksum's length, e.g. sha256 for 64 hex
+// digits; md5 and sha1 checksums are rejected as they don't protect against tampering.
+//
+// -path: The TOML manifest.
+// -expectedSha: The hex-encoded checksum of the manifest.
+// Returns: The manifest, or an error if the checksum is invalid or doesn't match.
+func LoadFromFileVerified(path, expectedSha string) (Config, error) {
+	algorithm, ok := utils.GuessAlgorithmFromHashLength(expectedSha)
+	if !ok || utils.IsWeakAlgorithm(algorithm) {
+		return Config{}, fmt.Errorf(
+			"invalid manifest checksum '%s': expected a hex-encoded sha224, sha256, sha384 "+
+				"or sha512 checksum",
+			expectedSha,
+		)
+	}
+	actual, err := utils.HashFile(path, algorithm)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to hash manifest '%s': %w", path, err)
+	}
+	if !strings.EqualFold(actual, expectedSha) {
+		return Config{}, fmt.Errorf(
+			"%s checksum mismatch for manifest '%s': expected %s, got %s",
+			algorithm,
+			path,
+			strings.ToLower(expectedSha),
+			actual,
+		)
+	}
+	return LoadFromFile(path)
+}
+
+// parseEntry validates and converts the manifest table value stored under key.
+func parseEntry(key string, value any) (BinaryConfig, error) {
+	if _, _, err := utils.ParseOwnerRepo(key); err != nil {
+		return BinaryConfig{}, fmt.Errorf("'%s': key must be owner/repo", key)
+	}
+	table, ok := value.(map[string]any)
+	if !ok {
+		return BinaryConfig{}, fmt.Errorf("'%s': expected a table", key)
+	}
+
+	bc := BinaryConfig{Key: key}
+	fields := map[string]*string{
+		"name":         &bc.Name,
+		"version":      &bc.Version,
+		"path":         &bc.Path,
+		"sha":          &bc.Sha,
+		"asset":        &bc.Asset,
+		"asset_regex":  &bc.AssetRegex,
+		"post_install": &bc.PostInstall,
+	}
+	for field, v := range table {
+		dst, known := fields[field]
+		s, isString := v.(string)
+		switch {
+		case !known:
+			return BinaryConfig{}, fmt.Errorf("'%s': unknown key '%s'", key, field)
+		case !isString:
+			return BinaryConfig{}, fmt.Errorf("'%s': %s must be a string", key, field)
+		}
+		*dst = s
+	}
+	bc.Sha = strings.ToLower(bc.Sha)
+	switch {
+	case bc.Version == "":
+		return BinaryConfig{}, fmt.Errorf(
+			"'%s': missing version (use a tag, a semver constraint or 'latest')",
+			key,
+		)
+	case bc.Sha != "" && !utils.IsKnownAlgorithm(bc.Sha):
+		return BinaryConfig{}, fmt.Errorf(
+			"'%s': unsupported sha '%s' (use one of %s)",
+			key,
+			bc.Sha,
+			strings.Join(utils.ListSupportedAlgorithms(), ", "),
+		)
+	case bc.Asset != "" && bc.AssetRegex != "":
+		return BinaryConfig{}, fmt.Errorf("'%s': asset and asset_regex can't be used together", key)
+	}
+	if bc.AssetRegex != "" {
+		if _, err := regexp.Compile(bc.AssetRegex); err != nil {
+			return BinaryConfig{}, fmt.Errorf("'%s': invalid asset_regex: %w", key, err)
+		}
+	}
+	return bc, nil
+}
@@ -3,8 +3,12 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/esacteksab/gh-install/utils"
 )
 
 func TestLoadFromFile(t *testing.T) {
@@ -14,11 +18,16 @@ func TestLoadFromFile(t *testing.T) {
 ['esacteksab/go-pretty-toml']
 name = 'toml-fmt'
 version = 'v0.1.1'
+path = '~/bin'
+post_install = 'toml-fmt --version'
 
 
 ['esacteksab/gh-actlock']
 name = 'gh-actlock'
 version = 'v0.4.0'
+path = '/usr/local/bin'
+sha = "sha512"
+asset_regex = 'gh-actlock_.*_linux_amd64\.tar\.gz'
 `
 	err := os.WriteFile(testConfigPath, []byte(testContent), 0o644)
 	if err != nil {
@@ -46,14 +55,19 @@ version = 'v0.4.0'
 			want: Config{
 				Binaries: map[string]BinaryConfig{
 					"esacteksab/go-pretty-toml": {
-						Key:     "esacteksab/go-pretty-toml",
-						Name:    "toml-fmt",
-						Version: "v0.1.1",
+						Key:         "esacteksab/go-pretty-toml",
+						Name:        "toml-fmt",
+						Version:     "v0.1.1",
+						Path:        "~/bin",
+						PostInstall: "toml-fmt --version",
 					},
 					"esacteksab/gh-actlock": {
-						Key:     "esacteksab/gh-actlock",
-						Name:    "gh-actlock",
-						Version: "v0.4.0",
+						Key:        "esacteksab/gh-actlock",
+						Name:       "gh-actlock",
+						Version:    "v0.4.0",
+						Path:       "/usr/local/bin",
+						Sha:        "sha512",
+						AssetRegex: `gh-actlock_.*_linux_amd64\.tar\.gz`,
 					},
 				},
 			},
@@ -82,3 +96,167 @@ version = 'v0.4.0'
 		})
 	}
 }
+
+func TestLoadFromFile_missingName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	if err := os.WriteFile(path, []byte("['owner/tool']\nversion = 'v1.0.0'\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v, want name to be optional", err)
+	}
+	want := BinaryConfig{Key: "owner/tool", Version: "v1.0.0"}
+	if got := cfg.Binaries["owner/tool"]; got != want {
+		t.Errorf("LoadFromFile() entry = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadFromFile_dottedRepo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	content := "['vercel/next.js']\nname = 'next'\nversion = 'v15.0.0'\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	want := BinaryConfig{Key: "vercel/next.js", Name: "next", Version: "v15.0.0"}
+	if got := cfg.Binaries["vercel/next.js"]; got != want || len(cfg.Binaries) != 1 {
+		t.Errorf("LoadFromFile() = %+v, want only %+v", cfg.Binaries, want)
+	}
+}
+
+func TestLoadFromFile_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // Substrings of the error, one per offending entry
+	}{
+		{
+			name:    "missing version",
+			content: "['owner/tool']\nname = 'tool'\n",
+			want:    []string{"'owner/tool': missing version"},
+		},
+		{
+			name:    "not owner/repo",
+			content: "['tool']\nversion = 'v1.0.0'\n",
+			want:    []string{"'tool': key must be owner/repo"},
+		},
+		{
+			name:    "stray scalar",
+			content: "version = 'v1.0.0'\n",
+			want:    []string{"'version': key must be owner/repo"},
+		},
+		{
+			name:    "not a table",
+			content: "'owner/tool' = 'v1.0.0'\n",
+			want:    []string{"'owner/tool': expected a table"},
+		},
+		{
+			name:    "unknown key",
+			content: "['owner/tool']\nversion = 'v1.0.0'\nnmae = 'tool'\n",
+			want:    []string{"'owner/tool': unknown key 'nmae'"},
+		},
+		{
+			name: "several entries",
+			content: "['owner/ok']\nversion = 'v1.0.0'\n\n" +
+				"['owner/a']\nname = 'a'\n\n['owner/b']\nversion = 1\n",
+			want: []string{"'owner/a': missing version", "'owner/b': version must be a string"},
+		},
+		{
+			name:    "path not a string",
+			content: "['owner/tool']\nversion = 'v1.0.0'\npath = 1\n",
+			want:    []string{"'owner/tool': path must be a string"},
+		},
+		{
+			name:    "unsupported sha",
+			content: "['owner/tool']\nversion = 'v1.0.0'\nsha = 'sha999'\n",
+			want:    []string{"'owner/tool': unsupported sha 'sha999'"},
+		},
+		{
+			name: "asset and asset_regex",
+			content: "['owner/tool']\nversion = 'v1.0.0'\n" +
+				"asset = 'tool.tar.gz'\nasset_regex = 'tool.*'\n",
+			want: []string{"'owner/tool': asset and asset_regex can't be used together"},
+		},
+		{
+			name:    "invalid asset_regex",
+			content: "['owner/tool']\nversion = 'v1.0.0'\nasset_regex = 'tool('\n",
+			want:    []string{"'owner/tool': invalid asset_regex"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tools.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			_, err := LoadFromFile(path)
+			if err == nil {
+				t.Fatal("LoadFromFile() error = nil, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadFromFile() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFromFileVerified(t *testing.T) {
+	utils.CreateLogger(false)
+	path := filepath.Join(t.TempDir(), "tools.toml")
+	content := []byte("['owner/tool']\nversion = 'v1.0.0'\n")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	sha256sum, err := utils.HashFile(path, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash config: %v", err)
+	}
+	sha512sum, err := utils.HashFile(path, "sha512")
+	if err != nil {
+		t.Fatalf("Failed to hash config: %v", err)
+	}
+	md5sum, err := utils.HashFile(path, "md5")
+	if err != nil {
+		t.Fatalf("Failed to hash config: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		sha     string
+		wantErr string // Substring of the error; none when empty
+	}{
+		{name: "sha256 matches", sha: sha256sum},
+		{name: "sha512 matches", sha: sha512sum},
+		{name: "upper case matches", sha: strings.ToUpper(sha256sum)},
+		{
+			name:    "mismatch",
+			sha:     strings.Repeat("0", 64),
+			wantErr: "sha256 checksum mismatch for manifest",
+		},
+		{name: "weak algorithm", sha: md5sum, wantErr: "invalid manifest checksum"},
+		{name: "not hex", sha: strings.Repeat("z", 64), wantErr: "invalid manifest checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadFromFileVerified(path, tt.sha)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFromFileVerified() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromFileVerified() error = %v", err)
+			}
+			if _, ok := cfg.Binaries["owner/tool"]; !ok {
+				t.Errorf("LoadFromFileVerified() = %+v, want the owner/tool entry", cfg)
+			}
+		})
+	}
+}
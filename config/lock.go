@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// lockExt replaces the manifest's extension to name its lockfile, tools.toml -> tools.lock.
+const lockExt = ".lock"
+
+// LockEntry pins a manifest entry to the release asset that was installed for it.
+type LockEntry struct {
+	Key       string `json:"key"`       // owner/repo key from the manifest
+	Tag       string `json:"tag"`       // Resolved release tag
+	Asset     string `json:"asset"`     // Name of the downloaded release asset
+	Checksum  string `json:"checksum"`  // Checksum of the downloaded release asset
+	Algorithm string `json:"algorithm"` // Algorithm used to compute Checksum
+}
+
+// lockFile is the on-disk layout of a lockfile.
+type lockFile struct {
+	Binaries []LockEntry `json:"binaries"`
+}
+
+// LockPath returns the lockfile belonging to the manifest at manifestPath, e.g.
+// tools.lock next to tools.toml.
+func LockPath(manifestPath string) string {
+	return strings.TrimSuffix(manifestPath, filepath.Ext(manifestPath)) + lockExt
+}
+
+// WriteLock replaces the lockfile at path with entries, sorted by key.
+func WriteLock(path string, entries []LockEntry) error {
+	sorted := append([]LockEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	data, err := json.MarshalIndent(lockFile{Binaries: sorted}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile '%s': %w", path, err)
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// LoadLock reads the lockfile at path.
+// Returns: The entries keyed by owner/repo, or an error if the file can't be read or
+// an entry lacks a tag, asset or checksum.
+func LoadLock(path string) (map[string]LockEntry, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile '%s': %w", path, err)
+	}
+	var lf lockFile
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile '%s': %w", path, err)
+	}
+
+	entries := make(map[string]LockEntry, len(lf.Binaries))
+	for _, e := range lf.Binaries {
+		if e.Tag == "" || e.Asset == "" || e.Checksum == "" || e.Algorithm == "" {
+			return nil, fmt.Errorf("incomplete entry for '%s' in lockfile '%s'", e.Key, path)
+		}
+		entries[e.Key] = e
+	}
+	return entries, nil
+}
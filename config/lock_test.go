@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockPath(t *testing.T) {
+	tests := []struct {
+		manifest string
+		want     string
+	}{
+		{manifest: "tools.toml", want: "tools.lock"},
+		{manifest: filepath.Join("ci", "tools.toml"), want: filepath.Join("ci", "tools.lock")},
+		{manifest: "manifest", want: "manifest.lock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.manifest, func(t *testing.T) {
+			if got := LockPath(tt.manifest); got != tt.want {
+				t.Errorf("LockPath(%q) = %q, want %q", tt.manifest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLoadLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.lock")
+	entries := []LockEntry{
+		{Key: "owner/b", Tag: "v2.0.0", Asset: "b_linux_amd64", Checksum: "bb", Algorithm: "sha256"},
+		{Key: "owner/a", Tag: "v1.0.0", Asset: "a.tar.gz", Checksum: "aa", Algorithm: "sha256"},
+	}
+	if err := WriteLock(path, entries); err != nil {
+		t.Fatalf("WriteLock() error = %v", err)
+	}
+
+	got, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("LoadLock() error = %v", err)
+	}
+	want := map[string]LockEntry{"owner/a": entries[1], "owner/b": entries[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadLock() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadLock_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "not json", content: "['owner/a']"},
+		{name: "missing checksum", content: `{"binaries":[{"key":"owner/a","tag":"v1","asset":"a"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tools.lock")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write lockfile: %v", err)
+			}
+			if _, err := LoadLock(path); err == nil {
+				t.Error("LoadLock() error = nil, want an error")
+			}
+		})
+	}
+	if _, err := LoadLock(filepath.Join(t.TempDir(), "missing.lock")); err == nil {
+		t.Error("LoadLock() of a missing file error = nil, want an error")
+	}
+}
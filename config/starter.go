@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// starterHeader documents the manifest layout read by LoadFromFile.
+const starterHeader = `# Binaries to install with: gh install --config %s
+#
+# Each table is keyed by the GitHub repository, 'owner/repo', and supports:
+#   name    - the name to save the binary as (optional, derived from the asset name)
+#   version - a release tag, a semver constraint such as '^1.2.0', or 'latest'
+#   path    - the directory to install to, overriding --path (optional)
+#   sha     - the checksum algorithm, overriding --sha (optional)
+#   asset   - the exact asset name to install, overriding OS/Arch matching (optional)
+#   asset_regex - a regular expression matching the asset to install, instead of asset
+#   post_install - a shell command run after installing, overriding --post-install
+#                  (optional); $GH_INSTALL_PATH is the binary's path, $GH_INSTALL_BIN
+#                  its name and $GH_INSTALL_VERSION the installed release tag
+#
+# ['esacteksab/go-pretty-toml']
+# name = 'toml-fmt'
+# version = 'v0.1.1'
+`
+
+// Starter renders a commented manifest for path listing binaries, in the layout
+// LoadFromFile expects. Entries without a Name leave that key out.
+func Starter(path string, binaries []BinaryConfig) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, starterHeader, path)
+	for _, bc := range binaries {
+		b.WriteString("\n")
+		b.WriteString(Table(bc))
+	}
+	return []byte(b.String())
+}
+
+// Table renders bc as a single ['owner/repo'] table.
+func Table(bc BinaryConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", tomlString(bc.Key))
+	if bc.Name != "" {
+		fmt.Fprintf(&b, "name = %s\n", tomlString(bc.Name))
+	}
+	if bc.Version != "" {
+		fmt.Fprintf(&b, "version = %s\n", tomlString(bc.Version))
+	}
+	return b.String()
+}
+
+// tomlString quotes s as a TOML literal string, or as a basic string when s contains
+// characters a literal string can't hold.
+func tomlString(s string) string {
+	if strings.ContainsAny(s, "'\n\r") {
+		return strconv.Quote(s)
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStarter(t *testing.T) {
+	tests := []struct {
+		name     string
+		binaries []BinaryConfig
+	}{
+		{name: "empty"},
+		{
+			name: "seeded",
+			binaries: []BinaryConfig{
+				{Key: "esacteksab/go-pretty-toml", Name: "toml-fmt", Version: "v0.1.1"},
+				{Key: "owner/no-name", Version: "^1.2.0"},
+				{Key: "owner/quoted", Name: "it's", Version: ">=2.0.0 <3.0.0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tools.toml")
+			if err := os.WriteFile(path, Starter(path, tt.binaries), 0o600); err != nil {
+				t.Fatalf("Failed to write starter: %v", err)
+			}
+
+			got, err := LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile() error = %v", err)
+			}
+			want := Config{Binaries: make(map[string]BinaryConfig)}
+			for _, bc := range tt.binaries {
+				want.Binaries[bc.Key] = bc
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadFromFile(Starter()) = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package ghclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v80/github"
 	"golang.org/x/oauth2"
@@ -17,6 +21,180 @@ import (
 	"github.com/esacteksab/gh-install/utils"
 )
 
+const (
+	hostEnv     = "GH_HOST"        // Environment variable naming the GitHub host, e.g. ghe.example.com
+	apiURLEnv   = "GITHUB_API_URL" // Environment variable with the full API URL, as set in GitHub Actions
+	defaultHost = "github.com"     // The public GitHub host
+
+	cacheDirEnv     = "GH_INSTALL_CACHE_DIR" // Environment variable overriding the cache directory
+	xdgCacheHomeEnv = "XDG_CACHE_HOME"       // Preferred base of the default cache directory
+	appCacheDirName = "gh-install"           // Subdirectory of the user cache directory
+
+	authenticatedLimit   = 5000 // Typical authenticated rate limit per hour
+	unauthenticatedLimit = 60   // Typical unauthenticated rate limit per hour
+)
+
+// resolveHost returns host, else the GitHub host named by GITHUB_API_URL or GH_HOST, or
+// "" for github.com.
+func resolveHost(host string) string {
+	for _, h := range []string{host, os.Getenv(apiURLEnv), os.Getenv(hostEnv)} {
+		if h = strings.TrimSpace(h); h != "" {
+			return h
+		}
+	}
+	return ""
+}
+
+// parseOverrideURL parses an APIURL or UploadURL value, which must be an absolute URL.
+//
+// - name: The flag the value came from, for the error message.
+// - raw: The URL to parse.
+// Returns: The URL with the trailing slash go-github requires, and an error if raw isn't
+// an absolute URL.
+func parseOverrideURL(name, raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s '%s': must be an absolute URL", name, raw)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
+
+// applyURLOverrides points client at opts.APIURL and opts.UploadURL when they're set.
+// Returns: An error if either of them isn't an absolute URL.
+func applyURLOverrides(client *github.Client, opts ClientOptions) error {
+	if apiURL := strings.TrimSpace(opts.APIURL); apiURL != "" {
+		u, err := parseOverrideURL("--github-api-url", apiURL)
+		if err != nil {
+			return err
+		}
+		utils.Logger.Debugf("🔧  Using GitHub API URL %s", u)
+		client.BaseURL = u
+	}
+	if uploadURL := strings.TrimSpace(opts.UploadURL); uploadURL != "" {
+		u, err := parseOverrideURL("--github-upload-url", uploadURL)
+		if err != nil {
+			return err
+		}
+		utils.Logger.Debugf("🔧  Using GitHub upload URL %s", u)
+		client.UploadURL = u
+	}
+	return nil
+}
+
+// defaultCacheDir returns $XDG_CACHE_HOME/gh-install, or gh-install under the user
+// cache directory when XDG_CACHE_HOME isn't set (it's ignored by os.UserCacheDir on
+// macOS and Windows).
+func defaultCacheDir() (string, error) {
+	if xdgCache := os.Getenv(xdgCacheHomeEnv); filepath.IsAbs(xdgCache) {
+		return filepath.Join(xdgCache, appCacheDirName), nil
+	}
+	// Get the user's cache directory (platform-specific).
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+	return filepath.Join(userCacheDir, appCacheDirName), nil
+}
+
+// checkWritable creates dir if needed and makes sure files can be written to it.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil { //nolint:mnd
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return err
+	}
+	f.Close()           //nolint:errcheck
+	os.Remove(f.Name()) //nolint:errcheck
+	return nil
+}
+
+// resolveCacheDir returns the directory to cache HTTP responses in. Responses go to the
+// gh-install subdirectory of an overridden directory (cacheDir or GH_INSTALL_CACHE_DIR),
+// which may be shared with other tools, so clean never removes files it didn't create.
+// An overridden directory that can't be written to is skipped with a warning in favor of
+// the default one.
+//
+// - cacheDir: The directory asked for, see ClientOptions.CacheDir; may be empty.
+// Returns: The cache directory and an error if the default one can't be determined.
+func resolveCacheDir(cacheDir string) (string, error) {
+	for _, dir := range []string{cacheDir, os.Getenv(cacheDirEnv)} {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		dir = filepath.Join(dir, appCacheDirName)
+		if err := checkWritable(dir); err != nil {
+			utils.Logger.Warnf(
+				"Cache directory '%s' isn't writable, using the default one: %v", dir, err,
+			)
+			break
+		}
+		return dir, nil
+	}
+	return defaultCacheDir()
+}
+
+// CachePath returns the root of gh-install's cache: the gh-install subdirectory of
+// cacheDir or GH_INSTALL_CACHE_DIR when one is set, the default cache directory otherwise.
+// Everything gh-install caches lives below it, including the per-host subdirectories
+// newCacheTransport picks for GitHub Enterprise Server, so clean removes those too.
+//
+// - cacheDir: The directory asked for, see ClientOptions.CacheDir; may be empty.
+// Returns: The cache directory and an error if it can't be determined.
+func CachePath(cacheDir string) (string, error) {
+	return resolveCacheDir(cacheDir)
+}
+
+// enterpriseURLs turns a host name or API URL into the base and upload URLs of a
+// GitHub Enterprise Server instance.
+//
+// - host: A host name ("ghe.example.com") or URL ("https://ghe.example.com/api/v3").
+// Returns: The base and upload URLs, the bare host name for keying the cache, and an
+// error if host can't be parsed. Empty URLs mean the public github.com API.
+func enterpriseURLs(host string) (baseURL, uploadURL, hostName string, err error) {
+	if host == "" {
+		return "", "", "", nil
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid GitHub host '%s'", host)
+	}
+	if u.Host == defaultHost || u.Host == "api."+defaultHost {
+		return "", "", "", nil
+	}
+
+	root := u.Scheme + "://" + u.Host + "/"
+	baseURL = root
+	if strings.Trim(u.Path, "/") != "" {
+		baseURL = u.String()
+	}
+	// go-github appends api/v3/ and api/uploads/ to a bare host root
+	return baseURL, root, u.Host, nil
+}
+
+// NewBaseTransport returns the transport all GitHub and download requests are built on:
+// a copy of http.DefaultTransport that routes requests through the proxies named by
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func NewBaseTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.Proxy = http.ProxyFromEnvironment
+	return transport
+}
+
+// NewDownloadClient returns the HTTP client used to fetch release assets from the
+// storage URLs GitHub redirects to. It doesn't carry the GitHub token, as those URLs
+// are pre-signed, but honors the same proxy settings as the API client.
+func NewDownloadClient() *http.Client {
+	return &http.Client{Transport: NewBaseTransport()}
+}
+
 // CachingTransport wraps an http.RoundTripper to potentially add custom logic,
 // such as logging or metrics, around the transport (including the cache layer).
 type CachingTransport struct {
@@ -37,81 +215,162 @@ func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	return t.Transport.RoundTrip(req)
 }
 
+// ClientOptions configures the client built by NewClientWithOptions.
+type ClientOptions struct {
+	MaxRetries int  // Retry rate-limited requests this many times; 0 disables retries
+	NoCache    bool // Send every request to GitHub instead of going through the disk cache
+
+	// Host selects the GitHub host to talk to, e.g. "ghe.example.com" or
+	// "https://ghe.example.com/api/v3". When empty, GITHUB_API_URL and then GH_HOST are
+	// consulted; when none are set (or they name github.com) the public API is used.
+	Host string
+	// APIURL overrides the REST API URL of the client, e.g. a caching proxy in front of
+	// github.com. Unlike Host it doesn't change which token is used or where responses
+	// are cached.
+	APIURL string
+	// UploadURL overrides the upload URL of the client, like APIURL does for the REST API
+	UploadURL string
+	// CacheDir overrides the directory HTTP responses are cached in; they're kept in its
+	// gh-install subdirectory. When empty, GH_INSTALL_CACHE_DIR is consulted, then
+	// $XDG_CACHE_HOME/gh-install and finally gh-install under the operating system's user
+	// cache directory.
+	CacheDir string
+}
+
 // NewClient initializes and returns a new GitHub API client.
-// It configures authentication (using GITHUB_TOKEN if available) and adds an HTTP cache layer.
+// It configures authentication (using GH_INSTALL_TOKEN, GITHUB_TOKEN or the gh CLI's token
+// if available; see utils.ResolveToken for GitHub Enterprise Server) and adds an HTTP cache
+// layer.
 //
 // - ctx: The context for the client, allows for cancellation.
 // Returns: An initialized *github.Client and an error if setup fails (e.g., cache directory creation).
 func NewClient(ctx context.Context) (*github.Client, error) {
-	// Get the user's cache directory (platform-specific).
-	// This is where we'll store cached HTTP responses to reduce API calls.
-	projectCacheDir, err := os.UserCacheDir()
-	if err != nil {
-		// Return an error if the user cache directory cannot be determined.
-		return nil, fmt.Errorf("failed to get user cache directory: %w", err)
-	}
+	return NewClientWithOptions(ctx, ClientOptions{})
+}
 
-	// Define the subdirectory name within the user cache directory for this application.
-	appCacheDirName := "gh-install"
-	// Construct the full path for the application's cache directory.
-	cachePath := filepath.Join(projectCacheDir, appCacheDirName)
+// NewClientWithRetry is like NewClient, but wraps the transport in a RetryTransport so
+// rate-limited requests are retried (waiting for the limit to reset) up to maxRetries times.
+//
+// - ctx: The context for the client, allows for cancellation.
+// - maxRetries: Maximum number of retries for a rate-limited request.
+// Returns: An initialized *github.Client and an error if setup fails.
+func NewClientWithRetry(ctx context.Context, maxRetries int) (*github.Client, error) {
+	return NewClientWithOptions(ctx, ClientOptions{MaxRetries: maxRetries})
+}
 
-	// Create the cache directory if it doesn't exist. 0o750 is the permission
-	// mode in octal notation: Owner: read/write/execute (7) Group: read/execute
-	// (5) Others: no access (0)
-	if err := os.MkdirAll(cachePath, 0o750); err != nil { //nolint:mnd
-		// Return an error if the cache directory cannot be created.
-		return nil, fmt.Errorf("could not create cache directory '%s': %w", cachePath, err)
+// NewClientWithOptions builds the GitHub client shared by NewClient and
+// NewClientWithRetry. A RetryTransport is only added when opts.MaxRetries is greater
+// than zero, and the disk cache is left out when opts.NoCache is set.
+//
+// - ctx: The context for the client, allows for cancellation.
+// - opts: How to build the client.
+// Returns: An initialized *github.Client and an error if setup fails.
+func NewClientWithOptions(ctx context.Context, opts ClientOptions) (*github.Client, error) {
+	baseURL, uploadURL, hostName, err := enterpriseURLs(resolveHost(opts.Host))
+	if err != nil {
+		return nil, err
 	}
 
-	// Initialize the disk cache using the specified path.
-	// This cache will store HTTP responses to reduce API calls.
-	cache := diskcache.New(cachePath)
+	// Requests go straight to GitHub, or through the disk cache to reduce API calls
+	var transport http.RoundTripper = NewBaseTransport()
+	if opts.NoCache {
+		utils.Logger.Debug("🔧  HTTP cache disabled, every request goes to GitHub.")
+	} else {
+		transport, err = newCacheTransport(opts.CacheDir, hostName)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Get the GitHub token from the environment variable.
+	// Get the GitHub token from the environment, or from the gh CLI if it's logged in.
 	// Using an environment variable is more secure than hardcoding the token.
-	token := os.Getenv("GITHUB_TOKEN")
-
-	var httpClient *http.Client // Variable to hold the final configured HTTP client.
-	// Initialize an HTTP transport that uses the disk cache.
-	cacheTransport := httpcache.NewTransport(cache)
+	token, tokenSource := utils.ResolveToken(hostName)
 
 	// Check if a GitHub token was found.
 	if token != "" {
-		utils.Logger.Debug("🔧  Using GITHUB_TOKEN for authentication.")
+		utils.Logger.Debugf("🔧  Using %s for authentication.", tokenSource)
 		// Create an OAuth2 token source with the provided token.
 		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-		// Create an OAuth2 transport that wraps the cache transport and adds the token to requests.
-		// This allows authenticated requests to be cached.
-		authTransport := &oauth2.Transport{
-			Base:   cacheTransport,                   // The transport to wrap (our cache transport).
+		// Create an OAuth2 transport that wraps the (cache) transport and adds the token to
+		// requests. This allows authenticated requests to be cached.
+		transport = &oauth2.Transport{
+			Base:   transport,                        // The transport to wrap.
 			Source: oauth2.ReuseTokenSource(nil, ts), // Source for the token, reusing it.
 		}
-		// Wrap the authenticated transport with our custom CachingTransport.
-		// This allows us to add custom logic around HTTP requests if needed.
-		cachingTransport := &CachingTransport{Transport: authTransport}
-		// Create the final HTTP client using the wrapped authenticated transport.
-		httpClient = &http.Client{Transport: cachingTransport}
 	} else {
-		utils.Logger.Debug("⚠️  No GITHUB_TOKEN found, using unauthenticated requests (lower rate limit).")
-		// If no token is found, use the cache transport directly wrapped in our custom transport.
+		utils.Logger.Debug(
+			"⚠️  No GH_INSTALL_TOKEN or GITHUB_TOKEN found and gh isn't logged in, " +
+				"using unauthenticated requests (lower rate limit).",
+		)
 		// Unauthenticated requests have much lower rate limits (60/hour vs 5000/hour).
-		debugTransport := &CachingTransport{Transport: cacheTransport}
-		// Create the final HTTP client using the wrapped cache transport.
-		httpClient = &http.Client{Transport: debugTransport}
+	}
+	if !opts.NoCache {
+		// Wrap the cached transport with our custom CachingTransport.
+		// This allows us to add custom logic around HTTP requests if needed.
+		transport = &CachingTransport{Transport: transport}
+	}
+
+	// Retry rate-limited requests on top of the caching (and authenticated) transport.
+	if opts.MaxRetries > 0 {
+		transport = &RetryTransport{
+			Transport:  transport,
+			MaxRetries: opts.MaxRetries,
+		}
 	}
 
 	// Create and return the GitHub client using the configured HTTP client.
-	client := github.NewClient(httpClient)
+	client := github.NewClient(&http.Client{Transport: transport})
+	if baseURL != "" {
+		utils.Logger.Debugf("🔧  Using GitHub Enterprise Server at %s", baseURL)
+		client, err = client.WithEnterpriseURLs(baseURL, uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure GitHub Enterprise URLs: %w", err)
+		}
+	}
+	if err := applyURLOverrides(client, opts); err != nil {
+		return nil, err
+	}
 	return client, nil
 }
 
+// newCacheTransport returns a transport caching responses on disk, keeping responses
+// from GitHub Enterprise Server hosts apart from github.com's.
+//
+// - cacheDir: The cache directory asked for, see ClientOptions.CacheDir; may be empty.
+// - hostName: The GitHub Enterprise Server host name; empty for github.com.
+// Returns: The cache transport, and an error if the cache directory can't be created.
+func newCacheTransport(cacheDir, hostName string) (*httpcache.Transport, error) {
+	// Resolve where we'll store cached HTTP responses to reduce API calls.
+	cachePath, err := CachePath(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	if hostName != "" {
+		// Keep responses from different GitHub hosts apart
+		cachePath = filepath.Join(cachePath, "hosts", hostName)
+	}
+
+	// Create the cache directory if it doesn't exist. 0o750 is the permission
+	// mode in octal notation: Owner: read/write/execute (7) Group: read/execute
+	// (5) Others: no access (0)
+	if err := os.MkdirAll(cachePath, 0o750); err != nil { //nolint:mnd
+		// Return an error if the cache directory cannot be created.
+		return nil, fmt.Errorf("could not create cache directory '%s': %w", cachePath, err)
+	}
+
+	// Initialize an HTTP transport that uses the disk cache.
+	cacheTransport := httpcache.NewTransport(diskcache.New(cachePath))
+	cacheTransport.Transport = NewBaseTransport()
+	return cacheTransport, nil
+}
+
 // CheckRateLimit retrieves the current GitHub API rate limit status and logs it.
 // This is useful for monitoring usage and diagnosing rate limit errors.
 //
 // - ctx: The context for the API call, allows for cancellation/timeouts.
 // - client: The initialized GitHub client for making API requests.
-func CheckRateLimit(ctx context.Context, client *github.Client) {
+// Returns: The core rate limit, and an error if it couldn't be retrieved.
+func CheckRateLimit(ctx context.Context, client *github.Client) (*github.Rate, error) {
 	// Call the GitHub API to get the rate limits.
 	// GitHub provides separate rate limits for different API endpoints.
 	limits, resp, err := client.RateLimit.Get(ctx)
@@ -121,16 +380,17 @@ func CheckRateLimit(ctx context.Context, client *github.Client) {
 		// Even if the API call failed, the 'resp' might contain rate limit headers.
 		// Attempt to print rate limit info from the response headers as a fallback.
 		PrintRateLimit(resp)
-		return
+		return nil, fmt.Errorf("failed to retrieve rate limits: %w", err)
 	}
 	// If the call succeeded and limit data is available, print the core limit.
 	// The "core" limit applies to most GitHub API endpoints.
-	if limits != nil && limits.Core != nil {
-		printRate(limits.Core)
-	} else {
+	if limits == nil || limits.Core == nil {
 		// Log a warning if the returned data structure doesn't contain expected rate limit info.
 		utils.Logger.Debug("Warning: Rate limit data not available in response.")
+		return nil, errors.New("rate limit data not available in response")
 	}
+	printRate(limits.Core)
+	return limits.Core, nil
 }
 
 // PrintRateLimit logs rate limit information extracted directly from a GitHub API Response.
@@ -170,11 +430,29 @@ func printRate(rate *github.Rate) {
 	)
 
 	// Provide additional context based on the identified rate limit.
-	const authenticatedLimit = 5000 // Typical authenticated rate limit per hour.
-	const unauthenticatedLimit = 60 // Typical unauthenticated rate limit per hour.
 	if rate.Limit >= authenticatedLimit {
 		utils.Logger.Debug("  Using authenticated rate limits.")
 	} else if rate.Limit <= unauthenticatedLimit {
 		utils.Logger.Debug("  Using unauthenticated rate limits.")
 	}
 }
+
+// RateLimitHint tells users how long to wait for an exhausted rate limit, e.g. "rate
+// limit exceeded; resets in 14m32s (at 15:04 MST); set GITHUB_TOKEN to raise the limit
+// to 5000/hr". The token advice is left out when requests are already authenticated.
+//
+// - rate: The exhausted rate limit.
+// - now: The current time, to count down from.
+// Returns: The hint.
+func RateLimitHint(rate github.Rate, now time.Time) string {
+	wait := max(rate.Reset.Sub(now).Round(time.Second), 0)
+	hint := fmt.Sprintf(
+		"rate limit exceeded; resets in %s (at %s)",
+		wait,
+		rate.Reset.Local().Format("15:04 MST"),
+	)
+	if rate.Limit < authenticatedLimit {
+		hint += fmt.Sprintf("; set GITHUB_TOKEN to raise the limit to %d/hr", authenticatedLimit)
+	}
+	return hint
+}
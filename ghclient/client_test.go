@@ -5,9 +5,16 @@ package ghclient_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 
 	// "io" // No longer strictly needed if not using a variable for os.Stderr
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +23,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 
+	"github.com/esacteksab/httpcache"
+
 	"github.com/esacteksab/gh-install/ghclient"
 	"github.com/esacteksab/gh-install/utils"
 )
@@ -65,36 +74,91 @@ func captureLogOutput(fn func()) string {
 	return buf.String()
 }
 
-func TestNewClient_WithToken(t *testing.T) {
-	utils.CreateLogger(true)
+// isProxyFromEnvironment reports whether proxy is http.ProxyFromEnvironment. The
+// function is compared rather than called, as it reads the proxy variables only once
+// per process and a lookup would depend on which test sent a request first.
+func isProxyFromEnvironment(proxy func(*http.Request) (*url.URL, error)) bool {
+	return proxy != nil &&
+		reflect.ValueOf(proxy).Pointer() == reflect.ValueOf(http.ProxyFromEnvironment).Pointer()
+}
 
-	tempCacheDir := t.TempDir()
-	originalXDGHome := os.Getenv("XDG_CACHE_HOME")
-	t.Setenv("XDG_CACHE_HOME", tempCacheDir)
-	defer t.Setenv("XDG_CACHE_HOME", originalXDGHome)
+func TestNewBaseTransport_Proxy(t *testing.T) {
+	utils.CreateLogger(false)
 
-	t.Setenv("GITHUB_TOKEN", "fake-test-token")
-	defer t.Setenv("GITHUB_TOKEN", "")
+	transport := ghclient.NewBaseTransport()
+	assert.True(t, isProxyFromEnvironment(transport.Proxy),
+		"base transport should honor HTTPS_PROXY and NO_PROXY")
 
-	ctx := context.Background()
-	var client *github.Client
-	var err error
-
-	logMsgs := captureLogOutput(func() {
-		client, err = ghclient.NewClient(ctx)
-	})
+	download, ok := ghclient.NewDownloadClient().Transport.(*http.Transport)
+	require.True(t, ok, "download client should use an *http.Transport")
+	assert.True(t, isProxyFromEnvironment(download.Proxy))
 
+	// The API client's cache layer sits on top of the same base transport
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("GH_INSTALL_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	client, err := ghclient.NewClient(context.Background())
 	require.NoError(t, err)
-	require.NotNil(t, client)
+	caching, ok := client.Client().Transport.(*ghclient.CachingTransport)
+	require.True(t, ok, "Transport should be CachingTransport")
+	cache, ok := caching.Transport.(*httpcache.Transport)
+	require.True(t, ok, "CachingTransport should wrap the cache transport")
+	base, ok := cache.Transport.(*http.Transport)
+	require.True(t, ok, "cache transport should wrap an *http.Transport")
+	assert.True(t, isProxyFromEnvironment(base.Proxy))
+}
 
-	assert.Contains(t, logMsgs, "Using GITHUB_TOKEN for authentication.")
+func TestNewClient_WithToken(t *testing.T) {
+	utils.CreateLogger(true)
 
-	httpClient := client.Client()
-	require.NotNil(t, httpClient)
-	cachingTransport, ok := httpClient.Transport.(*ghclient.CachingTransport)
-	require.True(t, ok, "Transport should be CachingTransport")
-	_, ok = cachingTransport.Transport.(*oauth2.Transport)
-	assert.True(t, ok, "CachingTransport should wrap oauth2.Transport when token is set")
+	tests := []struct {
+		name           string
+		ghInstallToken string
+		githubToken    string
+		wantLog        string
+	}{
+		{
+			name:        "GITHUB_TOKEN",
+			githubToken: "fake-test-token",
+			wantLog:     "Using GITHUB_TOKEN for authentication.",
+		},
+		{
+			name:           "GH_INSTALL_TOKEN",
+			ghInstallToken: "fake-tool-token",
+			wantLog:        "Using GH_INSTALL_TOKEN for authentication.",
+		},
+		{
+			name:           "GH_INSTALL_TOKEN takes priority",
+			ghInstallToken: "fake-tool-token",
+			githubToken:    "fake-test-token",
+			wantLog:        "Using GH_INSTALL_TOKEN for authentication.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			t.Setenv("GH_INSTALL_TOKEN", tt.ghInstallToken)
+			t.Setenv("GITHUB_TOKEN", tt.githubToken)
+
+			var client *github.Client
+			var err error
+			logMsgs := captureLogOutput(func() {
+				client, err = ghclient.NewClient(context.Background())
+			})
+
+			require.NoError(t, err)
+			require.NotNil(t, client)
+			assert.Contains(t, logMsgs, tt.wantLog)
+			assert.NotContains(t, logMsgs, "fake-", "the token itself must not be logged")
+
+			httpClient := client.Client()
+			require.NotNil(t, httpClient)
+			cachingTransport, ok := httpClient.Transport.(*ghclient.CachingTransport)
+			require.True(t, ok, "Transport should be CachingTransport")
+			_, ok = cachingTransport.Transport.(*oauth2.Transport)
+			assert.True(t, ok, "CachingTransport should wrap oauth2.Transport when token is set")
+		})
+	}
 }
 
 func TestNewClient_WithoutToken(t *testing.T) {
@@ -106,8 +170,10 @@ func TestNewClient_WithoutToken(t *testing.T) {
 	defer t.Setenv("XDG_CACHE_HOME", originalXDGHome)
 
 	originalToken := os.Getenv("GITHUB_TOKEN")
+	t.Setenv("GH_INSTALL_TOKEN", "")
 	t.Setenv("GITHUB_TOKEN", "")
 	defer t.Setenv("GITHUB_TOKEN", originalToken)
+	t.Setenv("PATH", t.TempDir()) // Keep a logged-in gh CLI from providing a token
 
 	ctx := context.Background()
 	var client *github.Client
@@ -120,7 +186,7 @@ func TestNewClient_WithoutToken(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, client)
 
-	assert.Contains(t, logMsgs, "No GITHUB_TOKEN found")
+	assert.Contains(t, logMsgs, "No GH_INSTALL_TOKEN or GITHUB_TOKEN found")
 
 	httpClient := client.Client()
 	require.NotNil(t, httpClient)
@@ -130,6 +196,313 @@ func TestNewClient_WithoutToken(t *testing.T) {
 	assert.False(t, ok, "CachingTransport should NOT wrap oauth2.Transport when token is not set")
 }
 
+func TestNewClient_EnterpriseHost(t *testing.T) {
+	utils.CreateLogger(true)
+
+	tests := []struct {
+		name          string
+		hostFlag      string
+		apiURLEnv     string
+		hostEnv       string
+		wantBaseURL   string
+		wantUploadURL string
+	}{
+		{
+			name:          "GH_HOST",
+			hostEnv:       "ghe.example.com",
+			wantBaseURL:   "https://ghe.example.com/api/v3/",
+			wantUploadURL: "https://ghe.example.com/api/uploads/",
+		},
+		{
+			name:          "GITHUB_API_URL",
+			apiURLEnv:     "https://ghe.example.com/api/v3",
+			hostEnv:       "other.example.com",
+			wantBaseURL:   "https://ghe.example.com/api/v3/",
+			wantUploadURL: "https://ghe.example.com/api/uploads/",
+		},
+		{
+			name:          "Host overrides environment",
+			hostFlag:      "http://ghe.internal",
+			hostEnv:       "ghe.example.com",
+			wantBaseURL:   "http://ghe.internal/api/v3/",
+			wantUploadURL: "http://ghe.internal/api/uploads/",
+		},
+		{
+			name:          "github.com is the default API",
+			apiURLEnv:     "https://api.github.com",
+			wantBaseURL:   "https://api.github.com/",
+			wantUploadURL: "https://uploads.github.com/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			t.Setenv("GITHUB_API_URL", tt.apiURLEnv)
+			t.Setenv("GH_HOST", tt.hostEnv)
+			client, err := ghclient.NewClientWithOptions(
+				context.Background(),
+				ghclient.ClientOptions{Host: tt.hostFlag},
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBaseURL, client.BaseURL.String())
+			assert.Equal(t, tt.wantUploadURL, client.UploadURL.String())
+		})
+	}
+}
+
+func TestNewClient_CacheDir(t *testing.T) {
+	utils.CreateLogger(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"abc"`)
+		fmt.Fprint(w, `{"full_name": "owner/repo"}`)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	notADir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(notADir, nil, 0o600))
+
+	tests := []struct {
+		name        string
+		cacheDir    string
+		cacheDirEnv string
+		wantDefault bool
+	}{
+		{name: "flag", cacheDir: t.TempDir()},
+		{name: "environment", cacheDirEnv: t.TempDir()},
+		{name: "flag overrides environment", cacheDir: t.TempDir(), cacheDirEnv: t.TempDir()},
+		{name: "unwritable falls back to default", cacheDir: notADir, wantDefault: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xdgCache := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", xdgCache)
+			t.Setenv("GH_INSTALL_CACHE_DIR", tt.cacheDirEnv)
+			t.Setenv("GH_INSTALL_TOKEN", "")
+			t.Setenv("GITHUB_TOKEN", "")
+			t.Setenv("PATH", t.TempDir())
+			var client *github.Client
+			logMsgs := captureLogOutput(func() {
+				client, err = ghclient.NewClientWithOptions(
+					context.Background(),
+					ghclient.ClientOptions{Host: server.URL, CacheDir: tt.cacheDir},
+				)
+			})
+			require.NoError(t, err)
+			_, _, err = client.Repositories.Get(context.Background(), "owner", "repo")
+			require.NoError(t, err)
+
+			wantDir := tt.cacheDir
+			if wantDir == "" {
+				wantDir = tt.cacheDirEnv
+			}
+			wantDir = filepath.Join(wantDir, "gh-install")
+			if tt.wantDefault {
+				wantDir = filepath.Join(xdgCache, "gh-install")
+				assert.Contains(t, logMsgs, "isn't writable")
+			}
+			entries, err := os.ReadDir(filepath.Join(wantDir, "hosts", serverURL.Host))
+			require.NoError(t, err)
+			assert.NotEmpty(t, entries, "the response should be cached in %s", wantDir)
+		})
+	}
+}
+
+func TestNewClientWithOptions_NoCache(t *testing.T) {
+	utils.CreateLogger(true)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "unauthenticated"},
+		{name: "authenticated", token: "fake-test-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", cacheDir)
+			t.Setenv("GH_INSTALL_TOKEN", "")
+			t.Setenv("GITHUB_TOKEN", tt.token)
+			t.Setenv("PATH", t.TempDir())
+
+			client, err := ghclient.NewClientWithOptions(
+				context.Background(),
+				ghclient.ClientOptions{NoCache: true},
+			)
+			require.NoError(t, err)
+
+			transport := client.Client().Transport
+			_, ok := transport.(*ghclient.CachingTransport)
+			assert.False(t, ok, "Transport should not be a CachingTransport")
+			if auth, ok := transport.(*oauth2.Transport); ok {
+				assert.NotEmpty(t, tt.token, "oauth2.Transport without a token")
+				transport = auth.Base
+			}
+			_, ok = transport.(*http.Transport)
+			assert.True(t, ok, "Transport should be a plain *http.Transport, got %T", transport)
+
+			_, err = os.Stat(filepath.Join(cacheDir, "gh-install"))
+			assert.True(t, os.IsNotExist(err), "no cache directory should be created")
+		})
+	}
+}
+
+func TestNewClient_InvalidHost(t *testing.T) {
+	utils.CreateLogger(true)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	_, err := ghclient.NewClientWithOptions(
+		context.Background(),
+		ghclient.ClientOptions{Host: "https://"},
+	)
+	assert.Error(t, err)
+}
+
+func TestNewClient_URLOverrides(t *testing.T) {
+	utils.CreateLogger(true)
+
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"tag_name": "v1.2.3"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("GH_HOST", "ghe.example.com")
+	client, err := ghclient.NewClientWithOptions(
+		context.Background(),
+		ghclient.ClientOptions{
+			NoCache:   true,
+			APIURL:    server.URL + "/github",
+			UploadURL: server.URL + "/uploads/",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/github/", client.BaseURL.String())
+	assert.Equal(t, server.URL+"/uploads/", client.UploadURL.String())
+
+	release, _, err := client.Repositories.GetLatestRelease(
+		context.Background(), "owner", "repo",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", release.GetTagName())
+	assert.Equal(t, "/github/repos/owner/repo/releases/latest", gotPath)
+}
+
+func TestNewClient_InvalidURLOverrides(t *testing.T) {
+	utils.CreateLogger(true)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tests := []struct {
+		name      string
+		apiURL    string
+		uploadURL string
+	}{
+		{name: "API URL without a scheme", apiURL: "proxy.example.com"},
+		{name: "relative API URL", apiURL: "/api/v3"},
+		{name: "upload URL without a host", uploadURL: "https://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ghclient.NewClientWithOptions(
+				context.Background(),
+				ghclient.ClientOptions{NoCache: true, APIURL: tt.apiURL, UploadURL: tt.uploadURL},
+			)
+			assert.ErrorContains(t, err, "must be an absolute URL")
+		})
+	}
+}
+
+func TestCheckRateLimit(t *testing.T) {
+	utils.CreateLogger(true)
+
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantRemaining int
+		wantErr       bool
+	}{
+		{
+			name:   "core limit",
+			status: http.StatusOK,
+			body: `{"resources": {"core": ` +
+				`{"limit": 5000, "remaining": 42, "reset": 1700000000}}}`,
+			wantRemaining: 42,
+		},
+		{name: "no core limit", status: http.StatusOK, body: `{"resources": {}}`, wantErr: true},
+		{name: "request fails", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}
+			server := httptest.NewServer(http.HandlerFunc(handler))
+			defer server.Close()
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			rate, err := ghclient.CheckRateLimit(context.Background(), client)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, rate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemaining, rate.Remaining)
+		})
+	}
+}
+
+func TestRateLimitHint(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		rate  github.Rate
+		want  string
+		token bool
+	}{
+		{
+			name: "unauthenticated",
+			rate: github.Rate{
+				Limit: 60,
+				Reset: github.Timestamp{Time: now.Add(14*time.Minute + 32*time.Second)},
+			},
+			want:  "rate limit exceeded; resets in 14m32s",
+			token: true,
+		},
+		{
+			name: "authenticated",
+			rate: github.Rate{Limit: 5000, Reset: github.Timestamp{Time: now.Add(time.Minute)}},
+			want: "rate limit exceeded; resets in 1m0s",
+		},
+		{
+			name:  "already reset",
+			rate:  github.Rate{Limit: 60, Reset: github.Timestamp{Time: now.Add(-time.Minute)}},
+			want:  "resets in 0s",
+			token: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint := ghclient.RateLimitHint(tt.rate, now)
+			assert.Contains(t, hint, tt.want)
+			assert.Contains(t, hint, tt.rate.Reset.Local().Format("15:04 MST"))
+			assert.Equal(t, tt.token, strings.Contains(hint, "raise the limit to 5000/hr"))
+		})
+	}
+}
+
 func TestPrintRate(t *testing.T) {
 	utils.CreateLogger(true)
 	tests := []struct {
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT
+package ghclient
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+const (
+	// DefaultMaxRetryWait caps how long RetryTransport sleeps before a single retry,
+	// even if GitHub asks us to wait longer (the primary rate limit resets hourly).
+	DefaultMaxRetryWait = 2 * time.Minute
+	// DefaultRetryBaseDelay is the starting delay for exponential backoff when a
+	// rate-limited response carries no Retry-After or X-RateLimit-Reset hint.
+	DefaultRetryBaseDelay = 1 * time.Second
+
+	// maxBackoffShift bounds the exponent used for backoff to avoid overflowing time.Duration.
+	maxBackoffShift = 16
+)
+
+// RetryTransport wraps an http.RoundTripper and retries requests that were rejected
+// because of GitHub rate limiting (HTTP 429, or 403 with rate-limit headers).
+// The wait between attempts honors Retry-After and X-RateLimit-Reset, falling back
+// to exponential backoff, and is capped by MaxWait. A request whose context ends before
+// the wait would is not retried: the rate-limited response is returned as is, so the
+// caller sees GitHub's rate limit error rather than its own timeout.
+type RetryTransport struct {
+	Transport  http.RoundTripper // The underlying transport, usually a CachingTransport.
+	MaxRetries int               // Maximum number of retries after the initial attempt.
+	MaxWait    time.Duration     // Upper bound for a single wait. Zero means DefaultMaxRetryWait.
+	BaseDelay  time.Duration     // Initial backoff delay. Zero means DefaultRetryBaseDelay.
+}
+
+// RoundTrip executes the request, retrying rate-limited responses up to MaxRetries times.
+// This method satisfies the http.RoundTripper interface.
+//
+// - req: The HTTP request to execute.
+// Returns: The final HTTP response and an error, if any.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Transport.RoundTrip(req)
+		if err != nil || attempt >= t.MaxRetries || !isRateLimited(resp) {
+			return resp, err
+		}
+
+		now := time.Now()
+		wait := t.retryDelay(resp, attempt, now)
+		if deadline, ok := req.Context().Deadline(); ok && now.Add(wait).After(deadline) {
+			utils.Logger.Debugf(
+				"Rate limited by GitHub (HTTP %d); not retrying as the wait of %s outlasts "+
+					"the request timeout",
+				resp.StatusCode,
+				wait.Round(time.Second),
+			)
+			return resp, nil
+		}
+		utils.Logger.Warnf(
+			"Rate limited by GitHub (HTTP %d). Retrying in %s (attempt %d of %d).",
+			resp.StatusCode,
+			wait.Round(time.Second),
+			attempt+1,
+			t.MaxRetries,
+		)
+
+		// Drain and close the rejected response so the connection can be reused.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		// Requests with a body need a fresh copy of it for the next attempt.
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isRateLimited reports whether resp indicates the request was rejected by a rate limit.
+func isRateLimited(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" ||
+			resp.Header.Get("X-RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
+
+// retryDelay determines how long to wait before the next attempt.
+// Retry-After (seconds) takes precedence, then X-RateLimit-Reset (Unix timestamp),
+// and finally exponential backoff based on the attempt number.
+func (t *RetryTransport) retryDelay(resp *http.Response, attempt int, now time.Time) time.Duration {
+	maxWait := t.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultMaxRetryWait
+	}
+	baseDelay := t.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+
+	retryAfter, retryAfterErr := strconv.Atoi(resp.Header.Get("Retry-After"))
+	reset, resetErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+
+	var wait time.Duration
+	switch {
+	case retryAfterErr == nil:
+		wait = time.Duration(retryAfter) * time.Second
+	case resetErr == nil:
+		wait = time.Unix(reset, 0).Sub(now)
+	case attempt >= maxBackoffShift:
+		wait = maxWait
+	default:
+		wait = baseDelay << attempt
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait
+}
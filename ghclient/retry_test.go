@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: MIT
+
+package ghclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/esacteksab/gh-install/ghclient"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// newFlakyServer returns a server that rejects the first `failures` requests with the given
+// status and headers, then responds 200 OK. The number of requests served is tracked in calls.
+func newFlakyServer(
+	t *testing.T,
+	failures int32,
+	status int,
+	headers map[string]string,
+	calls *int32,
+) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		if n <= failures {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetryTransport(t *testing.T) {
+	utils.CreateLogger(true)
+
+	tests := []struct {
+		name       string
+		failures   int32
+		status     int
+		headers    map[string]string
+		maxRetries int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{
+			name:       "429 with Retry-After is retried",
+			failures:   1,
+			status:     http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "0"},
+			maxRetries: 3,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name:     "403 with exhausted rate limit is retried",
+			failures: 2,
+			status:   http.StatusForbidden,
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10),
+			},
+			maxRetries: 3,
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		{
+			name:       "403 without rate-limit headers is not retried",
+			failures:   1,
+			status:     http.StatusForbidden,
+			headers:    nil,
+			maxRetries: 3,
+			wantStatus: http.StatusForbidden,
+			wantCalls:  1,
+		},
+		{
+			name:       "gives up after max retries",
+			failures:   5,
+			status:     http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "0"},
+			maxRetries: 2,
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := newFlakyServer(t, tt.failures, tt.status, tt.headers, &calls)
+
+			client := &http.Client{Transport: &ghclient.RetryTransport{
+				Transport:  http.DefaultTransport,
+				MaxRetries: tt.maxRetries,
+				BaseDelay:  time.Millisecond,
+			}}
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestRetryTransport_ContextCancelled(t *testing.T) {
+	utils.CreateLogger(true)
+
+	var calls int32
+	server := newFlakyServer(
+		t,
+		10,
+		http.StatusTooManyRequests,
+		map[string]string{"Retry-After": "60"},
+		&calls,
+	)
+
+	client := &http.Client{Transport: &ghclient.RetryTransport{
+		Transport:  http.DefaultTransport,
+		MaxRetries: 3,
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Do(req)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second, "cancellation should interrupt the wait")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryTransport_WaitPastDeadline(t *testing.T) {
+	utils.CreateLogger(true)
+
+	reset := time.Now().Add(time.Hour)
+	var calls int32
+	server := newFlakyServer(
+		t,
+		10,
+		http.StatusForbidden,
+		map[string]string{
+			"Retry-After":           "60",
+			"X-RateLimit-Limit":     "60",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		},
+		&calls,
+	)
+
+	retryClient := &http.Client{Transport: &ghclient.RetryTransport{
+		Transport:  http.DefaultTransport,
+		MaxRetries: 3,
+	}}
+	client := github.NewClient(retryClient)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	// Like --timeout 1s, shorter than the 60s GitHub asks us to wait
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err = client.Repositories.GetLatestRelease(ctx, "owner", "repo")
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the wait should be skipped")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	var rateErr *github.RateLimitError
+	require.ErrorAs(t, err, &rateErr)
+	assert.Equal(t, reset.Unix(), rateErr.Rate.Reset.Unix())
+}
+
+func TestNewClientWithRetry(t *testing.T) {
+	utils.CreateLogger(true)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("GH_INSTALL_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	client, err := ghclient.NewClientWithRetry(context.Background(), 3)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+
+	retryTransport, ok := client.Client().Transport.(*ghclient.RetryTransport)
+	require.True(t, ok, "Transport should be RetryTransport")
+	assert.Equal(t, 3, retryTransport.MaxRetries)
+	_, ok = retryTransport.Transport.(*ghclient.CachingTransport)
+	assert.True(t, ok, "RetryTransport should wrap CachingTransport")
+}
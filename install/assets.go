@@ -0,0 +1,330 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// AssetKind is what SelectAssets took a release asset for.
+type AssetKind string
+
+const (
+	KindCandidate  AssetKind = "binary"           // A binary or archive
+	KindPackage    AssetKind = "system package"   // A .deb, .rpm or .apk package
+	KindChecksum   AssetKind = "checksum"         // A checksum file
+	KindSignature  AssetKind = "signature"        // A signature or certificate
+	KindProvenance AssetKind = "provenance"       // An SLSA provenance attestation
+	KindSource     AssetKind = "source archive"   // The source code
+	KindMetadata   AssetKind = "SBOM/attestation" // Other release metadata
+	KindFiltered   AssetKind = "filtered"         // Excluded by --content-type
+)
+
+// ScannedAsset is a release asset as classified by SelectAssets.
+type ScannedAsset struct {
+	Asset   *github.ReleaseAsset
+	Kind    AssetKind
+	Matches bool // Whether a binary or package matches the OS/Arch (and OS family)
+}
+
+// AssetSelection is the outcome of SelectAssets.
+type AssetSelection struct {
+	Main      *github.ReleaseAsset // Selected binary, archive or package; nil if none matches
+	IsPackage bool                 // Whether Main is a system package
+	Checksum  *github.ReleaseAsset // First checksum file, nil if the release has none
+	Fallback  *github.ReleaseAsset // Asset running through Rosetta 2, nil if none
+	Source    *github.ReleaseAsset // Source code archive, only looked for with opts.Source
+	Assets    []ScannedAsset       // Every scanned asset, in release order
+	// Tied are the builds matching as well as Main, Main included, when the tie-break
+	// couldn't decide between them; nil otherwise
+	Tied []*github.ReleaseAsset
+}
+
+// SelectAssets scans a release's assets for the binary or archive matching the OS/Arch,
+// the checksum file and the other files PlanInstall needs. A native binary or archive
+// is preferred over a Rosetta 2 fallback, which is preferred over a system package.
+// Among several matching binaries, see breakTie.
+//
+// -assets: The release assets.
+// -tag: The release tag, which source archives are named after.
+// -opts: The install options; ContentTypes and Source are used.
+// -family: The OS family system packages must fit, see utils.ResolveOSFamily.
+// -matcher: Matches assets built for the platform to install for, see opts.Matcher.
+// Returns: The selection, without the --asset, --asset-regex, --source and lockfile
+// overrides applied.
+func SelectAssets( //nolint:gocyclo
+	assets []*github.ReleaseAsset,
+	tag string,
+	opts InstallOptions,
+	family string,
+	matcher *utils.Matcher,
+) AssetSelection {
+	var sel AssetSelection
+	var packageAsset *github.ReleaseAsset
+	var matches []*github.ReleaseAsset
+
+	utils.Logger.Debugf(
+		"Scanning %d assets to find matching binary/archive and checksum file...",
+		len(assets),
+	)
+
+	for _, asset := range assets {
+		if asset == nil || asset.Name == nil || asset.ID == nil {
+			utils.Logger.Debug("Skipping asset with missing name or ID.")
+			continue
+		}
+		assetName := *asset.Name
+		scanned := ScannedAsset{Asset: asset}
+		switch {
+		case utils.IsSignatureFile(assetName):
+			utils.Logger.Debugf("Skipping signature file: %s", assetName)
+			scanned.Kind = KindSignature
+		case utils.IsProvenanceFile(assetName):
+			utils.Logger.Debugf("Found provenance attestation: %s", assetName)
+			scanned.Kind = KindProvenance
+		case utils.IsChecksumFile(assetName):
+			if sel.Checksum == nil {
+				utils.Logger.Debugf("Found potential checksum file: %s", assetName)
+				sel.Checksum = asset
+			} else {
+				utils.Logger.Warnf(
+					"Found multiple checksum files. Using '%s', ignoring '%s'.",
+					*sel.Checksum.Name,
+					assetName,
+				)
+			}
+			scanned.Kind = KindChecksum
+		case utils.IsSourceArchive(assetName, tag):
+			if opts.Source && sel.Source == nil {
+				utils.Logger.Debugf("Found source archive: %s", assetName)
+				sel.Source = asset
+			} else {
+				utils.Logger.Debugf("Skipping source archive: %s", assetName)
+			}
+			scanned.Kind = KindSource
+		case utils.IsMetadataAsset(assetName):
+			utils.Logger.Debugf("Skipping SBOM or attestation: %s", assetName)
+			scanned.Kind = KindMetadata
+		case !contentTypeAllowed(asset.GetContentType(), opts.ContentTypes):
+			utils.Logger.Debugf(
+				"Skipping asset with content type '%s': %s",
+				asset.GetContentType(),
+				assetName,
+			)
+			scanned.Kind = KindFiltered
+		case utils.IsSystemPackage(assetName):
+			scanned.Kind = KindPackage
+			switch {
+			case !utils.PackageMatchesFamily(assetName, family):
+				utils.Logger.Debugf("Skipping package for another OS family: %s", assetName)
+			case matcher.Match(assetName):
+				scanned.Matches = true
+				if packageAsset == nil {
+					utils.Logger.Debugf("Found potential system package: %s", assetName)
+					packageAsset = asset
+				}
+			}
+		default:
+			scanned.Kind = KindCandidate
+			if matcher.Match(assetName) {
+				utils.Logger.Debugf("Found potential main asset: %s", assetName)
+				scanned.Matches = true
+				matches = append(matches, asset)
+			} else if sel.Fallback == nil && matcher.MatchFallback(assetName) {
+				utils.Logger.Debugf("Found potential fallback asset: %s", assetName)
+				sel.Fallback = asset
+			}
+		}
+		sel.Assets = append(sel.Assets, scanned)
+	}
+
+	sel.Main, sel.Tied = breakTie(matches)
+	switch {
+	case sel.Main != nil:
+	case sel.Fallback != nil:
+		sel.Main = sel.Fallback
+	case packageAsset != nil:
+		sel.Main, sel.IsPackage = packageAsset, true
+	}
+	return sel
+}
+
+// assetPreference ranks builds matching the OS/Arch: statically linked ones run
+// regardless of the C library and come first, macOS universal binaries after native ones.
+func assetPreference(name string) int {
+	switch {
+	case utils.IsStaticBuild(name):
+		return 2 //nolint:mnd
+	case utils.IsUniversalBuild(name):
+		return 0
+	default:
+		return 1
+	}
+}
+
+// buildName returns the name of the build an asset holds, the same for the build
+// published in several formats, e.g. "tool_linux_amd64" for tool_linux_amd64.tar.gz,
+// tool_linux_amd64.zip and tool_linux_amd64 itself.
+func buildName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(ArchiveStem(name)), ".exe")
+}
+
+// breakTie picks the binary to install among several matching the OS/Arch, preferring
+// the best ranked builds (see assetPreference) and, of those, the first in release order.
+//
+// -matches: The matching binaries and archives, in release order.
+// Returns: The asset to install, nil without matches, and the best ranked assets when
+// they're different builds the ranking can't decide between.
+func breakTie(
+	matches []*github.ReleaseAsset,
+) (best *github.ReleaseAsset, tied []*github.ReleaseAsset) {
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	top := assetPreference(matches[0].GetName())
+	for _, asset := range matches[1:] {
+		top = max(top, assetPreference(asset.GetName()))
+	}
+	var preferred []*github.ReleaseAsset
+	builds := make(map[string]bool)
+	for _, asset := range matches {
+		if assetPreference(asset.GetName()) == top {
+			preferred = append(preferred, asset)
+			builds[buildName(asset.GetName())] = true
+		}
+	}
+	best = preferred[0]
+	if len(preferred) < len(matches) {
+		utils.Logger.Debugf("Preferring '%s' over the other matching assets", best.GetName())
+	}
+	if len(builds) > 1 {
+		return best, preferred
+	}
+	for _, asset := range preferred[1:] {
+		utils.Logger.Debugf(
+			"Using '%s', ignoring '%s' holding the same build",
+			best.GetName(),
+			asset.GetName(),
+		)
+	}
+	return best, nil
+}
+
+// PromptAsset returns an InstallOptions.ChooseAsset that lists the candidates on out,
+// numbered, and reads the number of the one to install from in.
+//
+// -in: Where the answer is read from.
+// -out: Where the prompt is written to; stderr so --output json stays parseable.
+func PromptAsset(in io.Reader, out io.Writer) func(candidates []string) (string, error) {
+	return func(candidates []string) (string, error) {
+		promptMu.Lock()
+		defer promptMu.Unlock()
+		fmt.Fprintln(out, "Several assets match this system equally well:") //nolint:errcheck
+		for i, name := range candidates {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, name) //nolint:errcheck
+		}
+		fmt.Fprintf(out, "Install which one? [1-%d] ", len(candidates)) //nolint:errcheck
+		answer, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read the choice: %w", err)
+		}
+		answer = strings.TrimSpace(answer)
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(candidates) {
+			return "", fmt.Errorf("invalid choice '%s'; pass --asset to choose one", answer)
+		}
+		return candidates[n-1], nil
+	}
+}
+
+// selectMainAndChecksum picks the asset to install and the checksum file to verify it
+// with. It applies the --source, --asset, --asset-regex and lockfile overrides on top of
+// SelectAssets and, apart from asking opts.ChooseAsset, does no I/O, so dry runs and
+// previews select exactly what an install would.
+//
+// -assets: The release assets.
+// -tag: The release tag.
+// -opts: The install options.
+// -family: The OS family system packages must fit, see utils.ResolveOSFamily.
+// -matcher: Matches assets built for the platform to install for.
+// Returns: The main asset and the checksum file, nil if the release has none, a
+// *NoMatchingAssetError if no asset fits the OS/Arch or an *AmbiguousAssetError if
+// several do equally well and opts.ChooseAsset is nil.
+func selectMainAndChecksum(
+	assets []*github.ReleaseAsset,
+	tag string,
+	opts InstallOptions,
+	family string,
+	matcher *utils.Matcher,
+) (main, checksum *github.ReleaseAsset, err error) {
+	scan := SelectAssets(assets, tag, opts, family, matcher)
+	main = scan.Main
+	overridden := opts.Source || opts.Asset != "" || opts.AssetRegex != nil || opts.Lock != nil
+	if len(scan.Tied) > 0 && !overridden {
+		if main, err = chooseTiedAsset(scan.Tied, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	if main != nil && main == scan.Fallback {
+		utils.Logger.Infof(
+			"No native asset found; using '%s', which runs through Rosetta 2",
+			*main.Name,
+		)
+	}
+	if opts.Source {
+		if scan.Source == nil {
+			return nil, nil, errors.New("the release has no source code archive")
+		}
+		main = scan.Source
+	}
+	if opts.Asset != "" || opts.AssetRegex != nil {
+		if main, err = selectAsset(assets, opts.Asset, opts.AssetRegex); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.Lock != nil {
+		if main, err = findLockedAsset(assets, opts.Lock.Asset); err != nil {
+			return nil, nil, err
+		}
+	}
+	if main == nil {
+		utils.Logger.Error("No asset matching OS/Arch found.")
+		goos, goarch := opts.Platform()
+		return nil, nil, newNoMatchingAssetError(assets, goos, goarch)
+	}
+	return main, scan.Checksum, nil
+}
+
+// chooseTiedAsset asks opts.ChooseAsset which of the tied assets to install.
+// Returns: The chosen asset, or an *AmbiguousAssetError when opts.ChooseAsset is nil.
+func chooseTiedAsset(
+	tied []*github.ReleaseAsset,
+	opts InstallOptions,
+) (*github.ReleaseAsset, error) {
+	names := make([]string, 0, len(tied))
+	for _, asset := range tied {
+		names = append(names, asset.GetName())
+	}
+	if opts.ChooseAsset == nil {
+		goos, goarch := opts.Platform()
+		return nil, &AmbiguousAssetError{OS: goos, Arch: goarch, Assets: names}
+	}
+	name, err := opts.ChooseAsset(names)
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range tied {
+		if asset.GetName() == name {
+			return asset, nil
+		}
+	}
+	return nil, fmt.Errorf("'%s' isn't one of the matching assets", name)
+}
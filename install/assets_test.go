@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func TestSelectAssets(t *testing.T) {
+	matcher := utils.NewMatcher("linux", "amd64")
+
+	tests := []struct {
+		name         string
+		assets       []*github.ReleaseAsset
+		family       string
+		wantMain     string
+		wantPackage  bool
+		wantChecksum string
+		wantKinds    []AssetKind
+	}{
+		{
+			name: "binary and checksum",
+			assets: testutil.Assets(
+				"tool_linux_amd64.tar.gz",
+				"tool_darwin_arm64.tar.gz",
+				"checksums.txt",
+				"checksums.txt.sig",
+				"tool_linux_amd64.sbom.json",
+			),
+			wantMain:     "tool_linux_amd64.tar.gz",
+			wantChecksum: "checksums.txt",
+			wantKinds: []AssetKind{
+				KindCandidate, KindCandidate, KindChecksum, KindSignature, KindMetadata,
+			},
+		},
+		{
+			name:        "package when there's no binary",
+			assets:      testutil.Assets("tool_linux_amd64.deb", "tool_darwin_arm64.tar.gz"),
+			family:      "debian",
+			wantMain:    "tool_linux_amd64.deb",
+			wantPackage: true,
+			wantKinds:   []AssetKind{KindPackage, KindCandidate},
+		},
+		{
+			name:      "package for another family",
+			assets:    testutil.Assets("tool_linux_amd64.deb"),
+			family:    "rhel",
+			wantKinds: []AssetKind{KindPackage},
+		},
+		{
+			name:      "nothing matches",
+			assets:    testutil.Assets("tool_windows_arm64.zip"),
+			wantKinds: []AssetKind{KindCandidate},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := SelectAssets(tt.assets, "v1.0.0", InstallOptions{}, tt.family, matcher)
+			if got := sel.Main.GetName(); got != tt.wantMain {
+				t.Errorf("SelectAssets() main = %q, want %q", got, tt.wantMain)
+			}
+			if sel.IsPackage != tt.wantPackage {
+				t.Errorf("SelectAssets() IsPackage = %t, want %t", sel.IsPackage, tt.wantPackage)
+			}
+			if got := sel.Checksum.GetName(); got != tt.wantChecksum {
+				t.Errorf("SelectAssets() checksum = %q, want %q", got, tt.wantChecksum)
+			}
+			var kinds []AssetKind
+			for _, scanned := range sel.Assets {
+				kinds = append(kinds, scanned.Kind)
+			}
+			if len(kinds) != len(tt.wantKinds) {
+				t.Fatalf("SelectAssets() kinds = %v, want %v", kinds, tt.wantKinds)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.wantKinds[i] {
+					t.Errorf("SelectAssets() kinds = %v, want %v", kinds, tt.wantKinds)
+					break
+				}
+			}
+		})
+	}
+}
+
+func Test_breakTie(t *testing.T) {
+	tests := []struct {
+		name     string
+		assets   []string
+		wantBest string
+		wantTied []string
+	}{
+		{name: "no matches"},
+		{name: "single match", assets: []string{"tool_linux_amd64"}, wantBest: "tool_linux_amd64"},
+		{
+			name: "same build in several formats",
+			assets: []string{
+				"tool_linux_amd64.zip", "tool_linux_amd64.tar.gz", "tool_linux_amd64",
+			},
+			wantBest: "tool_linux_amd64.zip",
+		},
+		{
+			name:     "static suffix",
+			assets:   []string{"tool-linux-amd64", "tool-linux-amd64-static"},
+			wantBest: "tool-linux-amd64-static",
+		},
+		{
+			name: "musl over gnu",
+			assets: []string{
+				"tool-x86_64-unknown-linux-gnu.tar.gz", "tool-x86_64-unknown-linux-musl.tar.gz",
+			},
+			wantBest: "tool-x86_64-unknown-linux-musl.tar.gz",
+		},
+		{
+			name:     "native over universal",
+			assets:   []string{"tool_darwin_all.tar.gz", "tool_darwin_arm64.tar.gz"},
+			wantBest: "tool_darwin_arm64.tar.gz",
+		},
+		{
+			name: "tied static builds",
+			assets: []string{
+				"tool_linux_amd64.tar.gz", "tool-static_linux_amd64", "tool-musl_linux_amd64",
+			},
+			wantBest: "tool-static_linux_amd64",
+			wantTied: []string{"tool-static_linux_amd64", "tool-musl_linux_amd64"},
+		},
+		{
+			name:     "different builds",
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool-lite_linux_amd64.tar.gz"},
+			wantBest: "tool_linux_amd64.tar.gz",
+			wantTied: []string{"tool_linux_amd64.tar.gz", "tool-lite_linux_amd64.tar.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, tied := breakTie(testutil.Assets(tt.assets...))
+			if got := best.GetName(); got != tt.wantBest {
+				t.Errorf("breakTie() best = %q, want %q", got, tt.wantBest)
+			}
+			var names []string
+			for _, asset := range tied {
+				names = append(names, asset.GetName())
+			}
+			if !slices.Equal(names, tt.wantTied) {
+				t.Errorf("breakTie() tied = %v, want %v", names, tt.wantTied)
+			}
+		})
+	}
+}
+
+func TestPromptAsset(t *testing.T) {
+	candidates := []string{"tool-gnu", "tool-lite"}
+	tests := []struct {
+		name    string
+		answer  string
+		want    string
+		wantErr bool
+	}{
+		{name: "first", answer: "1\n", want: "tool-gnu"},
+		{name: "second without newline", answer: " 2 ", want: "tool-lite"},
+		{name: "out of range", answer: "3\n", wantErr: true},
+		{name: "not a number", answer: "tool-gnu\n", wantErr: true},
+		{name: "end of input", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := PromptAsset(strings.NewReader(tt.answer), &out)(candidates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PromptAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PromptAsset() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "2) tool-lite") {
+				t.Errorf("PromptAsset() prompt doesn't list the candidates:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func Test_selectMainAndChecksum(t *testing.T) {
+	tests := []struct {
+		name         string
+		platform     [2]string
+		assets       []string
+		family       string
+		opts         InstallOptions
+		wantMain     string
+		wantChecksum string
+		wantErr      bool
+	}{
+		{
+			name:     "native binary",
+			platform: [2]string{"linux", "amd64"},
+			assets: []string{
+				"tool_darwin_arm64.tar.gz", "tool_linux_amd64.tar.gz", "checksums.txt",
+			},
+			wantMain:     "tool_linux_amd64.tar.gz",
+			wantChecksum: "checksums.txt",
+		},
+		{
+			name:     "first of multiple matches",
+			platform: [2]string{"linux", "amd64"},
+			assets: []string{
+				"tool_linux_amd64.tar.gz", "tool_linux_amd64.zip", "a.sha256", "b.sha256",
+			},
+			wantMain:     "tool_linux_amd64.tar.gz",
+			wantChecksum: "a.sha256",
+		},
+		{
+			name:     "binary preferred over package",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.deb", "tool_linux_amd64.tar.gz"},
+			family:   "debian",
+			wantMain: "tool_linux_amd64.tar.gz",
+		},
+		{
+			name:     "package as the sole match",
+			platform: [2]string{"linux", "amd64"},
+			assets: []string{
+				"tool_linux_amd64.deb", "tool-linux.x86_64.rpm", "tool_darwin_arm64.tar.gz",
+			},
+			family:   "rhel",
+			wantMain: "tool-linux.x86_64.rpm",
+		},
+		{
+			name:     "Rosetta 2 fallback",
+			platform: [2]string{"darwin", "arm64"},
+			assets:   []string{"tool_linux_arm64.tar.gz", "tool_darwin_amd64.tar.gz"},
+			wantMain: "tool_darwin_amd64.tar.gz",
+		},
+		{
+			name:     "source archive",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool_1.0.0_src.tar.gz"},
+			opts:     InstallOptions{Source: true},
+			wantMain: "tool_1.0.0_src.tar.gz",
+		},
+		{
+			name:     "no source archive",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz"},
+			opts:     InstallOptions{Source: true},
+			wantErr:  true,
+		},
+		{
+			name:     "asset overrides matching",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool-static.tar.gz"},
+			opts:     InstallOptions{Asset: "tool-static.tar.gz"},
+			wantMain: "tool-static.tar.gz",
+		},
+		{
+			name:     "locked asset",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool_linux_amd64.zip"},
+			opts: InstallOptions{
+				Lock: &config.LockEntry{Asset: "tool_linux_amd64.zip"},
+			},
+			wantMain: "tool_linux_amd64.zip",
+		},
+		{
+			name:     "nothing matches",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_windows_arm64.zip", "checksums.txt"},
+			wantErr:  true,
+		},
+		{
+			name:     "tied builds",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool-lite_linux_amd64.tar.gz"},
+			wantErr:  true,
+		},
+		{
+			name:     "tied builds chosen",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool-lite_linux_amd64.tar.gz"},
+			opts: InstallOptions{
+				ChooseAsset: func(candidates []string) (string, error) {
+					return candidates[1], nil
+				},
+			},
+			wantMain: "tool-lite_linux_amd64.tar.gz",
+		},
+		{
+			name:     "tied builds and an asset",
+			platform: [2]string{"linux", "amd64"},
+			assets:   []string{"tool_linux_amd64.tar.gz", "tool-lite_linux_amd64.tar.gz"},
+			opts:     InstallOptions{Asset: "tool_linux_amd64.tar.gz"},
+			wantMain: "tool_linux_amd64.tar.gz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main, checksum, err := selectMainAndChecksum(
+				testutil.Assets(tt.assets...), "v1.0.0", tt.opts, tt.family,
+				utils.NewMatcher(tt.platform[0], tt.platform[1]),
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectMainAndChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := main.GetName(); got != tt.wantMain {
+				t.Errorf("selectMainAndChecksum() main = %q, want %q", got, tt.wantMain)
+			}
+			if got := checksum.GetName(); got != tt.wantChecksum {
+				t.Errorf("selectMainAndChecksum() checksum = %q, want %q", got, tt.wantChecksum)
+			}
+		})
+	}
+}
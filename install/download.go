@@ -0,0 +1,449 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// partSuffix is appended to the target path while a download is in progress.
+// An existing .part file is resumed with an HTTP Range request where possible.
+const partSuffix = ".part"
+
+// stagedSuffix is appended to the target path of a binary downloaded as is, until it has
+// been verified and is renamed over the target.
+const stagedSuffix = ".new"
+
+// downloadAttempts is how many times a download is tried before giving up on a
+// transient network error, see isRetryableDownloadError.
+const downloadAttempts = 3
+
+// downloadRetryDelay is the base delay before retrying a failed download; it doubles
+// with every attempt and is jittered. It's a variable so tests can shorten it.
+var downloadRetryDelay = 1 * time.Second
+
+// DownloadAndSaveAsset downloads a specific release asset and saves it to targetSavePath.
+// The data is written to targetSavePath + ".part" and renamed into place once complete;
+// if a shorter .part file is left over from an interrupted download it's resumed.
+// Progress is displayed when progress is set, see newProgress; a line is logged otherwise.
+// Returns the path where the file was saved (which is targetSavePath on success) and any error.
+func DownloadAndSaveAsset(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	asset *github.ReleaseAsset,
+	httpClient *http.Client,
+	targetSavePath string,
+	progress bool,
+) (filePath string, err error) {
+	filePath, _, err = downloadAndHashAsset(
+		ctx, client, owner, repo, asset, httpClient, targetSavePath, "", progress,
+	)
+	return filePath, err
+}
+
+// downloadAndHashAsset is like DownloadAndSaveAsset, but also hashes the data with
+// algorithm as it's written, sparing a second pass over large assets.
+//
+// -algorithm: The algorithm to hash with; empty to skip hashing.
+// Returns: The saved path, the hex-encoded digest and any error. The digest is empty
+// when a partial download was resumed, as only the newly downloaded bytes were seen;
+// callers then hash the file on disk instead.
+func downloadAndHashAsset(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	asset *github.ReleaseAsset,
+	httpClient *http.Client,
+	targetSavePath, algorithm string,
+	progress bool,
+) (filePath, digest string, err error) {
+	if asset == nil || asset.Name == nil || asset.ID == nil || asset.Size == nil {
+		return "", "", errors.New("asset has missing information (name, id, or size)")
+	}
+
+	var hasher hash.Hash
+	if algorithm != "" {
+		if hasher, err = utils.GetHasher(algorithm); err != nil {
+			return "", "", err
+		}
+	}
+
+	assetName := *asset.Name
+	assetID := *asset.ID
+	assetSize := int64(*asset.Size)
+
+	utils.Logger.Debugf(
+		"Initiating download for asset: %s (ID: %d, Size: %d) to target path: %s",
+		assetName,
+		assetID,
+		assetSize,
+		targetSavePath,
+	)
+
+	// Use the .part file next to targetSavePath to save the data. Failed attempts keep
+	// it, so a retry resumes where the previous attempt stopped.
+	partPath := targetSavePath + partSuffix
+	start := time.Now()
+	var written int64
+	hashed := int64(-1) // Nothing hashed yet; a resumed .part file can't be
+	for attempt := 1; ; attempt++ {
+		var n int64
+		n, err = downloadAttempt(
+			ctx, client, owner, repo, asset, httpClient, partPath, hasher, &hashed, progress,
+		)
+		written += n
+		if err == nil {
+			break
+		}
+		if attempt >= downloadAttempts || !isRetryableDownloadError(err) {
+			// Error already contains context; the .part file is kept so the next run
+			// can resume unless the download was cancelled.
+			// Return targetSavePath even on error for potential cleanup
+			return targetSavePath, "", err
+		}
+		wait := downloadBackoff(attempt)
+		utils.Logger.Warnf(
+			"Downloading '%s' failed: %v. Retrying in %s (attempt %d of %d).",
+			assetName,
+			err,
+			wait.Round(time.Millisecond),
+			attempt+1,
+			downloadAttempts,
+		)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return targetSavePath, "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if err := os.Rename(partPath, targetSavePath); err != nil {
+		return targetSavePath, "", fmt.Errorf(
+			"failed to move '%s' to '%s': %w",
+			partPath,
+			targetSavePath,
+			err,
+		)
+	}
+	if !utils.IsChecksumFile(assetName) {
+		utils.Logger.Info(downloadSummary(assetName, written, time.Since(start)))
+	}
+
+	if hasher != nil && hashed >= 0 {
+		digest = hex.EncodeToString(hasher.Sum(nil))
+	}
+	// Return the path where the file was saved
+	return targetSavePath, digest, nil
+}
+
+// downloadAttempt downloads asset into partPath once, resuming from the data already in
+// partPath where the server allows it.
+//
+// -hasher: Fed the data written; reset when the download starts over. May be nil.
+// -hashed: How many bytes of the .part file hasher has seen, updated as data is written;
+// -1 once hasher can't cover the whole file, e.g. after resuming an earlier run's download.
+// Returns: The bytes written and any error.
+func downloadAttempt(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	asset *github.ReleaseAsset,
+	httpClient *http.Client,
+	partPath string,
+	hasher hash.Hash,
+	hashed *int64,
+	progress bool,
+) (int64, error) {
+	assetName := asset.GetName()
+	assetSize := int64(asset.GetSize())
+	var offset int64
+	if info, statErr := os.Stat(partPath); statErr == nil && info.Size() < assetSize {
+		offset = info.Size()
+		utils.Logger.Debugf("Found partial download '%s' (%d bytes)", partPath, offset)
+	}
+
+	rc, offset, err := openAssetStream(ctx, client, owner, repo, asset, httpClient, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close() //nolint:errcheck
+
+	switch {
+	case offset == 0:
+		*hashed = 0
+		if hasher != nil {
+			hasher.Reset()
+		}
+	case offset != *hashed:
+		*hashed = -1 // The caller hashes the file on disk instead
+	}
+	if *hashed < 0 {
+		hasher = nil
+	}
+	written, err := writeAssetToFile(
+		ctx, rc, partPath, assetName, assetSize, offset, true, progress, hasher,
+	)
+	if *hashed >= 0 {
+		*hashed += written
+	}
+	return written, err
+}
+
+// downloadStatusError is returned, wrapped, when the storage server answers a download
+// request with an unexpected HTTP status.
+type downloadStatusError struct {
+	Name       string // Asset being downloaded
+	StatusCode int    // HTTP status code of the response
+	Status     string // HTTP status line, e.g. "503 Service Unavailable"
+}
+
+// Error implements the error interface.
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("error downloading '%s': unexpected status %s", e.Name, e.Status)
+}
+
+// isRetryableDownloadError reports whether a failed download may succeed when tried
+// again: server errors (5xx, 408, 429) and dropped or reset connections are retried.
+// Cancellation, missing assets (404) and failures writing the file aren't; neither are
+// checksum mismatches, which are only detected after the download.
+func isRetryableDownloadError(err error) bool {
+	var statusErr *downloadStatusError
+	var ghErr *github.ErrorResponse
+	var rateErr *github.RateLimitError
+	var pathErr *fs.PathError
+	switch {
+	case err == nil, errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &statusErr):
+		return retryableStatus(statusErr.StatusCode)
+	case errors.As(err, &rateErr):
+		return false // RetryTransport already waited for the rate limit
+	case errors.As(err, &ghErr):
+		return ghErr.Response != nil && retryableStatus(ghErr.Response.StatusCode)
+	case errors.As(err, &pathErr):
+		return false // Writing the file failed, e.g. the disk is full
+	default:
+		return true // Unexpected EOF, connection reset and similar network errors
+	}
+}
+
+// retryableStatus reports whether an HTTP status indicates a transient server problem.
+func retryableStatus(code int) bool {
+	return code >= http.StatusInternalServerError ||
+		code == http.StatusRequestTimeout ||
+		code == http.StatusTooManyRequests
+}
+
+// downloadBackoff returns how long to wait before the attempt after attempt: the
+// doubling downloadRetryDelay with up to half of it randomized, so parallel --config
+// downloads don't retry in lockstep.
+func downloadBackoff(attempt int) time.Duration {
+	wait := downloadRetryDelay << (attempt - 1)
+	return wait/2 + rand.N(wait/2+1) //nolint:gosec,mnd
+}
+
+// downloadSummary describes a finished download for people on slow connections.
+//
+// -name: The asset name.
+// -written: The bytes downloaded, excluding any resumed from an earlier attempt.
+// -elapsed: How long the download took.
+// Returns: A line like "tool.tar.gz: 4.0 MiB in 2s (2.0 MiB/s)".
+func downloadSummary(name string, written int64, elapsed time.Duration) string {
+	summary := fmt.Sprintf(
+		"%s: %s in %s",
+		name,
+		utils.HumanBytes(written),
+		elapsed.Round(time.Millisecond),
+	)
+	if elapsed > 0 {
+		rate := int64(float64(written) / elapsed.Seconds())
+		summary += fmt.Sprintf(" (%s/s)", utils.HumanBytes(rate))
+	}
+	return summary
+}
+
+// openAssetStream starts downloading asset. GitHub redirects asset downloads to a
+// storage URL, which is requested with a Range header when offset > 0 so an interrupted
+// download can be resumed.
+// Returns: The response body and the offset it starts at: the requested offset if the
+// server honored the range (206 Partial Content), otherwise 0.
+func openAssetStream(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	asset *github.ReleaseAsset,
+	httpClient *http.Client,
+	offset int64,
+) (io.ReadCloser, int64, error) {
+	assetName := asset.GetName()
+	rc, redirectURL, err := client.Repositories.DownloadReleaseAsset(
+		ctx,
+		owner,
+		repo,
+		asset.GetID(),
+		nil, // Follow the redirect ourselves so we can ask for a range
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error initiating download for '%s': %w", assetName, err)
+	}
+	if rc != nil {
+		// Served directly without a redirect; there's no way to request a range
+		if offset > 0 {
+			utils.Logger.Debugf("Cannot resume '%s', restarting download", assetName)
+		}
+		return rc, 0, nil
+	}
+	if redirectURL == "" {
+		return nil, 0, fmt.Errorf(
+			"download request for '%s' returned no data stream and no error",
+			assetName,
+		)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, redirectURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating download request for '%s': %w", assetName, err)
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error downloading '%s': %w", assetName, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		utils.Logger.Debugf("Resuming download of '%s' at byte %d", assetName, offset)
+		return resp.Body, offset, nil
+	case resp.StatusCode == http.StatusOK:
+		return resp.Body, 0, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file doesn't fit the asset (anymore); start from scratch
+		resp.Body.Close() //nolint:errcheck,gosec
+		return openAssetStream(ctx, client, owner, repo, asset, httpClient, 0)
+	default:
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, 0, &downloadStatusError{
+			Name:       assetName,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+}
+
+// contextReader is an io.Reader that fails with ctx's error once ctx is done,
+// so an io.Copy stops on Ctrl-C or timeout even if the underlying reader would block.
+type contextReader struct {
+	ctx context.Context //nolint:containedctx
+	r   io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done.
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// writeAssetToFile writes asset data from a reader to localPath with progress display.
+// When offset is greater than zero the data is appended to the first offset bytes
+// already in localPath (a resumed download) and the progress display starts there;
+// otherwise localPath is truncated. A partially written file is removed on error
+// unless keepPartial is set; it's always removed when ctx is cancelled. Progress is only
+// displayed when progress is set, see newProgress. The data written is also fed to
+// hasher unless it's nil.
+// Returns: The number of bytes written, not counting the first offset, and any error.
+func writeAssetToFile(
+	ctx context.Context,
+	rc io.Reader,
+	localPath, displayName string,
+	assetSize, offset int64,
+	keepPartial, progress bool,
+	hasher hash.Hash,
+) (int64, error) {
+	utils.Logger.Debugf("Saving asset '%s' to specific local path '%s'", displayName, localPath)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY
+	}
+	// Create the output file at the specified localPath
+	file, err := os.OpenFile(localPath, flags, 0o644) //nolint:gosec,mnd
+	if err != nil {
+		return 0, fmt.Errorf("error creating file '%s': %w", localPath, err)
+	}
+	var fileClosed bool
+	defer func() {
+		if !fileClosed {
+			file.Close() //nolint:errcheck,gosec
+		}
+	}()
+	if offset > 0 {
+		// Drop anything past offset, e.g. a torn last write, before appending
+		if err := file.Truncate(offset); err != nil {
+			return 0, fmt.Errorf("error truncating file '%s': %w", localPath, err)
+		}
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("error seeking in file '%s': %w", localPath, err)
+		}
+	}
+
+	prog := newProgress(progress, displayName, assetSize, offset)
+	writers := []io.Writer{file, prog}
+	if hasher != nil {
+		writers = append(writers, hasher)
+	}
+	written, copyErr := io.Copy(io.MultiWriter(writers...), &contextReader{ctx: ctx, r: rc})
+	prog.Finish(copyErr)
+	closeErr := file.Close()
+	fileClosed = true
+
+	if copyErr != nil {
+		utils.Logger.Errorf(
+			"Error during download/copy for '%s' to '%s': %v",
+			displayName,
+			localPath,
+			copyErr,
+		)
+		if !keepPartial || ctx.Err() != nil {
+			_ = os.Remove(localPath) // Attempt cleanup on copy error or cancellation
+		}
+		return written, fmt.Errorf(
+			"error saving data for '%s' to '%s': %w",
+			displayName,
+			localPath,
+			copyErr,
+		)
+	}
+	if closeErr != nil {
+		utils.Logger.Errorf("Error closing file '%s' after download: %v", localPath, closeErr)
+		// Do not return error here if copy was successful, but log it.
+	}
+	if !utils.IsChecksumFile(displayName) {
+		utils.Logger.Debugf(
+			Green("✔")+" Successfully downloaded %s to %s",
+			displayName,
+			localPath,
+		)
+		utils.Logger.Info(Green("✔") + " Successfully downloaded")
+	}
+	return written, nil
+}
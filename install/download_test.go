@@ -0,0 +1,600 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_writeAssetToFile(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "asset-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Test data
+	testData := []byte("This is test data for the download simulation")
+	testSize := int64(len(testData))
+
+	type args struct {
+		rc          io.ReadCloser
+		localPath   string
+		displayName string
+		assetSize   int64
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		setup   func()
+		wantErr bool
+	}{
+		{
+			name: "successful download",
+			args: args{
+				rc:          io.NopCloser(bytes.NewReader(testData)),
+				localPath:   filepath.Join(tempDir, "successful.txt"),
+				displayName: "test-file.txt",
+				assetSize:   testSize,
+			},
+			wantErr: false,
+		},
+		{
+			name: "read error",
+			args: args{
+				rc:          io.NopCloser(&errorReader{err: errors.New("simulated read error")}),
+				localPath:   filepath.Join(tempDir, "read-error.txt"),
+				displayName: "error-file.txt",
+				assetSize:   100, // Arbitrary size
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid path",
+			args: args{
+				rc:          io.NopCloser(bytes.NewReader(testData)),
+				localPath:   filepath.Join(tempDir, "non-existent-dir", "invalid.txt"),
+				displayName: "invalid-path.txt",
+				assetSize:   testSize,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero-size file",
+			args: args{
+				rc:          io.NopCloser(bytes.NewReader([]byte{})),
+				localPath:   filepath.Join(tempDir, "empty.txt"),
+				displayName: "empty-file.txt",
+				assetSize:   0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "close error",
+			args: args{
+				rc:          &errorCloser{Reader: bytes.NewReader(testData)},
+				localPath:   filepath.Join(tempDir, "close-error.txt"),
+				displayName: "close-error.txt",
+				assetSize:   testSize,
+			},
+			wantErr: false, // The function continues even if close fails
+		},
+		{
+			name: "path exists and is directory",
+			args: args{
+				rc:          io.NopCloser(bytes.NewReader(testData)),
+				localPath:   tempDir, // Try to write to directory path
+				displayName: "dir-path.txt",
+				assetSize:   testSize,
+			},
+			setup: func() {
+				// Ensure temp dir exists
+				os.MkdirAll(tempDir, 0o755)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			_, err := writeAssetToFile(
+				context.Background(),
+				tt.args.rc,
+				tt.args.localPath,
+				tt.args.displayName,
+				tt.args.assetSize,
+				0,
+				false,
+				false,
+				nil,
+			)
+
+			// Check if error matches expectation
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeAssetToFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			// For successful cases, verify the file was created with correct content
+			if !tt.wantErr && err == nil {
+				// Check file exists
+				fileInfo, err := os.Stat(tt.args.localPath)
+				if err != nil {
+					t.Errorf("Expected file %s to exist, but got error: %v", tt.args.localPath, err)
+					return
+				}
+
+				// Check file size
+				if tt.args.assetSize != fileInfo.Size() && tt.name != "close error" {
+					t.Errorf("Expected file size %d, got %d", tt.args.assetSize, fileInfo.Size())
+				}
+
+				// For cases with testData, verify content
+				if tt.args.assetSize > 0 {
+					content, err := os.ReadFile(tt.args.localPath)
+					if err != nil {
+						t.Errorf("Failed to read file content: %v", err)
+						return
+					}
+
+					if !bytes.Equal(content, testData) && tt.name != "close error" {
+						t.Errorf("File content mismatch. Expected %s, got %s", testData, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_writeAssetToFile_cancelled(t *testing.T) {
+	testData := []byte("This is test data for the download simulation")
+
+	tests := []struct {
+		name        string
+		keepPartial bool
+		offset      int64
+	}{
+		{name: "fresh download"},
+		{name: "resumed download keeping partial files", keepPartial: true, offset: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localPath := filepath.Join(t.TempDir(), "cancelled.txt")
+			if tt.offset > 0 {
+				if err := os.WriteFile(localPath, testData[:tt.offset], 0o600); err != nil {
+					t.Fatalf("Failed to write partial file: %v", err)
+				}
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := writeAssetToFile(
+				ctx,
+				bytes.NewReader(testData[tt.offset:]),
+				localPath,
+				"cancelled.txt",
+				int64(len(testData)),
+				tt.offset,
+				tt.keepPartial,
+				false,
+				nil,
+			)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("writeAssetToFile() error = %v, want %v", err, context.Canceled)
+			}
+			if _, err := os.Stat(localPath); !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed, stat error = %v", localPath, err)
+			}
+		})
+	}
+}
+
+func TestDownloadAndSaveAsset_cancelled(t *testing.T) {
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("asset data"))
+		}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	target := filepath.Join(t.TempDir(), "tool")
+	asset := &github.ReleaseAsset{
+		Name: github.Ptr("tool"),
+		ID:   github.Ptr(int64(1)),
+		Size: github.Ptr(10),
+	}
+	_, err := DownloadAndSaveAsset(
+		ctx, client, "owner", "repo", asset, http.DefaultClient, target, false,
+	)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DownloadAndSaveAsset() error = %v, want %v", err, context.Canceled)
+	}
+	for _, path := range []string{target, target + partSuffix} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to exist, stat error = %v", path, err)
+		}
+	}
+}
+
+func Test_downloadAndHashAsset(t *testing.T) {
+	utils.CreateLogger(false)
+
+	data := []byte("asset data")
+	sum := sha256.Sum256(data)
+	want := hex.EncodeToString(sum[:])
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(data)
+		}),
+	)
+	asset := &github.ReleaseAsset{
+		Name: github.Ptr("tool"),
+		ID:   github.Ptr(int64(1)),
+		Size: github.Ptr(len(data)),
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		want      string
+	}{
+		{name: "hashed while downloading", algorithm: "sha256", want: want},
+		{name: "no algorithm", algorithm: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "tool")
+			_, digest, err := downloadAndHashAsset(
+				context.Background(), client, "owner", "repo", asset, http.DefaultClient,
+				target, tt.algorithm, false,
+			)
+			if err != nil {
+				t.Fatalf("downloadAndHashAsset() error = %v", err)
+			}
+			if digest != tt.want {
+				t.Errorf("downloadAndHashAsset() digest = %q, want %q", digest, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkDownloadChecksum compares hashing a downloaded asset in a second pass over
+// the file with hashing it as it's written.
+func BenchmarkDownloadChecksum(b *testing.B) {
+	utils.CreateLogger(false)
+	utils.SetQuiet()
+	b.Cleanup(func() { utils.CreateLogger(false) })
+
+	data := bytes.Repeat([]byte("gh-install"), 4<<20) //nolint:mnd
+	dir := b.TempDir()
+
+	b.Run("two-pass", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for b.Loop() {
+			path := filepath.Join(dir, "two-pass")
+			_, err := writeAssetToFile(
+				context.Background(), bytes.NewReader(data), path, "two-pass",
+				int64(len(data)), 0, false, false, nil,
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := utils.HashFile(path, "sha256"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("single-pass", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for b.Loop() {
+			path := filepath.Join(dir, "single-pass")
+			hasher := sha256.New()
+			_, err := writeAssetToFile(
+				context.Background(), bytes.NewReader(data), path, "single-pass",
+				int64(len(data)), 0, false, false, hasher,
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = hex.EncodeToString(hasher.Sum(nil))
+		}
+	})
+}
+
+// Custom error types for testing
+
+// errorReader is a reader that always returns an error
+type errorReader struct {
+	err error
+}
+
+func (e *errorReader) Read(p []byte) (n int, err error) {
+	return 0, e.err
+}
+
+// errorCloser is a ReadCloser that returns an error on Close
+type errorCloser struct {
+	io.Reader
+}
+
+func (e *errorCloser) Close() error {
+	return errors.New("simulated close error")
+}
+
+func TestDownloadAndSaveAsset_resume(t *testing.T) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+	tests := []struct {
+		name        string
+		partial     []byte // Contents of a leftover .part file, if any
+		redirect    bool   // Whether the API redirects to a storage URL
+		ignoreRange bool   // Whether the storage server ignores Range headers
+		wantRange   string
+	}{
+		{name: "fresh download", redirect: true},
+		{name: "resume partial download", partial: content[:10], redirect: true, wantRange: "bytes=10-"},
+		{
+			name:        "server ignores range",
+			partial:     []byte("garbage"),
+			redirect:    true,
+			ignoreRange: true,
+			wantRange:   "bytes=7-",
+		},
+		{name: "direct download restarts", partial: []byte("garbage")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRange string
+			client := testutil.NewGitHubClient(
+				t,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case strings.HasPrefix(r.URL.Path, "/storage/"):
+						gotRange = r.Header.Get("Range")
+						if tt.ignoreRange {
+							r.Header.Del("Range")
+						}
+						http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+					case tt.redirect:
+						http.Redirect(w, r, "http://"+r.Host+"/storage/asset", http.StatusFound)
+					default:
+						_, _ = w.Write(content)
+					}
+				}),
+			)
+
+			target := filepath.Join(t.TempDir(), "tool")
+			if tt.partial != nil {
+				if err := os.WriteFile(target+partSuffix, tt.partial, 0o600); err != nil {
+					t.Fatalf("Failed to write partial file: %v", err)
+				}
+			}
+			asset := &github.ReleaseAsset{
+				Name: github.Ptr("tool"),
+				ID:   github.Ptr(int64(1)),
+				Size: github.Ptr(len(content)),
+			}
+
+			path, err := DownloadAndSaveAsset(
+				context.Background(), client, "owner", "repo", asset, http.DefaultClient,
+				target, false,
+			)
+			if err != nil {
+				t.Fatalf("DownloadAndSaveAsset() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read downloaded file: %v", err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded content = %q, want %q", got, content)
+			}
+			if gotRange != tt.wantRange {
+				t.Errorf("Range header = %q, want %q", gotRange, tt.wantRange)
+			}
+			if _, err := os.Stat(target + partSuffix); !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed, stat error = %v", target+partSuffix, err)
+			}
+		})
+	}
+}
+
+func Test_downloadAndHashAsset_retry(t *testing.T) {
+	utils.CreateLogger(false)
+	downloadRetryDelay = 0
+	t.Cleanup(func() { downloadRetryDelay = time.Second })
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	sum := sha256.Sum256(content)
+
+	tests := []struct {
+		name      string
+		fail      func(w http.ResponseWriter) // Fails a storage request; nil to serve it
+		failures  int32                       // Number of storage requests to fail
+		wantErr   bool
+		wantCalls int32
+		wantRange string // Range header of the last storage request
+	}{
+		{
+			name: "connection dropped mid-download",
+			fail: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				_, _ = w.Write(content[:10])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler) // Drop the connection
+			},
+			failures:  1,
+			wantCalls: 2,
+			wantRange: "bytes=10-",
+		},
+		{
+			name:      "server error",
+			fail:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			failures:  1,
+			wantCalls: 2,
+		},
+		{
+			name:      "not found is not retried",
+			fail:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			failures:  downloadAttempts,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "gives up after the last attempt",
+			fail:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			failures:  downloadAttempts,
+			wantErr:   true,
+			wantCalls: downloadAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			var gotRange string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasPrefix(r.URL.Path, "/storage/") {
+					http.Redirect(w, r, "http://"+r.Host+"/storage/asset", http.StatusFound)
+					return
+				}
+				n := atomic.AddInt32(&calls, 1)
+				gotRange = r.Header.Get("Range")
+				if n <= tt.failures {
+					tt.fail(w)
+					return
+				}
+				http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+			}
+			client := testutil.NewGitHubClient(t, http.HandlerFunc(handler))
+			asset := &github.ReleaseAsset{
+				Name: github.Ptr("tool"),
+				ID:   github.Ptr(int64(1)),
+				Size: github.Ptr(len(content)),
+			}
+
+			target := filepath.Join(t.TempDir(), "tool")
+			_, digest, err := downloadAndHashAsset(
+				context.Background(), client, "owner", "repo", asset, http.DefaultClient,
+				target, "sha256", false,
+			)
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("storage requests = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("downloadAndHashAsset() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("downloadAndHashAsset() error = %v", err)
+			}
+			got, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatalf("Failed to read downloaded file: %v", err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded content = %q, want %q", got, content)
+			}
+			if want := hex.EncodeToString(sum[:]); digest != want {
+				t.Errorf("downloadAndHashAsset() digest = %q, want %q", digest, want)
+			}
+			if gotRange != tt.wantRange {
+				t.Errorf("Range header = %q, want %q", gotRange, tt.wantRange)
+			}
+		})
+	}
+}
+
+func Test_isRetryableDownloadError(t *testing.T) {
+	notFound := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	badGateway := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "unexpected EOF",
+			err:  fmt.Errorf("error saving data: %w", io.ErrUnexpectedEOF),
+			want: true,
+		},
+		{
+			name: "storage 503",
+			err:  &downloadStatusError{StatusCode: http.StatusServiceUnavailable},
+			want: true,
+		},
+		{name: "storage 404", err: &downloadStatusError{StatusCode: http.StatusNotFound}},
+		{name: "API 502", err: fmt.Errorf("error initiating download: %w", badGateway), want: true},
+		{name: "API 404", err: fmt.Errorf("error initiating download: %w", notFound)},
+		{name: "cancelled", err: fmt.Errorf("error saving data: %w", context.Canceled)},
+		{name: "disk full", err: &os.PathError{Op: "write", Path: "tool", Err: syscall.ENOSPC}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableDownloadError(tt.err); got != tt.want {
+				t.Errorf("isRetryableDownloadError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_downloadSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		written int64
+		elapsed time.Duration
+		want    string
+	}{
+		{
+			name:    "average speed",
+			written: 4 << 20,
+			elapsed: 2 * time.Second,
+			want:    "tool.tar.gz: 4.0 MiB in 2s (2.0 MiB/s)",
+		},
+		{
+			name:    "rounded elapsed time",
+			written: 512,
+			elapsed: 1500*time.Millisecond + 400*time.Microsecond,
+			want:    "tool.tar.gz: 512 B in 1.5s (341 B/s)",
+		},
+		{name: "no elapsed time", written: 10, want: "tool.tar.gz: 10 B in 0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downloadSummary("tool.tar.gz", tt.written, tt.elapsed); got != tt.want {
+				t.Errorf("downloadSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
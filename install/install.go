@@ -0,0 +1,1665 @@
+// SPDX-License-Identifier: MIT
+
+// Package install finds, downloads, verifies and installs the binary a GitHub release
+// publishes for an OS and architecture. It's what the gh install command runs, and can
+// be embedded by other Go programs through Install.
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/fatih/color"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/config"
+	"github.com/esacteksab/gh-install/ghclient"
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+const (
+	// RequireChecksumEnv is the environment variable that, when true, requires a
+	// verified checksum for every install, as InstallOptions.RequireChecksum does
+	RequireChecksumEnv = "GH_INSTALL_REQUIRE_CHECKSUM"
+	// RateLimitRetries is how many times a rate-limited GitHub API request is retried
+	// before giving up
+	RateLimitRetries = 3
+	// LockAlgorithm is the algorithm of the asset checksums recorded in lockfiles, that of
+	// Asset.Digest
+	LockAlgorithm = "sha256"
+)
+
+// Green, Red and Yellow color the check marks and warnings logged by installs and the
+// gh install commands. fatih/color leaves text uncolored when stdout isn't a terminal.
+var (
+	Green  = color.New(color.FgGreen).SprintFunc()
+	Red    = color.New(color.FgRed).SprintFunc()
+	Yellow = color.New(color.FgYellow).SprintFunc()
+)
+
+// Asset represents a successfully downloaded and verified release asset
+type Asset struct {
+	Name      string // Original filename of the downloaded asset from GitHub
+	Path      string // Local path where the asset was saved
+	MIMEType  string // MIME content type of the asset
+	Tag       string // Release tag the asset belongs to
+	Checksum  string // Verified checksum of the asset, empty if not verified
+	Algorithm string // Algorithm used to verify Checksum
+	Link      string // Stable symlink pointing to Path, empty without Symlink
+	Digest    string // SHA-256 of the downloaded release asset, recorded in lockfiles
+	URL       string // Browser download URL of the asset on GitHub
+	// Extra are the further binaries installed from the archive with Extract or
+	// AllBinaries; Path is the first one
+	Extra []string
+}
+
+// InstallOptions describes a single install. Only Owner and Repo are required; the
+// zero value of every other field installs the latest release's binary for the running
+// system to $XDG_BIN_HOME, as gh install owner/repo does.
+type InstallOptions struct { //nolint:revive
+	Owner string // Owner of the repository publishing the release
+	Repo  string // Name of the repository publishing the release
+	// Version is a release tag, a constraint such as "^1.2" or a commit SHA such as
+	// "3f2a9c1"; the latest release when empty or "latest"
+	Version string
+	// Prerelease resolves an empty Version to the newest release, pre-releases included
+	Prerelease bool
+	// IncludeDrafts installs a draft release requested by its tag, which only tokens
+	// with push access to the repository can see; a *DraftReleaseError is returned otherwise
+	IncludeDrafts bool
+
+	BinName string // Name to save the binary as; derived from the asset name when empty
+	Path    string // Target directory; $XDG_BIN_HOME when empty
+	Symlink bool   // Save the binary as <BinName>-<tag> and link BinName to it
+
+	// Asset or AssetRegex select the asset to install instead of OS/Arch matching
+	Asset      string
+	AssetRegex *regexp.Regexp
+	// ChooseAsset picks the asset to install among different builds matching the OS/Arch
+	// equally well, see PromptAsset; an *AmbiguousAssetError is returned when nil
+	ChooseAsset func(candidates []string) (string, error)
+	// ContentTypes limits OS/Arch matching to assets with one of these media types
+	ContentTypes []string
+	// OS and Arch select the platform to match assets for, as in GOOS and GOARCH;
+	// the running system's when empty. Binaries for another platform are only saved.
+	OS   string
+	Arch string
+	// NoRosettaFallback refuses darwin/amd64 assets on Apple Silicon when no arm64 or
+	// universal one exists, instead of installing them to run through Rosetta 2
+	NoRosettaFallback bool
+	// OSFamily is the family .deb, .rpm and .apk packages must fit, one of the utils
+	// Family constants or a distribution ID such as "ubuntu"; detected when empty
+	OSFamily string
+	// Source downloads the release's source code archive instead of a binary
+	Source bool
+	// Force replaces an existing file at the target path instead of refusing to
+	Force bool
+	// Extract names the binaries to install from an archive; AllBinaries installs every
+	// executable in it. Either way they keep their own names.
+	Extract     []string
+	AllBinaries bool
+	// KeepArchive is a directory to copy the verified archive to before extracting it
+	KeepArchive string
+
+	// Sha is the checksum algorithm to verify with; derived from the checksum file
+	// when empty
+	Sha string
+	// DefaultAlgorithm is the algorithm of checksum files whose name doesn't tell it,
+	// e.g. checksums.txt; when empty it's guessed from the checksums' length, falling
+	// back to utils.DefaultAlgorithmForGenericChecksums
+	DefaultAlgorithm string
+	// RequireChecksum fails the install when no checksum can be verified; so does
+	// setting RequireChecksumEnv
+	RequireChecksum bool
+	// AllowWeakChecksum verifies with md5, sha1 or crc32 when the checksum file calls
+	// for them; a *WeakChecksumError is returned otherwise
+	AllowWeakChecksum bool
+	// GPGKeys are armored public key files the checksum file's signature must verify
+	// against; the signature isn't checked when empty
+	GPGKeys []string
+	// VerifySignature requires a valid cosign signature, VerifyProvenance valid SLSA
+	// provenance for the downloaded asset
+	VerifySignature  bool
+	VerifyProvenance bool
+
+	// DownloadOnly saves a matched .deb/.rpm/.apk package instead of installing it
+	DownloadOnly bool
+	// Sudo runs the system package manager with sudo
+	Sudo bool
+	// Yes installs system packages without asking for confirmation on stdin
+	Yes bool
+
+	// PostInstall is a shell command run after the binary was installed; none when empty
+	PostInstall string
+	// IgnoreHookErrors only logs a failed PostInstall command instead of failing
+	IgnoreHookErrors bool
+
+	// DryRun only selects the asset and target path; nothing is downloaded or written
+	DryRun bool
+	// Record saves the install to the state file read by gh install list, upgrade and
+	// sbom; installs aren't tracked otherwise
+	Record bool
+	// Out is where the plan of a DryRun is printed; it isn't printed when nil
+	Out io.Writer
+	// Progress draws a progress bar on stderr while downloading, or logs how far
+	// downloads got every few seconds when stderr isn't a terminal; a line is logged
+	// per download otherwise
+	Progress bool
+	// Timeout bounds each GitHub API call; 0 disables the limit. Downloads aren't
+	// bounded by it, as large assets can legitimately take longer.
+	Timeout time.Duration
+	// Client queries the release and downloads its assets, e.g. one shared by several
+	// installs or pointed at a test server; one is created the way gh install does when nil
+	Client *github.Client
+	// NoCache sends every request of the client Install creates to GitHub instead of
+	// going through the disk cache
+	NoCache bool
+	// Host, APIURL, UploadURL and CacheDir configure the client Install creates, see
+	// ghclient.ClientOptions; they're ignored when Client is set
+	Host      string
+	APIURL    string
+	UploadURL string
+	CacheDir  string
+
+	// Lock pins the asset and its checksum; nil otherwise
+	Lock *config.LockEntry
+}
+
+// Install resolves the release described by opts, then downloads, verifies and
+// installs its matching asset. Without opts.Client it builds a GitHub client the way
+// gh install does, authenticated with GH_INSTALL_TOKEN, GITHUB_TOKEN (GH_ENTERPRISE_TOKEN
+// for a GitHub Enterprise Server Host) or the gh CLI's token.
+//
+// -ctx: Cancels API calls and downloads when done.
+// -opts: What to install and how; Owner and Repo are required.
+// Returns: The installed asset; for a DryRun, the asset that would be installed.
+func Install(ctx context.Context, opts InstallOptions) (Asset, error) {
+	utils.EnsureLogger()
+	return Release(ctx, opts)
+}
+
+// client returns opts.Client, or a new GitHub client configured like gh install's when
+// it's nil.
+func (o InstallOptions) client(ctx context.Context) (*github.Client, error) {
+	if o.Client != nil {
+		return o.Client, nil
+	}
+	apiCtx, cancel := WithAPITimeout(ctx, o.Timeout)
+	defer cancel()
+	client, err := ghclient.NewClientWithOptions(apiCtx, ghclient.ClientOptions{
+		MaxRetries: RateLimitRetries,
+		NoCache:    o.NoCache,
+		Host:       o.Host,
+		APIURL:     o.APIURL,
+		UploadURL:  o.UploadURL,
+		CacheDir:   o.CacheDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize GitHub client: %w", err)
+	}
+	return client, nil
+}
+
+// parseArgs validates opts.Owner and opts.Repo and interprets opts.Version the way the
+// owner/repo[@version] argument of gh install is.
+func (o InstallOptions) parseArgs() (utils.ParsedArgs, error) {
+	if o.Owner == "" || o.Repo == "" {
+		return utils.ParsedArgs{}, errors.New("both the owner and the repository are required")
+	}
+	arg := o.Owner + "/" + o.Repo
+	if o.Version != "" {
+		arg += "@" + o.Version
+	}
+	return utils.ParseArgs(arg)
+}
+
+// Platform returns the OS and architecture assets are matched for: opts.OS and
+// opts.Arch, defaulting to the running system's.
+func (o InstallOptions) Platform() (goos, goarch string) {
+	goos, goarch = runtime.GOOS, runtime.GOARCH
+	if o.OS != "" {
+		goos = strings.ToLower(o.OS)
+	}
+	if o.Arch != "" {
+		goarch = strings.ToLower(o.Arch)
+	}
+	return goos, goarch
+}
+
+// Matcher compiles the patterns matching release assets built for opts.Platform, without
+// the Rosetta 2 fallback when NoRosettaFallback is set. Each install builds its own, so
+// concurrent installs with different options don't interfere.
+func (o InstallOptions) Matcher() *utils.Matcher {
+	m := utils.NewMatcher(o.Platform())
+	if o.NoRosettaFallback {
+		return m.WithoutFallback()
+	}
+	return m
+}
+
+// CrossTarget reports whether opts select a platform other than the running one.
+// Binaries for another platform are only saved, not made executable, and system
+// packages for it aren't installed.
+func (o InstallOptions) CrossTarget() bool {
+	goos, goarch := o.Platform()
+	return goos != runtime.GOOS || goarch != runtime.GOARCH
+}
+
+// WithAPITimeout derives a context for a GitHub API call that's cancelled after
+// timeout. A timeout of 0 disables the limit. Downloads aren't bounded by it since large
+// assets can legitimately take longer.
+func WithAPITimeout(
+	ctx context.Context,
+	timeout time.Duration,
+) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Release is Install for callers running several installs at once, e.g. sharing one
+// opts.Client. Unlike Install it relies on utils.Logger already being created. Each
+// install matches assets for its own opts.OS and opts.Arch, see InstallOptions.Matcher.
+//
+// -ctx: Cancels API calls and downloads when done.
+// -opts: What to install and how; Owner and Repo are required.
+// Returns: The installed asset; for a DryRun, the asset that would be installed.
+func Release(ctx context.Context, opts InstallOptions) (Asset, error) {
+	pa, err := opts.parseArgs()
+	if err != nil {
+		return Asset{}, fmt.Errorf("invalid argument: %w", err)
+	}
+	client, err := opts.client(ctx)
+	if err != nil {
+		return Asset{}, err
+	}
+	apiCtx, cancel := WithAPITimeout(ctx, opts.Timeout)
+	release, err := ResolveRelease(apiCtx, client, pa, opts.Prerelease, opts.IncludeDrafts)
+	cancel()
+	if err != nil {
+		return Asset{}, err
+	}
+	assets := release.Assets
+	releaseTag := release.GetTagName()
+
+	if len(assets) == 0 {
+		return Asset{}, fmt.Errorf("no assets found for release '%s'", releaseTag)
+	}
+	goos, goarch := opts.Platform()
+	binNameData := utils.NewBinNameData(pa.Owner, pa.Repo, releaseTag, goos, goarch)
+	if opts.BinName, err = utils.RenderBinName(opts.BinName, binNameData); err != nil {
+		return Asset{}, err
+	}
+
+	if opts.DryRun {
+		plan, err := PlanInstall(assets, releaseTag, opts, opts.Matcher())
+		if err != nil {
+			return Asset{}, err
+		}
+		if opts.Out != nil {
+			printPlan(opts.Out, plan, releaseTag)
+		}
+		return Asset{
+			Name:     plan.Main.GetName(),
+			Path:     plan.SavePath,
+			MIMEType: plan.Main.GetContentType(),
+			Tag:      releaseTag,
+			Link:     plan.LinkPath,
+			URL:      plan.Main.GetBrowserDownloadURL(),
+		}, nil
+	}
+
+	downloadedAsset, err := findDownloadAndVerifyAsset(
+		ctx,
+		client,
+		pa.Owner,
+		pa.Repo,
+		releaseTag,
+		assets,
+		ghclient.NewDownloadClient(),
+		opts,
+	)
+	if err != nil {
+		return Asset{}, err
+	}
+	downloadedAsset.Tag = releaseTag
+	if opts.Source {
+		// A source archive isn't executable and there's nothing to upgrade it with
+		utils.Logger.Info(Green("✔") + " Saved source archive " + downloadedAsset.Path)
+		return downloadedAsset, nil
+	}
+	if utils.IsSystemPackage(downloadedAsset.Name) {
+		// Installed packages are tracked by the package manager and a downloaded
+		// package isn't an executable, so neither is chmod'ed or recorded
+		return downloadedAsset, nil
+	}
+
+	utils.Logger.Debugf("Successfully downloaded and verified: %s", downloadedAsset.Name)
+	utils.Logger.Debugf("Asset saved to: %s", downloadedAsset.Path)
+	utils.Logger.Debugf("Asset MIME Type: %s", downloadedAsset.MIMEType)
+	if opts.CrossTarget() {
+		// The binary is meant for another machine, so it's only saved
+		utils.Logger.Info(Green("✔") + " Saved " + downloadedAsset.Path)
+		return downloadedAsset, nil
+	}
+	for _, path := range append([]string{downloadedAsset.Path}, downloadedAsset.Extra...) {
+		utils.Logger.Debugf("chmod'ing %s", path)
+		if err := utils.ChmodFile(path); err != nil {
+			return Asset{}, fmt.Errorf("failed to make '%s' executable: %w", path, err)
+		}
+		utils.Logger.Info(Green("✔") + " Installed " + path)
+	}
+	if downloadedAsset.Link != "" {
+		if err := utils.EnsureSymlink(downloadedAsset.Path, downloadedAsset.Link); err != nil {
+			return Asset{}, fmt.Errorf("failed to link '%s': %w", downloadedAsset.Link, err)
+		}
+		utils.Logger.Info(Green("✔") + " Linked " + downloadedAsset.Link)
+	}
+	warnIfNotOnPath(filepath.Dir(downloadedAsset.Path))
+
+	// The binary is installed even if the hook fails, so record it first; otherwise it
+	// couldn't be listed or upgraded
+	if opts.Record {
+		recordInstall(pa, downloadedAsset)
+	}
+	if opts.PostInstall != "" {
+		err := utils.RunPostInstallHook(ctx, opts.PostInstall, downloadedAsset.Path, releaseTag)
+		if err != nil && !opts.IgnoreHookErrors {
+			return Asset{}, err
+		}
+		if err != nil {
+			utils.Logger.Warnf("Ignoring failed post-install hook: %v", err)
+		}
+	}
+	return downloadedAsset, nil
+}
+
+// pathWarnings remembers the directories warnIfNotOnPath already warned about, so a
+// --config run warns once per directory.
+var pathWarnings sync.Map
+
+// warnIfNotOnPath tells the user how to add dir to $PATH when it isn't on it, as the
+// binaries installed there can't be run by name otherwise.
+func warnIfNotOnPath(dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if utils.IsDirOnPath(dir) {
+		return
+	}
+	if _, warned := pathWarnings.LoadOrStore(dir, true); warned {
+		return
+	}
+	exportLine := fmt.Sprintf(`export PATH="%s:$PATH"`, dir)
+	if runtime.GOOS == "windows" {
+		exportLine = fmt.Sprintf(`$env:Path = "%s;" + $env:Path`, dir)
+	}
+	utils.Logger.Warnf(
+		"%s is not on your PATH. To run installed binaries by name, add this to your "+
+			"shell profile:\n  %s",
+		dir,
+		exportLine,
+	)
+}
+
+// printPlan writes the outcome of a --dry-run to w: the selected asset and its size,
+// the release tag, where the binary would be saved and how it would be verified.
+func printPlan(w io.Writer, plan Plan, tag string) {
+	checksum := "none, the release has no checksum file"
+	if plan.Checksum != nil {
+		checksum = "verified against " + plan.Checksum.GetName()
+	}
+	savePath := plan.SavePath
+	if plan.InstallPackage {
+		savePath = "installed with the " + plan.Family + " package manager"
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Release:\t%s\n", tag)                    //nolint:errcheck
+	fmt.Fprintf(tw, "Asset:\t%s\n", plan.Main.GetName())      //nolint:errcheck
+	fmt.Fprintf(tw, "Size:\t%d bytes\n", plan.Main.GetSize()) //nolint:errcheck
+	fmt.Fprintf(tw, "Save path:\t%s\n", savePath)             //nolint:errcheck
+	if plan.LinkPath != "" {
+		fmt.Fprintf(tw, "Symlink:\t%s\n", plan.LinkPath) //nolint:errcheck
+	}
+	fmt.Fprintf(tw, "Checksum:\t%s\n", checksum) //nolint:errcheck
+	tw.Flush()                                   //nolint:errcheck,gosec
+}
+
+// recordInstall saves the install to the state file. Failing to record an install
+// doesn't fail the install itself, so errors are only logged.
+func recordInstall(pa utils.ParsedArgs, asset Asset) {
+	absPath, err := filepath.Abs(asset.Path)
+	if err != nil {
+		absPath = asset.Path
+	}
+	absLink := asset.Link
+	if absLink != "" {
+		if abs, err := filepath.Abs(absLink); err == nil {
+			absLink = abs
+		}
+	}
+	err = state.Save(state.Record{
+		Owner:       pa.Owner,
+		Repo:        pa.Repo,
+		Tag:         asset.Tag,
+		AssetName:   asset.Name,
+		Path:        absPath,
+		Link:        absLink,
+		MIMEType:    asset.MIMEType,
+		Checksum:    asset.Checksum,
+		Algorithm:   asset.Algorithm,
+		DownloadURL: asset.URL,
+		InstalledAt: time.Now().UTC(),
+	})
+	if err != nil {
+		utils.Logger.Warnf("Failed to record install of %s/%s: %v", pa.Owner, pa.Repo, err)
+	}
+}
+
+// WithRateLimit adds the rate limit state to the error of a failed GitHub API call:
+// how long until it resets when the call was rate limited, the raw rate otherwise.
+//
+// - err: The error to add to.
+// - resp: The response of the call, may be nil.
+// Returns: The wrapped error.
+func WithRateLimit(err error, resp *github.Response) error {
+	var rateErr *github.RateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		return fmt.Errorf("%w; %s", err, ghclient.RateLimitHint(rateErr.Rate, time.Now()))
+	case resp == nil:
+		return err
+	case resp.Rate.Remaining == 0 && resp.Rate.Limit > 0 &&
+		(resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests):
+		return fmt.Errorf("%w; %s", err, ghclient.RateLimitHint(resp.Rate, time.Now()))
+	}
+	return fmt.Errorf("%w (Rate Limit: %s)", err, resp.Rate.String())
+}
+
+// GetLatestRelease returns the release GitHub marks as the latest of owner/repo, its
+// newest non-draft, non-prerelease release.
+//
+// -client: The GitHub client to query.
+// -owner, repo: The repository.
+// Returns: The release, or an error naming the repository when it has no releases.
+func GetLatestRelease(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+) (*github.RepositoryRelease, error) {
+	release, resp, err := client.Repositories.GetLatestRelease(ctx, owner, repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("repository %s/%s not found or has no releases", owner, repo)
+		}
+		return nil, WithRateLimit(fmt.Errorf(
+			"failed to get latest release: %w",
+			err,
+		), resp)
+	}
+	if release == nil {
+		return nil, errors.New("received nil release object from GitHub API")
+	}
+	return release, nil
+}
+
+// getTaggedRelease returns the release of owner/repo with the exact tag. GitHub doesn't
+// look up drafts by tag, so when there's no such release the listed releases are
+// searched for a draft with the tag, which is returned with includeDrafts and reported
+// as a *DraftReleaseError otherwise.
+func getTaggedRelease(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, tag string,
+	includeDrafts bool,
+) (*github.RepositoryRelease, error) {
+	release, resp, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			if draft := findDraftRelease(ctx, client, owner, repo, tag); draft != nil {
+				if !includeDrafts {
+					return nil, &DraftReleaseError{Tag: tag}
+				}
+				utils.Logger.Warnf("Installing draft release %s of %s/%s", tag, owner, repo)
+				return draft, nil
+			}
+			return nil, fmt.Errorf("release with tag '%s' not found in %s/%s", tag, owner, repo)
+		}
+		return nil, WithRateLimit(fmt.Errorf(
+			"failed to get release by tag '%s': %w",
+			tag,
+			err,
+		), resp)
+	}
+	if release == nil {
+		return nil, fmt.Errorf("received nil release object for tag '%s' from GitHub API", tag)
+	}
+	return release, nil
+}
+
+// findDraftRelease returns the draft release of owner/repo with tag, or nil when there's
+// none or the releases can't be listed. Drafts are only listed for tokens with push
+// access to the repository.
+func findDraftRelease(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, tag string,
+) *github.RepositoryRelease {
+	releases, err := ListReleases(ctx, client, owner, repo)
+	if err != nil {
+		utils.Logger.Debugf("Failed to look for a draft release %s: %v", tag, err)
+		return nil
+	}
+	for _, r := range releases {
+		if r.GetDraft() && r.GetTagName() == tag {
+			return r
+		}
+	}
+	return nil
+}
+
+// DraftReleaseError is returned when the requested tag only exists as a draft release
+// and InstallOptions.IncludeDrafts isn't set.
+type DraftReleaseError struct {
+	Tag string // Tag of the draft release
+}
+
+func (e *DraftReleaseError) Error() string {
+	return fmt.Sprintf("release %s is a draft; pass --include-drafts to install it", e.Tag)
+}
+
+// Plan describes what an install would download and where the binary ends up.
+type Plan struct {
+	Main           *github.ReleaseAsset // Binary, archive or system package matching the OS/Arch
+	Checksum       *github.ReleaseAsset // Checksum file, nil if the release has none
+	Provenance     *github.ReleaseAsset // SLSA provenance attestation, nil if none is published
+	SavePath       string               // Path the binary (or downloaded package) is saved to
+	LinkPath       string               // Symlink pointing to SavePath, empty without Symlink
+	IsArchive      bool                 // Whether Main has to be extracted
+	InstallPackage bool                 // Whether Main is a package to install with Family's tools
+	Family         string               // OS family the system package was selected for
+}
+
+// NoMatchingAssetError is returned when no release asset matches the OS and
+// architecture gh-install runs on. Assets lists every asset name that was scanned, so
+// unusual naming conventions can be spotted.
+type NoMatchingAssetError struct {
+	OS     string   // Operating system the assets were matched against, as in runtime.GOOS
+	Arch   string   // Architecture the assets were matched against, as in runtime.GOARCH
+	Assets []string // Names of the release's assets
+}
+
+// newNoMatchingAssetError describes a failed match against the goos/goarch platform.
+func newNoMatchingAssetError(
+	assets []*github.ReleaseAsset,
+	goos, goarch string,
+) *NoMatchingAssetError {
+	names := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		if asset != nil && asset.Name != nil {
+			names = append(names, asset.GetName())
+		}
+	}
+	return &NoMatchingAssetError{OS: goos, Arch: goarch, Assets: names}
+}
+
+func (e *NoMatchingAssetError) Error() string {
+	if len(e.Assets) == 0 {
+		return fmt.Sprintf("no asset matched %s/%s; the release has no assets", e.OS, e.Arch)
+	}
+	return fmt.Sprintf(
+		"no asset matched %s/%s; available assets were: %s",
+		e.OS,
+		e.Arch,
+		strings.Join(e.Assets, ", "),
+	)
+}
+
+// AmbiguousAssetError is returned when different builds match the OS and architecture
+// equally well, e.g. a static and a dynamic one that the tie-break can't decide between,
+// and InstallOptions.ChooseAsset is nil.
+type AmbiguousAssetError struct {
+	OS     string   // Operating system the assets were matched against, as in runtime.GOOS
+	Arch   string   // Architecture the assets were matched against, as in runtime.GOARCH
+	Assets []string // Names of the tied assets, in release order
+}
+
+func (e *AmbiguousAssetError) Error() string {
+	return fmt.Sprintf(
+		"several assets matched %s/%s equally well: %s; choose one with --asset",
+		e.OS,
+		e.Arch,
+		strings.Join(e.Assets, ", "),
+	)
+}
+
+// WeakChecksumError is returned when the checksum file only offers an algorithm that
+// isn't collision-resistant, see utils.IsWeakAlgorithm, and
+// InstallOptions.AllowWeakChecksum isn't set.
+type WeakChecksumError struct {
+	Algorithm string // Algorithm the checksum file calls for
+}
+
+func (e *WeakChecksumError) Error() string {
+	return fmt.Sprintf(
+		"only a %s checksum is available, which doesn't protect against tampering; "+
+			"pass --allow-weak-checksum to install anyway",
+		e.Algorithm,
+	)
+}
+
+// PlanInstall selects the main asset and checksum file with selectMainAndChecksum and
+// determines where the binary is saved according to opts. Nothing is downloaded or
+// written, so dry runs and previews plan exactly what an install would do.
+// Binaries and archives are preferred; a .deb/.rpm/.apk package is only selected
+// when it's the sole match and fits the OS family, see utils.ResolveOSFamily.
+//
+// -assets: The release assets.
+// -tag: The release tag, which a Symlink'ed binary is saved with.
+// -opts: The install options.
+// -matcher: Matches assets built for the platform to install for, see opts.Matcher.
+// Returns: An error if no asset matches the OS/Arch, or if a checksum is required
+// but the release has none.
+func PlanInstall(
+	assets []*github.ReleaseAsset,
+	tag string,
+	opts InstallOptions,
+	matcher *utils.Matcher,
+) (Plan, error) {
+	family := utils.ResolveOSFamily(opts.OSFamily)
+	mainAssetToDownload, checksumAssetToDownload, err := selectMainAndChecksum(
+		assets,
+		tag,
+		opts,
+		family,
+		matcher,
+	)
+	if err != nil {
+		return Plan{}, err
+	}
+	isPackage := !opts.Source && utils.IsSystemPackage(*mainAssetToDownload.Name)
+
+	utils.Logger.Debugf("Selected main asset for download: %s", *mainAssetToDownload.Name)
+	requireChecksum := opts.checksumRequired()
+	switch {
+	case checksumAssetToDownload != nil:
+		utils.Logger.Debugf("Selected checksum file: %s", *checksumAssetToDownload.Name)
+	case requireChecksum:
+		return Plan{}, fmt.Errorf(
+			"no checksum file found for '%s' and a checksum is required (--require-checksum)",
+			*mainAssetToDownload.Name,
+		)
+	default:
+		utils.Logger.Warn(Yellow("No checksum file found. Proceeding without verification."))
+	}
+
+	// Determine Save Path for Main Asset
+	var finalMainAssetSaveName string
+	switch {
+	case isPackage || opts.Source: // Packages are only saved with --download-only
+		finalMainAssetSaveName = filepath.Base(*mainAssetToDownload.Name)
+	case opts.BinName != "": // User specified --binName
+		name, err := utils.ValidateBinName(opts.BinName)
+		if err != nil {
+			return Plan{}, err
+		}
+		finalMainAssetSaveName = name
+	default:
+		// final main asset name (fman)
+		fman := utils.ParseBinaryName(*mainAssetToDownload.Name)
+		finalMainAssetSaveName = fman
+	}
+	if !isPackage && !opts.Source {
+		goos, _ := opts.Platform()
+		finalMainAssetSaveName = utils.ExecutableName(finalMainAssetSaveName, goos)
+	}
+
+	targetMainAssetDir, err := ResolveTargetDir(opts.Path)
+	if err != nil {
+		return Plan{}, err
+	}
+	targetMainAssetSavePath := filepath.Join(targetMainAssetDir, finalMainAssetSaveName)
+	var linkPath string
+	if opts.Symlink && !isPackage && !opts.Source && tag != "" {
+		linkPath = targetMainAssetSavePath
+		targetMainAssetSavePath = utils.VersionedName(linkPath, tag)
+	}
+	utils.Logger.Debugf(
+		"Main asset ('%s') will be saved as: %s",
+		*mainAssetToDownload.Name,
+		targetMainAssetSavePath,
+	)
+
+	return Plan{
+		Main:           mainAssetToDownload,
+		Checksum:       checksumAssetToDownload,
+		Provenance:     selectProvenance(assets, *mainAssetToDownload.Name),
+		SavePath:       targetMainAssetSavePath,
+		LinkPath:       linkPath,
+		IsArchive:      utils.IsArchive(*mainAssetToDownload.Name) && !opts.Source,
+		InstallPackage: isPackage && !opts.DownloadOnly && !opts.CrossTarget(),
+		Family:         family,
+	}, nil
+}
+
+// findDownloadAndVerifyAsset downloads the asset PlanInstall selects among assets,
+// verifies it against the lockfile, the release's checksum file and, when asked, its
+// signature and provenance, then installs it: extracted from an archive, installed as a
+// system package or moved into place as is.
+// Returns: The installed asset.
+func findDownloadAndVerifyAsset( //nolint:gocyclo,funlen
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, tag string,
+	assets []*github.ReleaseAsset,
+	httpClient *http.Client,
+	opts InstallOptions,
+) (Asset, error) {
+	if httpClient == nil {
+		httpClient = ghclient.NewDownloadClient()
+	}
+
+	matcher := opts.Matcher()
+	plan, err := PlanInstall(assets, tag, opts, matcher)
+	if err != nil {
+		return Asset{}, err
+	}
+	mainAssetToDownload := plan.Main
+	checksumAssetToDownload := plan.Checksum
+	requireChecksum := opts.checksumRequired()
+	algorithm := opts.Sha
+	targetMainAssetSavePath := plan.SavePath
+	targetMainAssetDir := filepath.Dir(targetMainAssetSavePath)
+	multiBinary := len(opts.Extract) > 0 || opts.AllBinaries
+	switch {
+	case multiBinary && !plan.IsArchive:
+		return Asset{}, fmt.Errorf(
+			"'%s' isn't an archive; --extract and --all-binaries only apply to archives",
+			*mainAssetToDownload.Name,
+		)
+	case multiBinary, plan.InstallPackage:
+		// The binaries' names are only known once the archive is extracted
+	default:
+		if err := checkOverwrite(targetMainAssetSavePath, opts.Force); err != nil {
+			return Asset{}, err
+		}
+	}
+
+	// Ensure the target directory exists (unless it's current dir or nothing is saved there)
+	if targetMainAssetDir != "." && !plan.InstallPackage {
+		if err := os.MkdirAll(targetMainAssetDir, 0o750); err != nil { //nolint:mnd
+			return Asset{}, fmt.Errorf(
+				"failed to create target directory '%s': %w",
+				targetMainAssetDir,
+				err,
+			)
+		}
+	}
+
+	// Archives and system packages are downloaded to a temporary directory under their
+	// original name; only the binary extracted from an archive ends up in the target
+	// directory. The directory lives in the user's cache directory and is keyed by the
+	// asset ID so an interrupted download can be resumed by the next run; it's kept only
+	// in that case. Other assets are staged next
+	// to the target and only renamed over it once verified, so a failed verification
+	// never removes the binary being replaced.
+	isArchive := plan.IsArchive
+	inTempDir := isArchive || plan.InstallPackage
+	mainAssetDownloadPath := targetMainAssetSavePath + stagedSuffix
+	if !inTempDir {
+		defer os.Remove(mainAssetDownloadPath) //nolint:errcheck // Gone once installed
+	} else {
+		tmpDir, err := resumeDir(opts.CacheDir, owner, repo, mainAssetToDownload.GetID())
+		if err != nil {
+			return Asset{}, err
+		}
+		mainAssetDownloadPath = filepath.Join(tmpDir, filepath.Base(*mainAssetToDownload.Name))
+		defer func() {
+			if _, err := os.Stat(mainAssetDownloadPath + partSuffix); err != nil {
+				os.RemoveAll(tmpDir) //nolint:errcheck,gosec
+			}
+		}()
+	}
+	if err := checkDiskSpace(plan, mainAssetDownloadPath, targetMainAssetDir); err != nil {
+		return Asset{}, err
+	}
+
+	if plan.InstallPackage {
+		// Running the package manager modifies the system; ask before downloading
+		command, err := utils.SystemPackageCommand(mainAssetDownloadPath, plan.Family, opts.Sudo)
+		if err != nil {
+			return Asset{}, err
+		}
+		err = ConfirmSystemPackage(
+			os.Stdin,
+			os.Stderr,
+			IsInteractive(),
+			opts.Yes,
+			mainAssetDownloadPath,
+			command,
+		)
+		if err != nil {
+			return Asset{}, err
+		}
+	}
+
+	// Download Main Asset, hashing it on the way for the lockfile digest
+	digestAlgorithm := LockAlgorithm
+	if opts.Lock != nil {
+		digestAlgorithm = opts.Lock.Algorithm
+	}
+	downloadedMainAssetActualPath, digest, err := downloadAndHashAsset(
+		ctx, client, owner, repo, mainAssetToDownload, httpClient, mainAssetDownloadPath,
+		digestAlgorithm, opts.Progress,
+	)
+	if err != nil {
+		// DownloadAndSaveAsset now includes targetMainAssetSavePath in its error reporting if relevant
+		return Asset{}, fmt.Errorf(
+			"failed to download main asset '%s': %w",
+			*mainAssetToDownload.Name,
+			err,
+		)
+	}
+
+	if digest == "" {
+		// A resumed download was only partly seen by the hasher
+		digest, err = utils.HashFileContext(ctx, downloadedMainAssetActualPath, digestAlgorithm)
+		if err != nil {
+			return Asset{}, fmt.Errorf("failed to hash '%s': %w", *mainAssetToDownload.Name, err)
+		}
+	}
+	mainDigest := AssetDigest{Algorithm: digestAlgorithm, Sum: digest}
+	if opts.Lock != nil && !strings.EqualFold(digest, opts.Lock.Checksum) {
+		return Asset{}, fmt.Errorf(
+			"%s checksum of '%s' no longer matches the lockfile: expected '%s', got '%s'",
+			digestAlgorithm,
+			*mainAssetToDownload.Name,
+			opts.Lock.Checksum,
+			digest,
+		)
+	}
+
+	// Download Checksum File and Verify (if found)
+	var checksumAlgorithm, verifiedChecksum string
+	var binaryChecksumPath string // Checksum file listing the binary inside the archive
+	if checksumAssetToDownload != nil {
+		// The checksum file is downloaded to a temporary directory with its original name,
+		// never to the working directory, so concurrent installs don't share it and it's
+		// removed along with the directory once verification is done
+		checksumDir, err := os.MkdirTemp("", "gh-install-checksum-*")
+		if err != nil {
+			return Asset{}, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(checksumDir) //nolint:errcheck
+		targetChecksumAssetSavePath := filepath.Join(
+			checksumDir,
+			filepath.Base(*checksumAssetToDownload.Name),
+		)
+		utils.Logger.Debugf(
+			"Checksum asset ('%s') will be saved as: %s",
+			*checksumAssetToDownload.Name,
+			targetChecksumAssetSavePath,
+		)
+
+		actualChecksumAssetPath, checksumErr := DownloadAndSaveAsset(
+			ctx,
+			client,
+			owner,
+			repo,
+			checksumAssetToDownload,
+			httpClient,
+			targetChecksumAssetSavePath,
+			opts.Progress,
+		)
+		if checksumErr != nil && requireChecksum {
+			return Asset{}, fmt.Errorf(
+				"failed to download checksum file '%s' and a checksum is required: %w",
+				*checksumAssetToDownload.Name,
+				checksumErr,
+			)
+		}
+		if checksumErr != nil {
+			utils.Logger.Errorf(
+				Red(
+					"Failed to download checksum file '%s': %v. Checksum verification will be SKIPPED.",
+				),
+				*checksumAssetToDownload.Name,
+				checksumErr,
+			)
+			utils.Logger.Warnf(
+				Yellow("Integrity of '%s' (at %s) is NOT confirmed."),
+				*mainAssetToDownload.Name, targetMainAssetSavePath,
+			)
+			// Proceed without verification in this case
+		} else {
+			if len(opts.GPGKeys) > 0 {
+				err := verifyChecksumSignature(
+					ctx,
+					client,
+					owner,
+					repo,
+					assets,
+					checksumAssetToDownload,
+					actualChecksumAssetPath,
+					httpClient,
+					opts.GPGKeys,
+					opts.Progress,
+				)
+				if err != nil {
+					return Asset{}, err
+				}
+			}
+			// Pass the actual path of the (potentially renamed/relocated) main asset
+			// and its original name for checksum lookup
+			algo, sum, verifyErr := VerifyAssetChecksum(
+				ctx,
+				downloadedMainAssetActualPath,
+				*mainAssetToDownload.Name,
+				actualChecksumAssetPath,
+				algorithm,
+				opts.DefaultAlgorithm,
+				opts.AllowWeakChecksum,
+				mainDigest,
+				matcher,
+			)
+			switch {
+			case verifyErr == nil:
+				// Verification successful; actualChecksumAssetPath goes with checksumDir
+				checksumAlgorithm, verifiedChecksum = algo, sum
+			case isArchive && errors.Is(verifyErr, utils.ErrChecksumNotFound):
+				// Some projects checksum the binaries inside their archives instead
+				utils.Logger.Debugf(
+					"'%s' is not listed in '%s'; the extracted binary will be verified instead",
+					*mainAssetToDownload.Name,
+					filepath.Base(actualChecksumAssetPath),
+				)
+				binaryChecksumPath = actualChecksumAssetPath
+			default:
+				// Verification failed; the staged download is removed on return
+				return Asset{}, verifyErr // verifyErr already contains context
+			}
+		}
+	}
+
+	if opts.VerifySignature {
+		err := verifyAssetSignature(
+			ctx,
+			client,
+			owner,
+			repo,
+			assets,
+			mainAssetToDownload,
+			downloadedMainAssetActualPath,
+			httpClient,
+			opts.Progress,
+		)
+		if err != nil {
+			return Asset{}, err
+		}
+	}
+
+	if opts.VerifyProvenance {
+		err := verifyAssetProvenance(
+			ctx,
+			client,
+			owner,
+			repo,
+			tag,
+			plan.Provenance,
+			mainAssetToDownload,
+			downloadedMainAssetActualPath,
+			httpClient,
+			opts.Progress,
+		)
+		if err != nil {
+			return Asset{}, err
+		}
+	}
+
+	var extraBinaries []string
+	switch {
+	case plan.InstallPackage:
+		err := utils.InstallSystemPackage(downloadedMainAssetActualPath, plan.Family, opts.Sudo)
+		if err != nil {
+			return Asset{}, err
+		}
+		utils.Logger.Info(Green("✔") + " Installed system package " + *mainAssetToDownload.Name)
+		downloadedMainAssetActualPath = "" // The package manager decides where files go
+	case isArchive:
+		if opts.KeepArchive != "" {
+			if err := keepArchive(downloadedMainAssetActualPath, opts.KeepArchive); err != nil {
+				return Asset{}, err
+			}
+		}
+		var verifyBinary func(binaryPath string) error
+		if binaryChecksumPath != "" {
+			verifyBinary = func(binaryPath string) error {
+				algo, sum, err := VerifyAssetChecksum(
+					ctx,
+					binaryPath,
+					filepath.Base(binaryPath),
+					binaryChecksumPath,
+					algorithm,
+					opts.DefaultAlgorithm,
+					opts.AllowWeakChecksum,
+					AssetDigest{},
+					matcher,
+					ArchiveStem(*mainAssetToDownload.Name),
+				)
+				if err != nil {
+					return err
+				}
+				checksumAlgorithm, verifiedChecksum = algo, sum
+				return nil
+			}
+		}
+		if multiBinary {
+			installed, err := installBinariesFromArchive(
+				ctx,
+				downloadedMainAssetActualPath,
+				targetMainAssetDir,
+				opts.Extract,
+				opts.Force,
+				verifyBinary,
+			)
+			if err != nil {
+				return Asset{}, err
+			}
+			downloadedMainAssetActualPath, extraBinaries = installed[0], installed[1:]
+			break
+		}
+		err := installFromArchive(
+			ctx,
+			downloadedMainAssetActualPath,
+			repo,
+			opts.BinName,
+			targetMainAssetSavePath,
+			verifyBinary,
+		)
+		if err != nil {
+			return Asset{}, err
+		}
+		downloadedMainAssetActualPath = targetMainAssetSavePath
+	default:
+		if err := os.Rename(downloadedMainAssetActualPath, targetMainAssetSavePath); err != nil {
+			return Asset{}, fmt.Errorf(
+				"failed to move '%s' into place: %w",
+				*mainAssetToDownload.Name,
+				err,
+			)
+		}
+		downloadedMainAssetActualPath = targetMainAssetSavePath
+	}
+
+	return Asset{
+		Name:      *mainAssetToDownload.Name,
+		Path:      downloadedMainAssetActualPath,
+		MIMEType:  mainAssetToDownload.GetContentType(),
+		Checksum:  verifiedChecksum,
+		Algorithm: checksumAlgorithm,
+		Link:      plan.LinkPath,
+		Digest:    digest,
+		URL:       mainAssetToDownload.GetBrowserDownloadURL(),
+		Extra:     extraBinaries,
+	}, nil
+}
+
+const (
+	// diskSpaceHeadroom is kept free on top of what a download needs, as other files
+	// may be written while it runs
+	diskSpaceHeadroom = 10 << 20
+	// archiveExpansion estimates the extracted size of an archive as a multiple of its size
+	archiveExpansion = 3
+)
+
+// resumeDir returns the directory an archive or system package is downloaded to, under
+// the downloads subdirectory of the cache directory. Unlike a directory in the shared
+// temporary directory, its name can't be claimed by another user ahead of time; it's
+// still refused unless only the current user can access it, as a cache directory may be
+// shared.
+//
+// -cacheDir: The cache directory asked for, see InstallOptions.CacheDir; may be empty.
+// -owner, repo: The repository the asset belongs to.
+// -assetID: The ID of the asset, so a download can be resumed by the next run.
+// Returns: The directory, and an error if it can't be created or isn't private.
+func resumeDir(cacheDir, owner, repo string, assetID int64) (string, error) {
+	cachePath, err := ghclient.CachePath(cacheDir)
+	if err != nil {
+		return "", err
+	}
+	downloadsDir := filepath.Join(cachePath, "downloads")
+	if err := os.MkdirAll(downloadsDir, 0o700); err != nil { //nolint:mnd
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	if err := utils.EnsurePrivateDir(downloadsDir); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(downloadsDir, fmt.Sprintf("%s-%s-%d", owner, repo, assetID))
+	if err := utils.EnsurePrivateDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// availableBytes reports the free space of a directory's filesystem; tests stub it.
+var availableBytes = utils.AvailableBytes
+
+// checkDiskSpace fails before downloading when the filesystems the asset, the files
+// extracted from it and the installed binary are written to don't have room for them,
+// rather than halfway through.
+//
+// -plan: The planned install.
+// -downloadPath: Where the asset is downloaded to.
+// -targetDir: Where the binary is installed.
+// Returns: An error naming the directory without enough space.
+func checkDiskSpace(plan Plan, downloadPath, targetDir string) error {
+	size := int64(plan.Main.GetSize())
+	downloadDir := filepath.Dir(downloadPath)
+	need := size
+	if plan.IsArchive {
+		need += size * archiveExpansion
+	}
+	if err := ensureDiskSpace(downloadDir, need); err != nil {
+		return err
+	}
+	if plan.IsArchive && downloadDir != targetDir {
+		return ensureDiskSpace(targetDir, size)
+	}
+	return nil
+}
+
+// ensureDiskSpace checks that dir's filesystem has need bytes plus diskSpaceHeadroom
+// available. The check is skipped when the free space can't be determined.
+func ensureDiskSpace(dir string, need int64) error {
+	available, err := availableBytes(dir)
+	if err != nil {
+		utils.Logger.Debugf("Skipping the disk space check: %v", err)
+		return nil
+	}
+	need += diskSpaceHeadroom
+	if uint64(need) <= available { //nolint:gosec
+		return nil
+	}
+	return fmt.Errorf(
+		"not enough disk space in '%s': %s needed, %s available",
+		dir,
+		utils.HumanBytes(need),
+		utils.HumanBytes(int64(min(available, math.MaxInt64))), //nolint:gosec
+	)
+}
+
+// keepArchive copies a downloaded and verified archive to dir, for KeepArchive,
+// under its original asset name.
+//
+// -archivePath: The archive in the temporary download directory.
+// -dir: The directory to keep the archive in; created if needed.
+// Returns: An error if the archive can't be copied.
+func keepArchive(archivePath, dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil { //nolint:mnd
+		return fmt.Errorf("failed to create archive directory '%s': %w", dir, err)
+	}
+	dst := filepath.Join(dir, filepath.Base(archivePath))
+	if err := utils.CopyFile(archivePath, dst); err != nil {
+		return fmt.Errorf("failed to keep archive: %w", err)
+	}
+	utils.Logger.Infof("📦 Kept archive at %s", dst)
+	return nil
+}
+
+// ArchiveStem returns the archive name without its extension, e.g. "tool_linux_amd64"
+// for "tool_linux_amd64.tar.gz" or "tool_linux_amd64.zst", which is what a release ships
+// the binary inside it as when it also publishes it on its own.
+func ArchiveStem(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	if ext := utils.CompressedExt(name); ext != "" {
+		return name[:len(name)-len(ext)]
+	}
+	return name
+}
+
+// contentTypeAllowed reports whether an asset with contentType may be installed given
+// the --content-type values in allowed. Parameters such as charset are ignored and
+// media types compare case-insensitively.
+//
+// -contentType: The asset's content type as reported by GitHub.
+// -allowed: The accepted media types; any content type is allowed when empty.
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, a := range allowed {
+		if strings.EqualFold(mediaType, strings.TrimSpace(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// findLockedAsset returns the asset named name, which a lockfile pinned.
+func findLockedAsset(assets []*github.ReleaseAsset, name string) (*github.ReleaseAsset, error) {
+	for _, asset := range assets {
+		if asset != nil && asset.GetName() == name && asset.ID != nil {
+			utils.Logger.Debugf("Using locked asset: %s", name)
+			return asset, nil
+		}
+	}
+	return nil, fmt.Errorf("locked asset '%s' is no longer part of the release", name)
+}
+
+// selectAsset returns the asset named name or, when name is empty, the one matching
+// re. Checksum, signature and provenance files are never matched by re.
+// Returns: An error unless exactly one asset matches.
+func selectAsset(
+	assets []*github.ReleaseAsset,
+	name string,
+	re *regexp.Regexp,
+) (*github.ReleaseAsset, error) {
+	var matches []*github.ReleaseAsset
+	for _, asset := range assets {
+		if asset == nil || asset.Name == nil || asset.ID == nil {
+			continue
+		}
+		assetName := asset.GetName()
+		switch {
+		case name != "":
+			if assetName == name {
+				matches = append(matches, asset)
+			}
+		case utils.IsChecksumFile(assetName) || utils.IsSignatureFile(assetName) ||
+			utils.IsMetadataAsset(assetName):
+			continue
+		case re.MatchString(assetName):
+			matches = append(matches, asset)
+		}
+	}
+
+	selector := fmt.Sprintf("--asset '%s'", name)
+	if name == "" {
+		selector = fmt.Sprintf("--asset-regex '%s'", re)
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%s matched none of the release's assets", selector)
+	case 1:
+		utils.Logger.Debugf("Selected asset with %s: %s", selector, matches[0].GetName())
+		return matches[0], nil
+	}
+	names := make([]string, 0, len(matches))
+	for _, asset := range matches {
+		names = append(names, asset.GetName())
+	}
+	return nil, fmt.Errorf("%s matched several assets: %s", selector, strings.Join(names, ", "))
+}
+
+// ResolveTargetDir returns the directory to install into for an InstallOptions.Path of path,
+// expanding a leading "~" and environment variables. Defaults to $XDG_BIN_HOME.
+func ResolveTargetDir(path string) (string, error) {
+	switch path {
+	case "": // Default to XDG Bin Home
+		return xdg.BinHome, nil
+	case ".": // User specified current directory
+		return ".", nil
+	}
+	expanded, err := utils.ExpandPath(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(expanded), nil
+}
+
+// checkOverwrite refuses to replace an existing file at path unless force is set, so a
+// mistyped --binName can't clobber an unrelated binary.
+// Returns: An error describing the existing file, or nil if path is free or force is set.
+func checkOverwrite(path string, force bool) error {
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to check target path '%s': %w", path, err)
+	case force:
+		utils.Logger.Debugf("Overwriting existing '%s' (--force)", path)
+		return nil
+	}
+	return fmt.Errorf(
+		"'%s' already exists (%s, %s, modified %s); pass --force to overwrite it",
+		path,
+		info.Mode(),
+		utils.HumanBytes(info.Size()),
+		info.ModTime().Format(time.DateTime),
+	)
+}
+
+// checksumRequired reports whether the install must fail when no checksum can be
+// verified, as requested by RequireChecksum or GH_INSTALL_REQUIRE_CHECKSUM.
+func (o InstallOptions) checksumRequired() bool {
+	if o.RequireChecksum {
+		return true
+	}
+	required, _ := strconv.ParseBool(os.Getenv(RequireChecksumEnv))
+	return required
+}
+
+// installFromArchive extracts archivePath next to itself, locates the binary
+// and moves it to targetPath. The binary is looked up by --binName first and then
+// by the repository name. When verify is non-nil it's called with the extracted
+// binary before it's moved, and an error from it aborts the install.
+func installFromArchive(
+	ctx context.Context,
+	archivePath, repo, binName, targetPath string,
+	verify func(binaryPath string) error,
+) error {
+	var preferredNames []string
+	if binName != "" {
+		preferredNames = append(preferredNames, binName)
+	}
+	preferredNames = append(preferredNames, repo)
+
+	extractDir, err := extractNextTo(ctx, archivePath, preferredNames[0])
+	if err != nil {
+		return err
+	}
+
+	var binaryPath string
+	for _, name := range preferredNames {
+		binaryPath, err = utils.FindBinaryInDir(extractDir, name)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to locate binary in '%s': %w", filepath.Base(archivePath), err)
+	}
+
+	if verify != nil {
+		if err := verify(binaryPath); err != nil {
+			return fmt.Errorf("failed to verify extracted binary '%s': %w", filepath.Base(binaryPath), err)
+		}
+	}
+
+	utils.Logger.Debugf("Moving extracted binary '%s' to '%s'", binaryPath, targetPath)
+	if err := utils.MoveFile(binaryPath, targetPath); err != nil {
+		return fmt.Errorf("failed to install '%s' to '%s': %w", binaryPath, targetPath, err)
+	}
+	utils.Logger.Info(Green("✔") + " Extracted " + filepath.Base(binaryPath))
+	return nil
+}
+
+// installBinariesFromArchive extracts archivePath next to itself and moves the binaries
+// named by names, or every executable in it when names is empty, into targetDir under
+// their own names. Nothing is moved if one of them would overwrite an existing file and
+// force isn't set. When verify is non-nil it's called with each binary before it's moved.
+// Returns: The paths the binaries were installed to, in the order of names.
+func installBinariesFromArchive(
+	ctx context.Context,
+	archivePath, targetDir string,
+	names []string,
+	force bool,
+	verify func(binaryPath string) error,
+) ([]string, error) {
+	extractDir, err := extractNextTo(ctx, archivePath, "")
+	if err != nil {
+		return nil, err
+	}
+	binaries, err := utils.FindBinariesInDir(extractDir, names)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to locate binaries in '%s': %w",
+			filepath.Base(archivePath),
+			err,
+		)
+	}
+
+	targets := make([]string, 0, len(binaries))
+	seen := make(map[string]string, len(binaries))
+	for _, binaryPath := range binaries {
+		name := filepath.Base(binaryPath)
+		if other, dup := seen[name]; dup {
+			return nil, fmt.Errorf(
+				"'%s' and '%s' would both be installed as '%s'",
+				other,
+				binaryPath,
+				name,
+			)
+		}
+		seen[name] = binaryPath
+		target := filepath.Join(targetDir, name)
+		if err := checkOverwrite(target, force); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	for i, binaryPath := range binaries {
+		if verify != nil {
+			if err := verify(binaryPath); err != nil {
+				return nil, fmt.Errorf(
+					"failed to verify extracted binary '%s': %w",
+					filepath.Base(binaryPath),
+					err,
+				)
+			}
+		}
+		utils.Logger.Debugf("Moving extracted binary '%s' to '%s'", binaryPath, targets[i])
+		if err := utils.MoveFile(binaryPath, targets[i]); err != nil {
+			return nil, fmt.Errorf(
+				"failed to install '%s' to '%s': %w",
+				binaryPath,
+				targets[i],
+				err,
+			)
+		}
+		utils.Logger.Info(Green("✔") + " Extracted " + filepath.Base(binaryPath))
+	}
+	return targets, nil
+}
+
+// extractNextTo extracts archivePath into an "extracted" directory next to it. A single
+// compressed file such as "tool_linux_amd64.zst" is decompressed to name there, e.g. the
+// repository name or --binName, or to its name without the extension when name is empty.
+// Cancelling ctx stops an external decompression tool.
+// Returns: The directory the archive was extracted into.
+func extractNextTo(ctx context.Context, archivePath, name string) (string, error) {
+	extractDir := filepath.Join(filepath.Dir(archivePath), "extracted")
+	utils.Logger.Debugf("Extracting '%s' to '%s'", archivePath, extractDir)
+	_ = os.RemoveAll(extractDir) // Start clean in case an earlier run was interrupted
+
+	var err error
+	if name != "" && utils.IsCompressedFile(archivePath) {
+		err = utils.DecompressFile(ctx, archivePath, filepath.Join(extractDir, name))
+	} else {
+		_, err = utils.ExtractArchive(ctx, archivePath, extractDir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to extract '%s': %w", filepath.Base(archivePath), err)
+	}
+	return extractDir, nil
+}
+
+// AssetDigest is a checksum computed while an asset was downloaded.
+type AssetDigest struct {
+	Algorithm string // Algorithm the asset was hashed with
+	Sum       string // Hex-encoded checksum; empty if unknown
+}
+
+// VerifyAssetChecksum hashes the asset at mainAssetDiskPath and compares it against the entry
+// for mainAssetOriginalName in the checksum file. On success it returns the algorithm used
+// and the verified checksum. The file isn't read again when known was computed with the
+// algorithm the checksum file calls for. When the checksum file doesn't list
+// mainAssetOriginalName, the entry for one of alternatives or a similar name is used
+// instead, see utils.ResolveChecksumName, whose matcher is the platform the asset was
+// built for. defaultAlgo is the algorithm of a checksum file whose name doesn't tell it,
+// as InstallOptions.DefaultAlgorithm. Verifying with md5, sha1 or crc32 fails with a
+// *WeakChecksumError unless allowWeak is set. Hashing stops when ctx is cancelled.
+func VerifyAssetChecksum(
+	ctx context.Context,
+	mainAssetDiskPath, mainAssetOriginalName, checksumAssetPath, sha, defaultAlgo string,
+	allowWeak bool,
+	known AssetDigest,
+	matcher *utils.Matcher,
+	alternatives ...string,
+) (algorithm, checksum string, err error) {
+	utils.Logger.Debug("Verifying checksum...")
+	lookupName, err := utils.ResolveChecksumName(
+		checksumAssetPath,
+		mainAssetOriginalName,
+		matcher,
+		alternatives...,
+	)
+	if err != nil {
+		return "", "", err
+	}
+	if lookupName != mainAssetOriginalName {
+		utils.Logger.Infof(
+			"'%s' isn't listed in '%s'; verifying it against the entry for '%s'",
+			mainAssetOriginalName,
+			filepath.Base(checksumAssetPath),
+			lookupName,
+		)
+	}
+	var algoToUse string
+	var expectedChecksum string
+
+	// Manifests listing several algorithms per asset are verified with the strongest one
+	// (or the one given with --sha); plain manifests fall through to the logic below.
+	var annotatedAlgo, annotatedChecksum string
+	var annotated bool
+	sums, multiErr := utils.ParseChecksumFileMulti(checksumAssetPath, lookupName)
+	if multiErr == nil {
+		annotatedAlgo, annotatedChecksum, annotated = utils.StrongestChecksum(sums, sha)
+	}
+
+	switch {
+	case annotated:
+		algoToUse, expectedChecksum = annotatedAlgo, annotatedChecksum
+		utils.Logger.Debugf("Using algorithm '%s' annotated in checksum file.", algoToUse)
+	case sha != "":
+		// User specified an algorithm
+		algoToUse = sha
+		utils.Logger.Debugf("Using specified algorithm '%s' from --sha flag.", algoToUse)
+		expectedChecksum, err = utils.ParseChecksumFile(checksumAssetPath, lookupName)
+		if err != nil {
+			return "", "", fmt.Errorf(
+				"failed to parse checksum file '%s' for target '%s' (using --sha=%s): %w",
+				checksumAssetPath,
+				mainAssetOriginalName,
+				sha,
+				err,
+			)
+		}
+	default:
+		// Get the expected checksum first: without a hint in the file name, its length
+		// tells the algorithm better than assuming the default.
+		// The actual hashing and comparison will be done once outside this switch.
+		expectedChecksum, err = utils.ParseChecksumFile(checksumAssetPath, lookupName)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to parse checksum file '%s' for target '%s': %w",
+				checksumAssetPath, mainAssetOriginalName, err)
+		}
+
+		var determinedAlgoFromExtOrGeneric string
+		algoFromExt, found := utils.GetAlgorithmFromFilename(checksumAssetPath)
+		guessedAlgo, guessed := utils.GuessAlgorithmFromHashLength(expectedChecksum)
+		switch {
+		case found:
+			determinedAlgoFromExtOrGeneric = algoFromExt
+			utils.Logger.Debugf("Using algorithm '%s' derived from checksum file extension: %s", determinedAlgoFromExtOrGeneric, checksumAssetPath)
+		case defaultAlgo != "":
+			determinedAlgoFromExtOrGeneric = defaultAlgo
+			utils.Logger.Debugf(
+				"Checksum file '%s' has no algorithm extension. Using '%s' from --default-algo",
+				checksumAssetPath,
+				determinedAlgoFromExtOrGeneric,
+			)
+		case guessed:
+			determinedAlgoFromExtOrGeneric = guessedAlgo
+			utils.Logger.Debugf(
+				"Checksum file '%s' has no algorithm extension; '%s' fits the checksum's length",
+				checksumAssetPath,
+				determinedAlgoFromExtOrGeneric,
+			)
+		default:
+			determinedAlgoFromExtOrGeneric = utils.DefaultAlgorithmForGenericChecksums
+			utils.Logger.Debugf("Checksum file '%s' has no algorithm extension. Using default/hint: '%s'", checksumAssetPath, determinedAlgoFromExtOrGeneric)
+		}
+
+		// Ensure determined algo is supported
+		if _, err := utils.GetHasher(determinedAlgoFromExtOrGeneric); err != nil {
+			return "", "", fmt.Errorf("algorithm '%s' (derived or default) is not supported: %w", determinedAlgoFromExtOrGeneric, err)
+		}
+		algoToUse = determinedAlgoFromExtOrGeneric
+	}
+
+	if utils.IsWeakAlgorithm(algoToUse) {
+		if !allowWeak {
+			return "", "", &WeakChecksumError{Algorithm: algoToUse}
+		}
+		utils.Logger.Warnf(
+			Yellow("Verifying with %s, which isn't collision-resistant: the checksum only "+
+				"guards against a corrupted download, not a tampered one."),
+			algoToUse,
+		)
+	}
+
+	utils.Logger.Debugf(
+		"Calculating %s checksum for local asset: %s",
+		strings.ToUpper(algoToUse),
+		mainAssetDiskPath,
+	)
+	actualChecksum := known.Sum
+	if actualChecksum == "" || !strings.EqualFold(known.Algorithm, algoToUse) {
+		actualChecksum, err = utils.HashFileContext(ctx, mainAssetDiskPath, algoToUse)
+		if err != nil {
+			return "", "", fmt.Errorf(
+				"failed to calculate actual checksum for asset '%s' using %s: %w",
+				mainAssetDiskPath, algoToUse, err,
+			)
+		}
+	} else {
+		utils.Logger.Debugf("Using the %s checksum computed during the download", algoToUse)
+	}
+
+	if !strings.EqualFold(expectedChecksum, actualChecksum) {
+		return "", "", fmt.Errorf(
+			"checksum mismatch for asset '%s' (original name '%s') using algorithm '%s': expected '%s', got '%s'",
+			mainAssetDiskPath,
+			mainAssetOriginalName,
+			algoToUse,
+			expectedChecksum,
+			actualChecksum,
+		)
+	}
+
+	utils.Logger.Debugf(
+		Green("✔")+" Checksum VALID for '%s' (original name: '%s') using algorithm %s.",
+		mainAssetDiskPath,
+		mainAssetOriginalName,
+		algoToUse,
+	)
+	utils.Logger.Info(Green("✔") + " Checksum verified!")
+	return algoToUse, actualChecksum, nil
+}
@@ -0,0 +1,1690 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/ghclient"
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/state"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func TestMain(m *testing.M) {
+	// The gh install command creates the logger on startup; tests of the package
+	// need one as well
+	utils.CreateLogger(false)
+	os.Exit(m.Run())
+}
+
+func Test_findDownloadAndVerifyAsset_requireChecksum(t *testing.T) {
+	utils.GetOSArch()
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(assetName), ID: github.Ptr(int64(1)), Size: github.Ptr(1)},
+	}
+
+	tests := []struct {
+		name string
+		flag bool
+		env  string
+	}{
+		{name: "flag", flag: true},
+		{name: "environment", env: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RequireChecksumEnv, tt.env)
+
+			// The client is never used: the install must abort before downloading anything
+			_, err := findDownloadAndVerifyAsset(
+				context.Background(), nil, "owner", "tool", "v1.0.0", assets, nil,
+				InstallOptions{Path: t.TempDir(), RequireChecksum: tt.flag},
+			)
+			if err == nil || !strings.Contains(err.Error(), "checksum is required") {
+				t.Errorf("findDownloadAndVerifyAsset() error = %v, want checksum required error", err)
+			}
+		})
+	}
+}
+
+func TestInstallOptions_checksumRequired(t *testing.T) {
+	t.Setenv(RequireChecksumEnv, "")
+	if (InstallOptions{}).checksumRequired() {
+		t.Error("checksumRequired() = true by default, want false")
+	}
+	if !(InstallOptions{RequireChecksum: true}).checksumRequired() {
+		t.Error("checksumRequired() = false with RequireChecksum, want true")
+	}
+	t.Setenv(RequireChecksumEnv, "1")
+	if !(InstallOptions{}).checksumRequired() {
+		t.Errorf("checksumRequired() = false with %s=1, want true", RequireChecksumEnv)
+	}
+}
+
+func TestInstallOptions_Matcher_noRosettaFallback(t *testing.T) {
+	const asset = "tool_darwin_amd64.tar.gz"
+	withFallback := InstallOptions{OS: "darwin", Arch: "arm64"}
+	withoutFallback := InstallOptions{OS: "darwin", Arch: "arm64", NoRosettaFallback: true}
+
+	// Options of concurrent installs don't affect each other's matchers
+	if !withFallback.Matcher().MatchFallback(asset) {
+		t.Errorf("Matcher().MatchFallback(%q) = false, want true", asset)
+	}
+	if withoutFallback.Matcher().MatchFallback(asset) {
+		t.Errorf("Matcher().MatchFallback(%q) with NoRosettaFallback = true, want false", asset)
+	}
+	if !withFallback.Matcher().MatchFallback(asset) {
+		t.Errorf("Matcher().MatchFallback(%q) = false after NoRosettaFallback, want true", asset)
+	}
+}
+
+func TestVerifyAssetChecksum_cancelled(t *testing.T) {
+	dir := t.TempDir()
+	assetPath := filepath.Join(dir, "tool.tar.gz")
+	if err := os.WriteFile(assetPath, []byte("tool"), 0o600); err != nil {
+		t.Fatalf("Failed to write asset: %v", err)
+	}
+	sum, err := utils.HashFile(assetPath, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash asset: %v", err)
+	}
+	checksumPath := filepath.Join(dir, "checksums.txt")
+	content := fmt.Sprintf("%s  tool.tar.gz\n", sum)
+	if err := os.WriteFile(checksumPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write checksum file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = VerifyAssetChecksum(
+		ctx, assetPath, "tool.tar.gz", checksumPath, "", "", false, AssetDigest{},
+		InstallOptions{}.Matcher(),
+	)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("VerifyAssetChecksum() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestVerifyAssetChecksum_multiAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	assetPath := filepath.Join(dir, "tool.tar.gz")
+	if err := os.WriteFile(assetPath, []byte("tool"), 0o600); err != nil {
+		t.Fatalf("Failed to write asset: %v", err)
+	}
+	sha256Sum, err := utils.HashFile(assetPath, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash asset: %v", err)
+	}
+	md5Sum, err := utils.HashFile(assetPath, "md5")
+	if err != nil {
+		t.Fatalf("Failed to hash asset: %v", err)
+	}
+
+	checksumPath := filepath.Join(dir, "CHECKSUMS")
+	content := fmt.Sprintf("MD5 (tool.tar.gz) = %s\nSHA256 (tool.tar.gz) = %s\n", md5Sum, sha256Sum)
+	if err := os.WriteFile(checksumPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write checksum file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		sha       string
+		allowWeak bool
+		wantAlgo  string
+		wantSum   string
+		wantErr   bool
+	}{
+		{name: "strongest algorithm", wantAlgo: "sha256", wantSum: sha256Sum},
+		{
+			name:      "--sha selects algorithm",
+			sha:       "md5",
+			allowWeak: true,
+			wantAlgo:  "md5",
+			wantSum:   md5Sum,
+		},
+		{name: "--sha selects weak algorithm", sha: "md5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algo, sum, err := VerifyAssetChecksum(
+				context.Background(),
+				assetPath, "tool.tar.gz", checksumPath, tt.sha, "", tt.allowWeak, AssetDigest{},
+				InstallOptions{}.Matcher(),
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyAssetChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if algo != tt.wantAlgo || sum != tt.wantSum {
+				t.Errorf("VerifyAssetChecksum() = (%q, %q), want (%q, %q)", algo, sum, tt.wantAlgo, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestVerifyAssetChecksum_genericChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	assetPath := filepath.Join(dir, "tool.tar.gz")
+	if err := os.WriteFile(assetPath, []byte("tool"), 0o600); err != nil {
+		t.Fatalf("Failed to write asset: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		algo        string // Algorithm of the checksums in checksums.txt
+		defaultAlgo string
+		wantErr     bool
+	}{
+		{name: "md5 by length", algo: "md5"},
+		{name: "sha1 by length", algo: "sha1"},
+		{name: "sha256 by length", algo: "sha256"},
+		{name: "sha512 by length", algo: "sha512"},
+		{name: "sha512 with --default-algo", algo: "sha512", defaultAlgo: "sha512"},
+		// SHA3-512 checksums are as long as SHA-512 ones
+		{name: "sha3-512 taken for sha512", algo: "sha3-512", wantErr: true},
+		{name: "sha3-512 with --default-algo", algo: "sha3-512", defaultAlgo: "sha3-512"},
+		{name: "wrong --default-algo", algo: "sha512", defaultAlgo: "sha256", wantErr: true},
+		{name: "unsupported --default-algo", algo: "sha512", defaultAlgo: "xxh3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, err := utils.HashFile(assetPath, tt.algo)
+			if err != nil {
+				t.Fatalf("Failed to hash asset: %v", err)
+			}
+			checksumPath := filepath.Join(t.TempDir(), "checksums.txt")
+			content := fmt.Sprintf("%s  tool.tar.gz\n", sum)
+			if err := os.WriteFile(checksumPath, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write checksum file: %v", err)
+			}
+
+			gotAlgo, gotSum, err := VerifyAssetChecksum(
+				context.Background(),
+				assetPath, "tool.tar.gz", checksumPath, "", tt.defaultAlgo, true, AssetDigest{},
+				InstallOptions{}.Matcher(),
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyAssetChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (gotAlgo != tt.algo || gotSum != sum) {
+				t.Errorf("VerifyAssetChecksum() = (%q, %q), want (%q, %q)", gotAlgo, gotSum,
+					tt.algo, sum)
+			}
+		})
+	}
+}
+
+func TestVerifyAssetChecksum_bareBinary(t *testing.T) {
+	utils.GetOSArch()
+	platform := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	dir := t.TempDir()
+	binaryPath := filepath.Join(dir, "tool")
+	if err := os.WriteFile(binaryPath, []byte("tool binary"), 0o600); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	sum, err := utils.HashFile(binaryPath, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash binary: %v", err)
+	}
+	archiveListed := "0000  tool_1.0_" + platform + ".tar.gz\n"
+	bothListed := archiveListed + sum + "  tool_1.0_" + platform + "\n"
+
+	tests := []struct {
+		name         string
+		content      string
+		alternatives []string
+		wantErr      error
+	}{
+		{
+			name:    "only the archive is listed",
+			content: archiveListed,
+			wantErr: utils.ErrChecksumNotFound,
+		},
+		{
+			name:         "binary listed under the archive's name",
+			content:      bothListed,
+			alternatives: []string{"tool_1.0_" + platform},
+		},
+		{
+			name:    "binary listed with its platform",
+			content: bothListed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksumPath := filepath.Join(t.TempDir(), "checksums.txt")
+			if err := os.WriteFile(checksumPath, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write checksum file: %v", err)
+			}
+			_, got, err := VerifyAssetChecksum(
+				context.Background(),
+				binaryPath, "tool", checksumPath, "", "", false, AssetDigest{},
+				InstallOptions{}.Matcher(), tt.alternatives...,
+			)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyAssetChecksum() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyAssetChecksum() error = %v", err)
+			}
+			if got != sum {
+				t.Errorf("VerifyAssetChecksum() checksum = %q, want %q", got, sum)
+			}
+		})
+	}
+}
+
+func TestArchiveStem(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "tool_linux_amd64.tar.gz", want: "tool_linux_amd64"},
+		{name: "tool_linux_amd64.TGZ", want: "tool_linux_amd64"},
+		{name: "tool_windows_amd64.zip", want: "tool_windows_amd64"},
+		{name: "tool_linux_amd64.zst", want: "tool_linux_amd64"},
+		{name: "tool_linux_amd64.tar.zst", want: "tool_linux_amd64.tar.zst"},
+		{name: "tool", want: "tool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ArchiveStem(tt.name); got != tt.want {
+				t.Errorf("ArchiveStem(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resumeDir(t *testing.T) {
+	cacheDir := t.TempDir()
+	cachePath, err := ghclient.CachePath(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := resumeDir(cacheDir, "owner", "repo", 42)
+	if err != nil {
+		t.Fatalf("resumeDir() error = %v", err)
+	}
+	want := filepath.Join(cachePath, "downloads", "owner-repo-42")
+	if dir != want {
+		t.Errorf("resumeDir() = %s, want %s", dir, want)
+	}
+	// The next run resumes in the same directory
+	if again, err := resumeDir(cacheDir, "owner", "repo", 42); err != nil || again != dir {
+		t.Errorf("resumeDir() again = (%s, %v), want (%s, nil)", again, err, dir)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err := os.Chmod(dir, 0o777); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	if _, err := resumeDir(cacheDir, "owner", "repo", 42); err == nil {
+		t.Error("resumeDir() accepted a directory other users can write to")
+	}
+}
+
+func Test_checkDiskSpace(t *testing.T) {
+	t.Cleanup(func() { availableBytes = utils.AvailableBytes })
+	const mib = 1 << 20
+	tmpDir, binDir := filepath.Join("tmp", "dl"), "bin"
+
+	tests := []struct {
+		name      string
+		asset     string
+		size      int
+		available map[string]uint64 // Free space per directory; missing means unknown
+		wantErr   string
+	}{
+		{
+			name:      "enough space",
+			asset:     "tool",
+			size:      50 * mib,
+			available: map[string]uint64{binDir: 100 * mib},
+		},
+		{
+			name:      "binary doesn't fit",
+			asset:     "tool",
+			size:      95 * mib,
+			available: map[string]uint64{binDir: 100 * mib},
+			wantErr:   "not enough disk space in 'bin'",
+		},
+		{
+			name:      "archive needs room to extract",
+			asset:     "tool.tar.gz",
+			size:      20 * mib,
+			available: map[string]uint64{tmpDir: 60 * mib, binDir: 100 * mib},
+			wantErr:   "not enough disk space in '" + tmpDir + "'",
+		},
+		{
+			name:      "extracted binary doesn't fit",
+			asset:     "tool.tar.gz",
+			size:      20 * mib,
+			available: map[string]uint64{tmpDir: 200 * mib, binDir: 25 * mib},
+			wantErr:   "not enough disk space in 'bin'",
+		},
+		{name: "unknown free space", asset: "tool", size: 95 * mib},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			availableBytes = func(dir string) (uint64, error) {
+				if available, ok := tt.available[dir]; ok {
+					return available, nil
+				}
+				return 0, utils.ErrDiskSpaceUnknown
+			}
+			plan := Plan{
+				Main: &github.ReleaseAsset{
+					Name: github.Ptr(tt.asset),
+					Size: github.Ptr(tt.size),
+				},
+				IsArchive: utils.IsArchive(tt.asset),
+			}
+			downloadDir := binDir
+			if plan.IsArchive {
+				downloadDir = tmpDir
+			}
+
+			err := checkDiskSpace(plan, filepath.Join(downloadDir, tt.asset), binDir)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("checkDiskSpace() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("checkDiskSpace() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestReleaseClient returns a client for a server with release v1.0.0 of owner/tool,
+// holding an archive of binary for the current platform and its checksum file.
+// Returns: The client and the archive's sha256 checksum.
+func newTestReleaseClient(t *testing.T, binary []byte) (*github.Client, string) {
+	t.Helper()
+	archive := testutil.TarGz(t, testutil.TarEntry{Name: "tool", Body: string(binary), Mode: 0o755})
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(archive)
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), assetName)
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[
+		{"id":1,"name":%q,"size":%d,"content_type":"application/gzip"},
+		{"id":2,"name":"checksums.txt","size":%d}
+	]}`, assetName, len(archive), len(checksums))
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/tool/releases/tags/v1.0.0":
+				fmt.Fprint(w, release) //nolint:errcheck
+			case "/repos/owner/tool/releases/assets/1":
+				_, _ = w.Write(archive)
+			case "/repos/owner/tool/releases/assets/2":
+				fmt.Fprint(w, checksums) //nolint:errcheck
+			default:
+				t.Errorf("unexpected request: %s", r.URL.Path)
+				http.NotFound(w, r)
+			}
+		}),
+	)
+	return client, hex.EncodeToString(sum[:])
+}
+
+func TestInstall_client(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	binary := []byte("#!/bin/sh\necho tool\n")
+	client, sum := newTestReleaseClient(t, binary)
+	targetDir := t.TempDir()
+	asset, err := Install(context.Background(), InstallOptions{
+		Owner:           "owner",
+		Repo:            "tool",
+		Version:         "v1.0.0",
+		Path:            targetDir,
+		RequireChecksum: true,
+		Record:          true,
+		Client:          client,
+	})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if asset.Checksum != sum || asset.Algorithm != "sha256" {
+		t.Errorf("Install() verified %s %s, want the archive's sha256", asset.Algorithm,
+			asset.Checksum)
+	}
+	want := filepath.Join(targetDir, utils.ExecutableName("tool", runtime.GOOS))
+	if got, err := os.ReadFile(want); err != nil || !bytes.Equal(got, binary) {
+		t.Errorf("installed binary = %q, %v; want the archive's", got, err)
+	}
+	record, ok, err := state.Find("owner", "tool")
+	if err != nil || !ok || record.Tag != "v1.0.0" || record.Path != want {
+		t.Errorf("state.Find() = %+v, %t, %v; want v1.0.0 at %s", record, ok, err, want)
+	}
+}
+
+func TestInstall_notRecorded(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	client, _ := newTestReleaseClient(t, []byte("#!/bin/sh\necho tool\n"))
+	_, err := Install(context.Background(), InstallOptions{
+		Owner:   "owner",
+		Repo:    "tool",
+		Version: "v1.0.0",
+		Path:    t.TempDir(),
+		Client:  client,
+	})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if record, ok, err := state.Find("owner", "tool"); err != nil || ok {
+		t.Errorf("state.Find() = %+v, %t, %v; want nothing recorded without Record", record,
+			ok, err)
+	}
+}
+
+func TestInstall_failedHookIsRecorded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("post-install hooks run with sh")
+	}
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	client, _ := newTestReleaseClient(t, []byte("#!/bin/sh\necho tool\n"))
+	targetDir := t.TempDir()
+	_, err := Install(context.Background(), InstallOptions{
+		Owner:       "owner",
+		Repo:        "tool",
+		Version:     "v1.0.0",
+		Path:        targetDir,
+		PostInstall: "exit 3",
+		Record:      true,
+		Client:      client,
+	})
+	if err == nil || !strings.Contains(err.Error(), "post-install hook") {
+		t.Fatalf("Install() error = %v, want the failed hook", err)
+	}
+	want := filepath.Join(targetDir, "tool")
+	record, ok, err := state.Find("owner", "tool")
+	if err != nil || !ok || record.Path != want {
+		t.Errorf("state.Find() = %+v, %t, %v; want the install at %s recorded", record, ok,
+			err, want)
+	}
+}
+
+func TestInstall_dryRun(t *testing.T) {
+	utils.GetOSArch()
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[
+		{"id":1,"name":%q,"size":1234,"content_type":"application/gzip"},
+		{"id":2,"name":"checksums.txt","size":10}
+	]}`, assetName)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/releases/latest") {
+				t.Errorf("unexpected request during dry run: %s", r.URL.Path)
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, release) //nolint:errcheck
+		}),
+	)
+
+	var out bytes.Buffer
+	targetDir := filepath.Join(t.TempDir(), "bin")
+	asset, err := Install(context.Background(), InstallOptions{
+		Owner:  "owner",
+		Repo:   "tool",
+		Path:   targetDir,
+		DryRun: true,
+		Out:    &out,
+		Client: client,
+	})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if want := filepath.Join(targetDir, "tool"); asset.Path != want {
+		t.Errorf("Install() path = %q, want %q", asset.Path, want)
+	}
+	for _, want := range []string{"v1.0.0", assetName, "1234 bytes", asset.Path, "checksums.txt"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output is missing %q:\n%s", want, out.String())
+		}
+	}
+	if _, err := os.Stat(targetDir); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be created, stat error = %v", targetDir, err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	utils.GetOSArch()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	binary := []byte("#!/bin/sh\necho tool\n")
+	assetName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[
+		{"id":1,"name":%q,"size":%d,"content_type":"application/octet-stream"}
+	]}`, assetName, len(binary))
+	var downloads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/repos/owner/tool/releases/latest"):
+			fmt.Fprint(w, release) //nolint:errcheck
+		case strings.HasSuffix(r.URL.Path, "/releases/assets/1"):
+			downloads++
+			_, _ = w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GH_INSTALL_TOKEN", "test-token")
+
+	tests := []struct {
+		name          string
+		opts          InstallOptions
+		wantErr       bool
+		wantDownloads int
+	}{
+		{
+			name:          "installs the latest release",
+			opts:          InstallOptions{Owner: "owner", Repo: "tool"},
+			wantDownloads: 1,
+		},
+		{
+			name: "dry run",
+			opts: InstallOptions{Owner: "owner", Repo: "tool", DryRun: true},
+		},
+		{
+			name:    "missing repository",
+			opts:    InstallOptions{Owner: "owner"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloads = 0
+			tt.opts.Path = t.TempDir()
+			tt.opts.NoCache = true
+			tt.opts.APIURL = server.URL + "/"
+
+			asset, err := Install(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Install() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if downloads != tt.wantDownloads {
+				t.Errorf("Install() downloaded %d times, want %d", downloads, tt.wantDownloads)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := filepath.Join(tt.opts.Path, utils.ExecutableName("tool", runtime.GOOS))
+			if asset.Path != want || asset.Tag != "v1.0.0" {
+				t.Errorf("Install() = (%q, %q), want (%q, v1.0.0)", asset.Path, asset.Tag, want)
+			}
+			_, err = os.Stat(asset.Path)
+			if installed := err == nil; installed == tt.opts.DryRun {
+				t.Errorf("Install() installed = %t with DryRun %t", installed, tt.opts.DryRun)
+			}
+		})
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_checksumNotInWorkingDir(t *testing.T) {
+	utils.GetOSArch()
+	binary := []byte("#!/bin/sh\necho tool\n")
+	assetName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	binaryPath := filepath.Join(t.TempDir(), assetName)
+	if err := os.WriteFile(binaryPath, binary, 0o600); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	sum, err := utils.HashFile(binaryPath, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash binary: %v", err)
+	}
+	checksums := []byte(fmt.Sprintf("%s  %s\n", sum, assetName))
+
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/assets/1"):
+				_, _ = w.Write(binary)
+			case strings.HasSuffix(r.URL.Path, "/assets/2"):
+				_, _ = w.Write(checksums)
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+	assets := []*github.ReleaseAsset{
+		{
+			Name:        github.Ptr(assetName),
+			ID:          github.Ptr(int64(1)),
+			Size:        github.Ptr(len(binary)),
+			ContentType: github.Ptr("application/octet-stream"),
+		},
+		{
+			Name: github.Ptr("checksums.txt"),
+			ID:   github.Ptr(int64(2)),
+			Size: github.Ptr(len(checksums)),
+		},
+	}
+
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	targetDir := t.TempDir()
+
+	asset, err := findDownloadAndVerifyAsset(
+		context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+		InstallOptions{Path: targetDir},
+	)
+	if err != nil {
+		t.Fatalf("findDownloadAndVerifyAsset() error = %v", err)
+	}
+	if asset.Checksum != sum {
+		t.Errorf("findDownloadAndVerifyAsset() checksum = %q, want %q", asset.Checksum, sum)
+	}
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		t.Fatalf("Failed to read working directory: %v", err)
+	}
+	for _, e := range entries {
+		t.Errorf("unexpected file left in working directory: %s", e.Name())
+	}
+}
+
+func TestPlanInstall_crossTarget(t *testing.T) {
+	opts := InstallOptions{Path: t.TempDir(), OS: "windows", Arch: "arm64"}
+
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr("tool_1.0.0_linux_amd64.tar.gz"), ID: github.Ptr(int64(1))},
+		{Name: github.Ptr("tool_1.0.0_windows_amd64.zip"), ID: github.Ptr(int64(2))},
+		{Name: github.Ptr("tool_1.0.0_windows_arm64.zip"), ID: github.Ptr(int64(3))},
+		{Name: github.Ptr("tool_1.0.0_arm64.deb"), ID: github.Ptr(int64(4))},
+	}
+	plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if got := plan.Main.GetName(); got != "tool_1.0.0_windows_arm64.zip" {
+		t.Errorf("PlanInstall() selected %q, want the windows/arm64 asset", got)
+	}
+	if got := filepath.Base(plan.SavePath); got != "tool.exe" {
+		t.Errorf("PlanInstall() save name = %q, want %q", got, "tool.exe")
+	}
+	if !opts.CrossTarget() {
+		t.Errorf("crossTarget() = false for windows/arm64 on %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	// A package for another machine is never installed on this one
+	plan, err = PlanInstall(assets[3:], "v1.0.0", opts, opts.Matcher())
+	if err == nil && plan.InstallPackage {
+		t.Errorf("PlanInstall() InstallPackage = true when cross-targeting")
+	}
+}
+
+func TestPlanInstall_prefersBinaryOverPackage(t *testing.T) {
+	utils.GetOSArch()
+	archive := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(fmt.Sprintf("tool_1.0.0_%s.deb", runtime.GOARCH)), ID: github.Ptr(int64(1))},
+		{Name: github.Ptr(fmt.Sprintf("tool-1.0.0.%s.rpm", runtime.GOARCH)), ID: github.Ptr(int64(2))},
+		{Name: github.Ptr(archive), ID: github.Ptr(int64(3))},
+	}
+
+	opts := InstallOptions{Path: t.TempDir()}
+	plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if plan.Main.GetName() != archive || plan.InstallPackage {
+		t.Errorf("PlanInstall() selected %q (package: %t), want %q", plan.Main.GetName(),
+			plan.InstallPackage, archive)
+	}
+}
+
+func TestPlanInstall_osFamily(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("system packages are only selected on Linux")
+	}
+	utils.GetOSArch()
+	deb := fmt.Sprintf("tool_1.0.0_linux_%s.deb", runtime.GOARCH)
+	rpm := fmt.Sprintf("tool-1.0.0-linux.%s.rpm", runtime.GOARCH)
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(rpm), ID: github.Ptr(int64(1))},
+		{Name: github.Ptr(deb), ID: github.Ptr(int64(2))},
+	}
+
+	tests := []struct {
+		family string
+		want   string
+	}{
+		{family: utils.FamilyDebian, want: deb},
+		{family: "ubuntu", want: deb},
+		{family: utils.FamilyRHEL, want: rpm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			opts := InstallOptions{
+				Path:     t.TempDir(),
+				OSFamily: tt.family,
+			}
+			plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+			if err != nil {
+				t.Fatalf("PlanInstall() error = %v", err)
+			}
+			if plan.Main.GetName() != tt.want || !plan.InstallPackage {
+				t.Errorf("PlanInstall() selected %q (package: %t), want %q", plan.Main.GetName(),
+					plan.InstallPackage, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanInstall_skipsMetadata(t *testing.T) {
+	utils.GetOSArch()
+	platform := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	archive := "tool_" + platform + ".tar.gz"
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr("tool_" + platform + ".sbom.json"), ID: github.Ptr(int64(1))},
+		{Name: github.Ptr("tool_" + platform + ".spdx.json"), ID: github.Ptr(int64(2))},
+		{Name: github.Ptr("tool_" + platform + "-src.tar.gz"), ID: github.Ptr(int64(3))},
+		{Name: github.Ptr(archive), ID: github.Ptr(int64(4))},
+		{Name: github.Ptr(archive + ".intoto.jsonl"), ID: github.Ptr(int64(5))},
+	}
+
+	opts := InstallOptions{Path: t.TempDir()}
+	plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if plan.Main.GetName() != archive {
+		t.Errorf("PlanInstall() selected %q, want %q", plan.Main.GetName(), archive)
+	}
+	if plan.Provenance.GetName() != archive+".intoto.jsonl" {
+		t.Errorf("PlanInstall() provenance = %q, want the archive's", plan.Provenance.GetName())
+	}
+}
+
+func TestPlanInstall_sourceArchives(t *testing.T) {
+	utils.GetOSArch()
+	archive := fmt.Sprintf("tool_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	var assets []*github.ReleaseAsset
+	for i, name := range []string{
+		"v1.0.0.tar.gz",
+		"Source code (zip)",
+		"Source code (tar.gz)",
+		"v1.0.0.zip",
+		archive,
+		"checksums.txt",
+	} {
+		asset := &github.ReleaseAsset{Name: github.Ptr(name), ID: github.Ptr(int64(i))}
+		assets = append(assets, asset)
+	}
+
+	tests := []struct {
+		name        string
+		source      bool
+		want        string
+		wantArchive bool
+	}{
+		{name: "source archives are skipped", want: archive, wantArchive: true},
+		{name: "--source selects one", source: true, want: "v1.0.0.tar.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := InstallOptions{
+				Path:   dir,
+				Source: tt.source,
+			}
+			plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+			if err != nil {
+				t.Fatalf("PlanInstall() error = %v", err)
+			}
+			if plan.Main.GetName() != tt.want || plan.IsArchive != tt.wantArchive {
+				t.Errorf("PlanInstall() selected %q (archive: %t), want %q (archive: %t)",
+					plan.Main.GetName(), plan.IsArchive, tt.want, tt.wantArchive)
+			}
+			if tt.source && plan.SavePath != filepath.Join(dir, tt.want) {
+				t.Errorf("PlanInstall() SavePath = %q, want the archive's own name", plan.SavePath)
+			}
+		})
+	}
+
+	opts := InstallOptions{Path: t.TempDir(), Source: true}
+	_, err := PlanInstall(assets[4:], "v1.0.0", opts, opts.Matcher())
+	if err == nil {
+		t.Error("PlanInstall() with --source and no source archive succeeded")
+	}
+}
+
+func TestPlanInstall_noMatchingAsset(t *testing.T) {
+	utils.GetOSArch()
+	names := []string{"tool_plan9_mips.tar.gz", "checksums.txt"}
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(names[0]), ID: github.Ptr(int64(1))},
+		nil,
+		{Name: github.Ptr(names[1]), ID: github.Ptr(int64(2))},
+	}
+
+	opts := InstallOptions{Path: t.TempDir()}
+	_, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	var noMatch *NoMatchingAssetError
+	if !errors.As(err, &noMatch) {
+		t.Fatalf("PlanInstall() error = %v, want a *NoMatchingAssetError", err)
+	}
+	if noMatch.OS != runtime.GOOS || noMatch.Arch != runtime.GOARCH ||
+		!reflect.DeepEqual(noMatch.Assets, names) {
+		t.Errorf("NoMatchingAssetError = %+v, want %s/%s with assets %v",
+			noMatch, runtime.GOOS, runtime.GOARCH, names)
+	}
+	want := fmt.Sprintf(
+		"no asset matched %s/%s; available assets were: %s",
+		runtime.GOOS,
+		runtime.GOARCH,
+		strings.Join(names, ", "),
+	)
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestPlanInstall_ambiguousAsset(t *testing.T) {
+	utils.GetOSArch()
+	platform := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	names := []string{"tool_" + platform + ".tar.gz", "tool-lite_" + platform + ".tar.gz"}
+	assets := testutil.Assets(names[0], names[1], "checksums.txt")
+
+	opts := InstallOptions{Path: t.TempDir()}
+	_, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	var tied *AmbiguousAssetError
+	if !errors.As(err, &tied) {
+		t.Fatalf("PlanInstall() error = %v, want an *AmbiguousAssetError", err)
+	}
+	if !reflect.DeepEqual(tied.Assets, names) || !strings.Contains(err.Error(), "--asset") {
+		t.Errorf("AmbiguousAssetError = %+v (%v), want assets %v", tied, err, names)
+	}
+
+	opts = InstallOptions{
+		Path:        t.TempDir(),
+		ChooseAsset: func(candidates []string) (string, error) { return candidates[1], nil },
+	}
+	plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	if err != nil || plan.Main.GetName() != names[1] {
+		t.Errorf("PlanInstall() = %q, %v with ChooseAsset, want %q", plan.Main.GetName(), err,
+			names[1])
+	}
+}
+
+func Test_selectAsset(t *testing.T) {
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr("tool_linux_x64.tar.gz"), ID: github.Ptr(int64(1))},
+		{Name: github.Ptr("tool_linux_x64.tar.gz.sha256"), ID: github.Ptr(int64(2))},
+		{Name: github.Ptr("tool_linux_arm.tar.gz"), ID: github.Ptr(int64(3))},
+		{Name: github.Ptr("tool_linux_x64.tar.gz.sig"), ID: github.Ptr(int64(4))},
+	}
+
+	tests := []struct {
+		name    string
+		asset   string
+		regex   string
+		want    string
+		wantErr bool
+	}{
+		{name: "exact name", asset: "tool_linux_arm.tar.gz", want: "tool_linux_arm.tar.gz"},
+		{
+			name:  "exact name of any asset",
+			asset: "tool_linux_x64.tar.gz.sig",
+			want:  "tool_linux_x64.tar.gz.sig",
+		},
+		{name: "unknown name", asset: "tool_linux_x64", wantErr: true},
+		{name: "regex skips checksums and signatures", regex: `x64`, want: "tool_linux_x64.tar.gz"},
+		{name: "regex matching several assets", regex: `^tool_linux_`, wantErr: true},
+		{name: "regex matching nothing", regex: `darwin`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var re *regexp.Regexp
+			if tt.regex != "" {
+				re = regexp.MustCompile(tt.regex)
+			}
+			got, err := selectAsset(assets, tt.asset, re)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.GetName() != tt.want {
+				t.Errorf("selectAsset() = %q, want %q", got.GetName(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanInstall_assetOverride(t *testing.T) {
+	utils.GetOSArch()
+	native := fmt.Sprintf("tool_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(native), ID: github.Ptr(int64(1))},
+		{Name: github.Ptr("tool-portable.zip"), ID: github.Ptr(int64(2))},
+		{Name: github.Ptr("checksums.txt"), ID: github.Ptr(int64(3))},
+	}
+
+	opts := InstallOptions{
+		Path:       t.TempDir(),
+		AssetRegex: regexp.MustCompile(`portable`),
+	}
+	plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if plan.Main.GetName() != "tool-portable.zip" || plan.Checksum.GetName() != "checksums.txt" {
+		t.Errorf("PlanInstall() selected %q with checksum %q, want tool-portable.zip",
+			plan.Main.GetName(), plan.Checksum.GetName())
+	}
+}
+
+func Test_contentTypeAllowed(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		allowed     []string
+		want        bool
+	}{
+		{name: "no filter", contentType: "application/gzip", want: true},
+		{
+			name:        "match",
+			contentType: "application/gzip",
+			allowed:     []string{"application/gzip"},
+			want:        true,
+		},
+		{
+			name:        "one of several",
+			contentType: "application/octet-stream",
+			allowed:     []string{"application/gzip", "application/octet-stream"},
+			want:        true,
+		},
+		{
+			name:        "case and parameters ignored",
+			contentType: "Application/Gzip; charset=binary",
+			allowed:     []string{" application/gzip"},
+			want:        true,
+		},
+		{name: "no match", contentType: "application/x-tar", allowed: []string{"application/gzip"}},
+		{name: "missing content type", allowed: []string{"application/gzip"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentTypeAllowed(tt.contentType, tt.allowed); got != tt.want {
+				t.Errorf("contentTypeAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanInstall_contentType(t *testing.T) {
+	utils.GetOSArch()
+	image := fmt.Sprintf("tool-image_%s_%s.tar", runtime.GOOS, runtime.GOARCH)
+	archive := fmt.Sprintf("tool_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	binary := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	asset := func(name string, id int64, contentType string) *github.ReleaseAsset {
+		return &github.ReleaseAsset{
+			Name:        github.Ptr(name),
+			ID:          github.Ptr(id),
+			ContentType: github.Ptr(contentType),
+		}
+	}
+	assets := []*github.ReleaseAsset{
+		asset(image, 1, "application/x-tar"),
+		asset(archive, 2, "application/gzip"),
+		asset(binary, 3, "application/octet-stream"),
+		asset("checksums.txt", 4, "text/plain"),
+	}
+
+	tests := []struct {
+		name         string
+		contentTypes []string
+		want         string
+		wantErr      bool
+	}{
+		{name: "different builds without a filter", wantErr: true},
+		{name: "gzip archive", contentTypes: []string{"application/gzip"}, want: archive},
+		{name: "raw binary", contentTypes: []string{"application/octet-stream"}, want: binary},
+		{name: "nothing matches", contentTypes: []string{"application/zip"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := InstallOptions{
+				Path:         t.TempDir(),
+				ContentTypes: tt.contentTypes,
+			}
+			plan, err := PlanInstall(assets, "v1.0.0", opts, opts.Matcher())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlanInstall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if plan.Main.GetName() != tt.want || plan.Checksum.GetName() != "checksums.txt" {
+				t.Errorf("PlanInstall() selected %q with checksum %q, want %q",
+					plan.Main.GetName(), plan.Checksum.GetName(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanInstall_symlink(t *testing.T) {
+	utils.GetOSArch()
+	assets := []*github.ReleaseAsset{{
+		Name: github.Ptr(fmt.Sprintf("tool_1.2.3_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)),
+		ID:   github.Ptr(int64(1)),
+	}}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		opts         InstallOptions
+		wantSavePath string
+		wantLinkPath string
+	}{
+		{
+			name:         "without --symlink",
+			opts:         InstallOptions{Path: dir},
+			wantSavePath: filepath.Join(dir, "tool"),
+		},
+		{
+			name:         "with --symlink",
+			opts:         InstallOptions{Path: dir, Symlink: true},
+			wantSavePath: filepath.Join(dir, "tool-v1.2.3"),
+			wantLinkPath: filepath.Join(dir, "tool"),
+		},
+		{
+			name:         "with --symlink and --binName",
+			opts:         InstallOptions{BinName: "t", Path: dir, Symlink: true},
+			wantSavePath: filepath.Join(dir, "t-v1.2.3"),
+			wantLinkPath: filepath.Join(dir, "t"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanInstall(assets, "v1.2.3", tt.opts, tt.opts.Matcher())
+			if err != nil {
+				t.Fatalf("PlanInstall() error = %v", err)
+			}
+			if plan.SavePath != tt.wantSavePath || plan.LinkPath != tt.wantLinkPath {
+				t.Errorf(
+					"PlanInstall() = (%q, %q), want (%q, %q)",
+					plan.SavePath, plan.LinkPath, tt.wantSavePath, tt.wantLinkPath,
+				)
+			}
+		})
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_binaryChecksum(t *testing.T) {
+	utils.GetOSArch()
+	binary := []byte("#!/bin/sh\necho tool\n")
+	binaryPath := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(binaryPath, binary, 0o600); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	sum, err := utils.HashFile(binaryPath, "sha256")
+	if err != nil {
+		t.Fatalf("Failed to hash binary: %v", err)
+	}
+	archive := testutil.TarGz(t, testutil.TarEntry{Name: "tool", Body: string(binary), Mode: 0o755})
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+
+	tests := []struct {
+		name      string
+		checksums string
+		wantErr   bool
+	}{
+		{name: "binary listed", checksums: fmt.Sprintf("%s  tool\n", sum)},
+		{name: "binary mismatch", checksums: fmt.Sprintf("%064d  tool\n", 0), wantErr: true},
+		{name: "nothing listed", checksums: fmt.Sprintf("%s  other\n", sum), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewGitHubClient(
+				t,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case strings.HasSuffix(r.URL.Path, "/assets/1"):
+						_, _ = w.Write(archive)
+					case strings.HasSuffix(r.URL.Path, "/assets/2"):
+						_, _ = w.Write([]byte(tt.checksums))
+					default:
+						http.NotFound(w, r)
+					}
+				}),
+			)
+			assets := []*github.ReleaseAsset{
+				{
+					Name:        github.Ptr(assetName),
+					ID:          github.Ptr(int64(1)),
+					Size:        github.Ptr(len(archive)),
+					ContentType: github.Ptr("application/gzip"),
+				},
+				{
+					Name: github.Ptr("checksums.txt"),
+					ID:   github.Ptr(int64(2)),
+					Size: github.Ptr(len(tt.checksums)),
+				},
+			}
+
+			targetDir := t.TempDir()
+			asset, err := findDownloadAndVerifyAsset(
+				context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+				InstallOptions{Path: targetDir},
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findDownloadAndVerifyAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			target := filepath.Join(targetDir, "tool")
+			if tt.wantErr {
+				if _, err := os.Stat(target); !os.IsNotExist(err) {
+					t.Errorf("unverified binary was installed at %s", target)
+				}
+				return
+			}
+			if asset.Checksum != sum || asset.Path != target {
+				t.Errorf("findDownloadAndVerifyAsset() = %+v, want checksum %q at %s", asset, sum, target)
+			}
+		})
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_weakChecksum(t *testing.T) {
+	utils.GetOSArch()
+	binary := []byte("#!/bin/sh\necho tool\n")
+	assetName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	binaryPath := filepath.Join(t.TempDir(), assetName)
+	if err := os.WriteFile(binaryPath, binary, 0o600); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	sum, err := utils.HashFile(binaryPath, "md5")
+	if err != nil {
+		t.Fatalf("Failed to hash binary: %v", err)
+	}
+	checksums := []byte(fmt.Sprintf("%s  %s\n", sum, assetName))
+	assets := []*github.ReleaseAsset{
+		{
+			Name:        github.Ptr(assetName),
+			ID:          github.Ptr(int64(1)),
+			Size:        github.Ptr(len(binary)),
+			ContentType: github.Ptr("application/octet-stream"),
+		},
+		{
+			Name: github.Ptr("checksums.md5"),
+			ID:   github.Ptr(int64(2)),
+			Size: github.Ptr(len(checksums)),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		allowWeak bool
+		wantErr   bool
+	}{
+		{name: "md5 only", wantErr: true},
+		{name: "md5 with --allow-weak-checksum", allowWeak: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewGitHubClient(
+				t,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case strings.HasSuffix(r.URL.Path, "/assets/1"):
+						_, _ = w.Write(binary)
+					case strings.HasSuffix(r.URL.Path, "/assets/2"):
+						_, _ = w.Write(checksums)
+					default:
+						http.NotFound(w, r)
+					}
+				}),
+			)
+
+			targetDir := t.TempDir()
+			asset, err := findDownloadAndVerifyAsset(
+				context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+				InstallOptions{Path: targetDir, AllowWeakChecksum: tt.allowWeak},
+			)
+			target := filepath.Join(targetDir, utils.ExecutableName("tool", runtime.GOOS))
+			if tt.wantErr {
+				var weakErr *WeakChecksumError
+				if !errors.As(err, &weakErr) || weakErr.Algorithm != "md5" {
+					t.Fatalf("findDownloadAndVerifyAsset() error = %v, want WeakChecksumError", err)
+				}
+				if _, err := os.Stat(target); !os.IsNotExist(err) {
+					t.Errorf("weakly verified binary was installed at %s", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findDownloadAndVerifyAsset() error = %v", err)
+			}
+			if asset.Algorithm != "md5" || asset.Checksum != sum {
+				t.Errorf("findDownloadAndVerifyAsset() = (%q, %q), want (md5, %q)",
+					asset.Algorithm, asset.Checksum, sum)
+			}
+		})
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_keepArchive(t *testing.T) {
+	utils.GetOSArch()
+	archive := testutil.TarGz(
+		t,
+		testutil.TarEntry{Name: "tool", Body: "#!/bin/sh\necho tool\n", Mode: 0o755},
+	)
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/assets/1") {
+				_, _ = w.Write(archive)
+				return
+			}
+			http.NotFound(w, r)
+		}),
+	)
+	assets := []*github.ReleaseAsset{
+		{
+			Name:        github.Ptr(assetName),
+			ID:          github.Ptr(int64(1)),
+			Size:        github.Ptr(len(archive)),
+			ContentType: github.Ptr("application/gzip"),
+		},
+	}
+
+	keepDir := filepath.Join(t.TempDir(), "mirror")
+	targetDir := t.TempDir()
+	if _, err := findDownloadAndVerifyAsset(
+		context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+		InstallOptions{Path: targetDir, KeepArchive: keepDir},
+	); err != nil {
+		t.Fatalf("findDownloadAndVerifyAsset() error = %v", err)
+	}
+
+	kept, err := os.ReadFile(filepath.Join(keepDir, assetName))
+	if err != nil {
+		t.Fatalf("archive was not kept: %v", err)
+	}
+	if !bytes.Equal(kept, archive) {
+		t.Error("kept archive differs from the downloaded one")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "tool")); err != nil {
+		t.Errorf("binary was not extracted: %v", err)
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_compressedBinary(t *testing.T) {
+	utils.GetOSArch()
+	binary := []byte("#!/bin/sh\necho tool\n")
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	if _, err := gzw.Write(binary); err != nil {
+		t.Fatalf("Failed to write gzip stream: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	compressed := buf.Bytes()
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.gz", runtime.GOOS, runtime.GOARCH)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/assets/1") {
+				_, _ = w.Write(compressed)
+				return
+			}
+			http.NotFound(w, r)
+		}),
+	)
+	assets := []*github.ReleaseAsset{
+		{
+			Name:        github.Ptr(assetName),
+			ID:          github.Ptr(int64(1)),
+			Size:        github.Ptr(len(compressed)),
+			ContentType: github.Ptr("application/gzip"),
+		},
+	}
+
+	targetDir := t.TempDir()
+	asset, err := findDownloadAndVerifyAsset(
+		context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+		InstallOptions{Path: targetDir},
+	)
+	if err != nil {
+		t.Fatalf("findDownloadAndVerifyAsset() error = %v", err)
+	}
+	target := filepath.Join(targetDir, "tool")
+	if asset.Path != target {
+		t.Errorf("findDownloadAndVerifyAsset() path = %s, want %s", asset.Path, target)
+	}
+	got, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("binary was not decompressed: %v", err)
+	}
+	if !bytes.Equal(got, binary) {
+		t.Errorf("installed binary = %q, want %q", got, binary)
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_force(t *testing.T) {
+	utils.GetOSArch()
+	assetName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	var downloads int
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/assets/1") {
+				downloads++
+				fmt.Fprint(w, "new!") //nolint:errcheck
+				return
+			}
+			http.NotFound(w, r)
+		}),
+	)
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(assetName), ID: github.Ptr(int64(1)), Size: github.Ptr(4)},
+	}
+
+	targetDir := t.TempDir()
+	existing := filepath.Join(targetDir, utils.ExecutableName("tool", runtime.GOOS))
+	if err := os.WriteFile(existing, []byte("old"), 0o600); err != nil {
+		t.Fatalf("Failed to write existing binary: %v", err)
+	}
+
+	_, err := findDownloadAndVerifyAsset(
+		context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+		InstallOptions{Path: targetDir},
+	)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("findDownloadAndVerifyAsset() error = %v, want a refusal to overwrite", err)
+	}
+	if got, _ := os.ReadFile(existing); string(got) != "old" || downloads != 0 {
+		t.Errorf("existing binary = %q after %d downloads, want it untouched", got, downloads)
+	}
+
+	if _, err := findDownloadAndVerifyAsset(
+		context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+		InstallOptions{Path: targetDir, Force: true},
+	); err != nil {
+		t.Fatalf("findDownloadAndVerifyAsset() with Force error = %v", err)
+	}
+	if got, _ := os.ReadFile(existing); string(got) != "new!" {
+		t.Errorf("binary = %q with Force, want it replaced", got)
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_forceKeepsBinaryOnFailedVerification(t *testing.T) {
+	utils.GetOSArch()
+	assetName := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	checksums := fmt.Sprintf("%s  %s\n", strings.Repeat("0", 64), assetName)
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/assets/1"):
+				fmt.Fprint(w, "new!") //nolint:errcheck
+			case strings.HasSuffix(r.URL.Path, "/assets/2"):
+				fmt.Fprint(w, checksums) //nolint:errcheck
+			default:
+				http.NotFound(w, r)
+			}
+		}),
+	)
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr(assetName), ID: github.Ptr(int64(1)), Size: github.Ptr(4)},
+		{
+			Name: github.Ptr("checksums.txt"),
+			ID:   github.Ptr(int64(2)),
+			Size: github.Ptr(len(checksums)),
+		},
+	}
+
+	targetDir := t.TempDir()
+	existing := filepath.Join(targetDir, utils.ExecutableName("tool", runtime.GOOS))
+	if err := os.WriteFile(existing, []byte("old"), 0o600); err != nil {
+		t.Fatalf("Failed to write existing binary: %v", err)
+	}
+
+	_, err := findDownloadAndVerifyAsset(
+		context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+		InstallOptions{Path: targetDir, Force: true},
+	)
+	if err == nil {
+		t.Fatal("findDownloadAndVerifyAsset() succeeded, want a checksum mismatch")
+	}
+	if got, err := os.ReadFile(existing); err != nil || string(got) != "old" {
+		t.Errorf("existing binary = %q, %v; want it kept when verification fails", got, err)
+	}
+	entries, _ := os.ReadDir(targetDir)
+	if len(entries) != 1 {
+		t.Errorf("target directory holds %d files, want only the existing binary", len(entries))
+	}
+}
+
+func Test_findDownloadAndVerifyAsset_multipleBinaries(t *testing.T) {
+	utils.GetOSArch()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, f := range []struct {
+		name string
+		mode int64
+	}{{"tool/foo", 0o755}, {"tool/foo-helper", 0o755}, {"tool/README.md", 0o644}} {
+		body := []byte("#!/bin/sh\necho " + f.name + "\n")
+		hdr := &tar.Header{
+			Name: f.name, Mode: f.mode, Size: int64(len(body)), Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			t.Fatalf("Failed to write tar body: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	archive := buf.Bytes()
+
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/assets/1") {
+				w.Write(archive) //nolint:errcheck
+				return
+			}
+			http.NotFound(w, r)
+		}),
+	)
+	assets := []*github.ReleaseAsset{{
+		Name: github.Ptr(fmt.Sprintf("tool_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)),
+		ID:   github.Ptr(int64(1)),
+		Size: github.Ptr(len(archive)),
+	}}
+
+	tests := []struct {
+		name      string
+		opts      InstallOptions
+		existing  string
+		wantPath  string
+		wantExtra []string
+		wantErr   bool
+	}{
+		{
+			name:      "extract by name",
+			opts:      InstallOptions{Extract: []string{"foo-helper", "foo"}},
+			wantPath:  "foo-helper",
+			wantExtra: []string{"foo"},
+		},
+		{
+			name:      "all binaries",
+			opts:      InstallOptions{AllBinaries: true},
+			wantPath:  "foo",
+			wantExtra: []string{"foo-helper"},
+		},
+		{
+			name:    "unknown name",
+			opts:    InstallOptions{Extract: []string{"foo", "bar"}},
+			wantErr: true,
+		},
+		{
+			name:     "existing binary",
+			opts:     InstallOptions{AllBinaries: true},
+			existing: "foo-helper",
+			wantErr:  true,
+		},
+		{
+			name:      "existing binary with force",
+			opts:      InstallOptions{AllBinaries: true, Force: true},
+			existing:  "foo-helper",
+			wantPath:  "foo",
+			wantExtra: []string{"foo-helper"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetDir := t.TempDir()
+			if tt.existing != "" {
+				existing := filepath.Join(targetDir, tt.existing)
+				if err := os.WriteFile(existing, []byte("old"), 0o600); err != nil {
+					t.Fatalf("Failed to write existing binary: %v", err)
+				}
+			}
+			tt.opts.Path = targetDir
+			got, err := findDownloadAndVerifyAsset(
+				context.Background(), client, "owner", "tool", "v1.0.0", assets, http.DefaultClient,
+				tt.opts,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findDownloadAndVerifyAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := filepath.Join(targetDir, tt.wantPath); got.Path != want {
+				t.Errorf("findDownloadAndVerifyAsset() path = %q, want %q", got.Path, want)
+			}
+			if len(got.Extra) != len(tt.wantExtra) {
+				t.Fatalf("extra = %v, want %v", got.Extra, tt.wantExtra)
+			}
+			for i, name := range tt.wantExtra {
+				if want := filepath.Join(targetDir, name); got.Extra[i] != want {
+					t.Errorf("extra[%d] = %q, want %q", i, got.Extra[i], want)
+				}
+			}
+			for _, path := range append([]string{got.Path}, got.Extra...) {
+				body, err := os.ReadFile(path)
+				if err != nil || !strings.HasPrefix(string(body), "#!/bin/sh") {
+					t.Errorf("installed binary %q = %q, %v", path, body, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(targetDir, "README.md")); !os.IsNotExist(err) {
+				t.Errorf("README.md was installed, want only executables")
+			}
+		})
+	}
+}
+
+func Test_checkOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "tool")
+	if err := os.WriteFile(existing, []byte("old"), 0o600); err != nil {
+		t.Fatalf("Failed to write existing file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		force   bool
+		wantErr bool
+	}{
+		{name: "free path", path: filepath.Join(dir, "other")},
+		{name: "existing file", path: existing, wantErr: true},
+		{name: "existing file with force", path: existing, force: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOverwrite(tt.path, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkOverwrite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "3 B") {
+				t.Errorf("checkOverwrite() error = %q, want the existing file's size", err)
+			}
+		})
+	}
+}
+
+func TestResolveTargetDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	absolute := filepath.Join(t.TempDir(), "bin")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "tilde", path: "~/bin", want: filepath.Join(home, "bin")},
+		{name: "env var", path: "$HOME/bin", want: filepath.Join(home, "bin")},
+		{name: "absolute", path: absolute + "/", want: absolute},
+		{name: "current directory", path: ".", want: "."},
+		{name: "default", path: "", want: xdg.BinHome},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveTargetDir(tt.path)
+			if err != nil {
+				t.Fatalf("ResolveTargetDir(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveTargetDir(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// promptMu serializes confirmation prompts of concurrent --config installs.
+var promptMu sync.Mutex
+
+// IsInteractive reports whether stdin is a terminal that a prompt can be answered on.
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ConfirmSystemPackage asks whether to run command, which installs the system package
+// at pkgPath, unless yes is set. Without a terminal there's nobody to answer, so
+// --yes is required instead of waiting on stdin.
+//
+// -in: Where the answer is read from.
+// -out: Where the prompt is written to; stderr so --output json stays parseable.
+// -interactive: Whether in is a terminal.
+// -yes: Whether the install was confirmed up front, e.g. with --yes.
+// Returns: An error if the install wasn't confirmed.
+func ConfirmSystemPackage(
+	in io.Reader,
+	out io.Writer,
+	interactive, yes bool,
+	pkgPath string,
+	command []string,
+) error {
+	if yes {
+		return nil
+	}
+	commandLine := strings.Join(command, " ")
+	if !interactive {
+		return fmt.Errorf(
+			"installing system package '%s' runs '%s'; pass --yes to confirm non-interactively",
+			filepath.Base(pkgPath),
+			commandLine,
+		)
+	}
+
+	promptMu.Lock()
+	defer promptMu.Unlock()
+	fmt.Fprintf( //nolint:errcheck
+		out,
+		"About to install the system package %s by running:\n  %s\nContinue? [y/N] ",
+		pkgPath,
+		commandLine,
+	)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("installation of system package '%s' cancelled", filepath.Base(pkgPath))
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirmSystemPackage(t *testing.T) {
+	command := []string{"sudo", "apt-get", "install", "-y", "/tmp/tool_amd64.deb"}
+
+	tests := []struct {
+		name        string
+		yes         bool
+		interactive bool
+		answer      string
+		wantErr     bool
+		wantPrompt  bool
+	}{
+		{name: "--yes skips the prompt", yes: true},
+		{name: "confirmed", interactive: true, answer: "y\n", wantPrompt: true},
+		{name: "confirmed in full", interactive: true, answer: "YES\n", wantPrompt: true},
+		{name: "declined", interactive: true, answer: "n\n", wantErr: true, wantPrompt: true},
+		{name: "default is no", interactive: true, answer: "\n", wantErr: true, wantPrompt: true},
+		{name: "end of input", interactive: true, wantErr: true, wantPrompt: true},
+		{name: "non-interactive requires --yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := ConfirmSystemPackage(
+				strings.NewReader(tt.answer),
+				&out,
+				tt.interactive,
+				tt.yes,
+				"/tmp/tool_amd64.deb",
+				command,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConfirmSystemPackage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotPrompt := out.Len() > 0; gotPrompt != tt.wantPrompt {
+				t.Errorf("prompt shown = %t, want %t: %q", gotPrompt, tt.wantPrompt, out.String())
+			}
+			if tt.wantPrompt && !strings.Contains(out.String(), strings.Join(command, " ")) {
+				t.Errorf("prompt doesn't show the command: %q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// progressInterval is how often a lineProgress logs how far a download got.
+const progressInterval = 5 * time.Second
+
+// Progress displays how far a download got. The downloaded data is written to it, and
+// Finish is called once the download ended, successfully or not.
+type Progress interface {
+	io.Writer
+	Finish(err error)
+}
+
+// stderrIsTerminal reports whether a progress bar can be drawn on stderr. The bar goes
+// there rather than to stdout, which carries results, e.g. for --print-path.
+var stderrIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stderr.Fd())) //nolint:gosec
+}
+
+// newProgress picks how the progress of a download is displayed: a progress bar when
+// enabled and stderr is a terminal, a line logged every progressInterval when it isn't,
+// e.g. in CI logs, and a single line when disabled, e.g. for --quiet or concurrent
+// downloads.
+//
+// -enabled: Whether progress is displayed, see InstallOptions.Progress.
+// -name: The name of the asset being downloaded.
+// -size: The size of the asset in bytes; unknown when 0 or less.
+// -offset: The bytes already downloaded by an earlier attempt.
+func newProgress(enabled bool, name string, size, offset int64) Progress {
+	switch {
+	case enabled && stderrIsTerminal():
+		return newBarProgress(os.Stderr, name, size, offset)
+	case enabled:
+		utils.Logger.Infof("Downloading %s...", name)
+		return newLineProgress(name, size, offset, progressInterval, time.Now)
+	default:
+		utils.Logger.Infof("Downloading %s...", name)
+		return noProgress{}
+	}
+}
+
+// barProgress draws a progress bar for interactive terminals.
+type barProgress struct {
+	bar *progressbar.ProgressBar
+}
+
+// newBarProgress returns a Progress drawing a bar on out, starting at offset.
+func newBarProgress(out io.Writer, name string, size, offset int64) *barProgress {
+	description := fmt.Sprintf("Downloading %s...", name)
+	theme := progressbar.Theme{
+		Saucer:        "=",
+		SaucerHead:    ">",
+		SaucerPadding: " ",
+		BarStart:      "[",
+		BarEnd:        "]",
+	}
+	if !color.NoColor {
+		description = "[cyan]" + description + "[reset]"
+		theme.Saucer = "[green]=[reset]"
+		theme.SaucerHead = "[green]>[reset]"
+	}
+	bar := progressbar.NewOptions64(
+		size,
+		progressbar.OptionSetWriter(out),
+		progressbar.OptionEnableColorCodes(!color.NoColor),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetWidth(35), //nolint:mnd
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetTheme(theme),
+		progressbar.OptionClearOnFinish(),
+	)
+	if offset > 0 {
+		_ = bar.Set64(offset) // Reflect the bytes downloaded by an earlier attempt
+	}
+	return &barProgress{bar: bar}
+}
+
+func (p *barProgress) Write(b []byte) (int, error) {
+	return p.bar.Write(b)
+}
+
+// Finish clears a bar left incomplete by a failed download, so the error is logged
+// on a line of its own; a complete bar clears itself.
+func (p *barProgress) Finish(err error) {
+	if err != nil {
+		_ = p.bar.Clear()
+	}
+}
+
+// lineProgress logs how far a download got at regular intervals, for output that isn't
+// a terminal, where a progress bar would show up as a mess of escape codes.
+type lineProgress struct {
+	name     string
+	size     int64
+	written  int64
+	interval time.Duration
+	now      func() time.Time
+	lastLog  time.Time
+}
+
+// newLineProgress returns a Progress logging the downloaded bytes every interval.
+//
+// -now: Returns the current time; time.Now outside of tests.
+func newLineProgress(
+	name string,
+	size, offset int64,
+	interval time.Duration,
+	now func() time.Time,
+) *lineProgress {
+	return &lineProgress{
+		name:     name,
+		size:     size,
+		written:  offset,
+		interval: interval,
+		now:      now,
+		lastLog:  now(),
+	}
+}
+
+func (p *lineProgress) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if t := p.now(); t.Sub(p.lastLog) >= p.interval {
+		p.lastLog = t
+		if p.size > 0 {
+			utils.Logger.Infof(
+				"%s: downloaded %s of %s (%d%%)",
+				p.name,
+				utils.HumanBytes(p.written),
+				utils.HumanBytes(p.size),
+				p.written*100/p.size, //nolint:mnd
+			)
+		} else {
+			utils.Logger.Infof("%s: downloaded %s", p.name, utils.HumanBytes(p.written))
+		}
+	}
+	return len(b), nil
+}
+
+// Finish does nothing; writeAssetToFile logs the outcome of the download.
+func (p *lineProgress) Finish(error) {}
+
+// noProgress displays nothing.
+type noProgress struct{}
+
+func (noProgress) Write(b []byte) (int, error) { return len(b), nil }
+
+func (noProgress) Finish(error) {}
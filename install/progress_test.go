@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_lineProgress(t *testing.T) {
+	var buf bytes.Buffer
+	utils.Logger.SetOutput(&buf)
+	t.Cleanup(func() { utils.Logger.SetOutput(os.Stderr) })
+
+	type step struct {
+		n       int           // Bytes written
+		advance time.Duration // Time passed since the previous write
+		want    string        // Line logged after the write; none when empty
+	}
+	tests := []struct {
+		name   string
+		size   int64
+		offset int64
+		steps  []step
+	}{
+		{
+			name:   "known size",
+			size:   4096,
+			offset: 1024, // Resumed download
+			steps: []step{
+				{n: 1024, advance: time.Second},
+				{
+					n:       1024,
+					advance: 5 * time.Second,
+					want:    "tool.tar.gz: downloaded 3.0 KiB of 4.0 KiB (75%)",
+				},
+				{n: 512, advance: time.Second},
+				{
+					n:       512,
+					advance: 4 * time.Second,
+					want:    "tool.tar.gz: downloaded 4.0 KiB of 4.0 KiB (100%)",
+				},
+			},
+		},
+		{
+			name: "unknown size",
+			steps: []step{
+				{n: 2048, advance: 10 * time.Second, want: "tool.tar.gz: downloaded 2.0 KiB"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time.Unix(0, 0)
+			p := newLineProgress(
+				"tool.tar.gz",
+				tt.size,
+				tt.offset,
+				5*time.Second,
+				func() time.Time { return clock },
+			)
+			for i, step := range tt.steps {
+				buf.Reset()
+				clock = clock.Add(step.advance)
+				if n, err := p.Write(make([]byte, step.n)); n != step.n || err != nil {
+					t.Fatalf("Write() = (%d, %v), want (%d, nil)", n, err, step.n)
+				}
+				got := strings.TrimSpace(buf.String())
+				if got != step.want {
+					t.Errorf("step %d logged %q, want %q", i, got, step.want)
+				}
+			}
+			p.Finish(nil)
+		})
+	}
+}
+
+func Test_newProgress(t *testing.T) {
+	defer func(orig func() bool) { stderrIsTerminal = orig }(stderrIsTerminal)
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		terminal bool
+		want     string
+	}{
+		{name: "terminal", enabled: true, terminal: true, want: "*install.barProgress"},
+		{name: "no terminal", enabled: true, want: "*install.lineProgress"},
+		{name: "disabled", terminal: true, want: "install.noProgress"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderrIsTerminal = func() bool { return tt.terminal }
+			p := newProgress(tt.enabled, "tool.tar.gz", 1024, 0)
+			if got := fmt.Sprintf("%T", p); got != tt.want {
+				t.Errorf("newProgress() = %s, want %s", got, tt.want)
+			}
+			p.Finish(errors.New("interrupted"))
+		})
+	}
+}
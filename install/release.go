@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// releasesPerPage is the page size used when listing releases.
+const releasesPerPage = 100
+
+// ResolveRelease returns the release described by pa: the latest release when no
+// version was given, the highest release matching pa.Constraint when one was given,
+// the release created from pa.Commit when one was given, or the release with the exact
+// tag otherwise. With prerelease, the latest release may be a pre-release. With
+// includeDrafts, an exact tag may name a draft release.
+func ResolveRelease(
+	ctx context.Context,
+	client *github.Client,
+	pa utils.ParsedArgs,
+	prerelease, includeDrafts bool,
+) (*github.RepositoryRelease, error) {
+	switch {
+	case pa.Constraint != "":
+		utils.Logger.Infof(
+			"Resolving releases of %s/%s matching '%s'", pa.Owner, pa.Repo, pa.Constraint,
+		)
+		release, err := getConstrainedRelease(ctx, client, pa.Owner, pa.Repo, pa.Constraint)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve constraint '%s': %w", pa.Constraint, err)
+		}
+		utils.Logger.Infof("Resolved '%s' to release tag: %s", pa.Constraint, release.GetTagName())
+		return release, nil
+	case prerelease && (pa.Version == "latest" || pa.Version == ""):
+		utils.Logger.Infof(
+			"Fetching assets for newest release (including pre-releases) of %s/%s",
+			pa.Owner,
+			pa.Repo,
+		)
+		release, err := getNewestRelease(ctx, client, pa.Owner, pa.Repo)
+		if err != nil {
+			return nil, fmt.Errorf("could not get newest release: %w", err)
+		}
+		if release.GetPrerelease() {
+			utils.Logger.Infof("Newest release tag: %s (pre-release)", release.GetTagName())
+		} else {
+			utils.Logger.Infof("Newest release tag: %s (not a pre-release)", release.GetTagName())
+		}
+		return release, nil
+	case pa.Version == "latest" || pa.Version == "":
+		utils.Logger.Infof("Fetching assets for latest release of %s/%s", pa.Owner, pa.Repo)
+		release, err := GetLatestRelease(ctx, client, pa.Owner, pa.Repo)
+		if err != nil {
+			return nil, fmt.Errorf("could not get latest release: %w", err)
+		}
+		utils.Logger.Infof("Latest release tag: %s", release.GetTagName())
+		return release, nil
+	case pa.Commit != "":
+		utils.Logger.Infof("Resolving the release of %s/%s at commit %s", pa.Owner, pa.Repo, pa.Commit)
+		release, err := getCommitRelease(ctx, client, pa.Owner, pa.Repo, pa.Commit)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve commit '%s': %w", pa.Commit, err)
+		}
+		utils.Logger.Infof("Resolved commit %s to release tag: %s", pa.Commit, release.GetTagName())
+		return release, nil
+	default:
+		utils.Logger.Infof(
+			"Fetching assets for release tag '%s' of %s/%s", pa.Version, pa.Owner, pa.Repo,
+		)
+		release, err := getTaggedRelease(ctx, client, pa.Owner, pa.Repo, pa.Version, includeDrafts)
+		if err != nil {
+			return nil, fmt.Errorf("could not get release for tag '%s': %w", pa.Version, err)
+		}
+		return release, nil
+	}
+}
+
+// getConstrainedRelease lists the releases of owner/repo and returns the highest
+// semantic version satisfying constraint. Drafts and tags that aren't semantic
+// versions are ignored.
+func getConstrainedRelease(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, constraint string,
+) (*github.RepositoryRelease, error) {
+	c, err := utils.ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := ListReleases(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *github.RepositoryRelease
+	var bestVersion utils.Semver
+	for _, r := range releases {
+		if r.GetDraft() {
+			continue
+		}
+		v, ok := utils.ParseSemver(r.GetTagName())
+		if !ok || !c.Check(v) {
+			continue
+		}
+		if best == nil || v.Compare(bestVersion) > 0 {
+			best, bestVersion = r, v
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf(
+			"no release of %s/%s matches '%s' (checked %d releases)",
+			owner,
+			repo,
+			constraint,
+			len(releases),
+		)
+	}
+	return best, nil
+}
+
+// getCommitRelease returns the release of owner/repo created from the commit sha, which
+// may be abbreviated. A release matches when its target commitish is that commit or
+// when its tag points at it; the tags are only listed if no target commitish matches,
+// as most releases target a branch name rather than a SHA.
+func getCommitRelease(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, sha string,
+) (*github.RepositoryRelease, error) {
+	releases, err := ListReleases(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if !r.GetDraft() && commitMatches(r.GetTargetCommitish(), sha) {
+			return r, nil
+		}
+	}
+
+	tagCommits, err := listTagCommits(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if !r.GetDraft() && commitMatches(tagCommits[r.GetTagName()], sha) {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"no release of %s/%s was created from commit '%s'; install a release by tag "+
+			"with %s/%s@<tag> instead",
+		owner,
+		repo,
+		sha,
+		owner,
+		repo,
+	)
+}
+
+// commitMatches reports whether the full commit SHA commit starts with the
+// (possibly abbreviated, lower case) sha.
+func commitMatches(commit, sha string) bool {
+	return len(commit) == 40 && strings.HasPrefix(strings.ToLower(commit), sha) //nolint:mnd
+}
+
+// listTagCommits returns the commit SHA each tag of owner/repo points at, following
+// pagination.
+func listTagCommits(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+) (map[string]string, error) {
+	commits := make(map[string]string)
+	opts := &github.ListOptions{PerPage: releasesPerPage}
+	for {
+		tags, resp, err := client.Repositories.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, WithRateLimit(fmt.Errorf(
+				"failed to list tags of %s/%s: %w",
+				owner,
+				repo,
+				err,
+			), resp)
+		}
+		for _, tag := range tags {
+			commits[tag.GetName()] = tag.GetCommit().GetSHA()
+		}
+		if resp.NextPage == 0 {
+			return commits, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// getNewestRelease returns the most recently published non-draft release of
+// owner/repo, including pre-releases, which GetLatestRelease skips.
+func getNewestRelease(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+) (*github.RepositoryRelease, error) {
+	releases, err := ListReleases(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var newest *github.RepositoryRelease
+	for _, r := range releases {
+		if r.GetDraft() {
+			continue
+		}
+		if newest == nil || ReleaseTime(r).After(ReleaseTime(newest)) {
+			newest = r
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("repository %s/%s has no published releases", owner, repo)
+	}
+	return newest, nil
+}
+
+// ReleaseTime returns when a release was published, falling back to its creation time.
+func ReleaseTime(r *github.RepositoryRelease) time.Time {
+	if t := r.GetPublishedAt(); !t.IsZero() {
+		return t.Time
+	}
+	return r.GetCreatedAt().Time
+}
+
+// ListReleases returns every release of owner/repo, drafts included, following
+// pagination.
+func ListReleases(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+) ([]*github.RepositoryRelease, error) {
+	var all []*github.RepositoryRelease
+	opts := &github.ListOptions{PerPage: releasesPerPage}
+	for {
+		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, WithRateLimit(fmt.Errorf(
+				"failed to list releases of %s/%s: %w",
+				owner,
+				repo,
+				err,
+			), resp)
+		}
+		all = append(all, releases...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
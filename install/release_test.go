@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+	"github.com/esacteksab/gh-install/utils"
+)
+
+func Test_getConstrainedRelease(t *testing.T) {
+	// Two pages of releases to exercise pagination
+	pages := map[string]string{
+		"": `[{"tag_name":"v2.0.0"},{"tag_name":"v1.4.0-rc.1"},{"tag_name":"v1.3.2"},
+			{"tag_name":"v1.9.0","draft":true}]`,
+		"2": `[{"tag_name":"v1.3.0"},{"tag_name":"nightly"},{"tag_name":"v0.9.0"}]`,
+	}
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				w.Header().Set(
+					"Link",
+					fmt.Sprintf(`<http://%s%s?page=2>; rel="next"`, r.Host, r.URL.Path),
+				)
+			}
+			fmt.Fprint(w, pages[page]) //nolint:errcheck
+		}),
+	)
+
+	tests := []struct {
+		name       string
+		constraint string
+		wantTag    string
+		wantErr    bool
+	}{
+		{name: "caret", constraint: "^1.0.0", wantTag: "v1.3.2"},
+		{name: "range", constraint: ">=1.0.0 <1.3.1", wantTag: "v1.3.0"},
+		{name: "greater picks highest", constraint: ">=0.1.0", wantTag: "v2.0.0"},
+		{name: "explicit prerelease", constraint: ">=1.4.0-rc.1 <1.5.0", wantTag: "v1.4.0-rc.1"},
+		{name: "draft ignored", constraint: "~1.9.0", wantErr: true},
+		{name: "no match", constraint: "^3.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := getConstrainedRelease(
+				context.Background(), client, "owner", "repo", tt.constraint,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getConstrainedRelease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && release.GetTagName() != tt.wantTag {
+				t.Errorf("getConstrainedRelease() tag = %q, want %q", release.GetTagName(), tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_getNewestRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		releases string
+		wantTag  string
+		wantErr  bool
+	}{
+		{
+			name: "pre-release newer than latest",
+			releases: `[
+				{"tag_name":"v1.0.0","published_at":"2025-01-01T00:00:00Z"},
+				{"tag_name":"v1.1.0-rc.1","prerelease":true,"published_at":"2025-02-01T00:00:00Z"},
+				{"tag_name":"v1.2.0-rc.1","draft":true}
+			]`,
+			wantTag: "v1.1.0-rc.1",
+		},
+		{
+			name: "stable newer than pre-release",
+			releases: `[
+				{"tag_name":"v1.1.0-rc.1","prerelease":true,"published_at":"2025-02-01T00:00:00Z"},
+				{"tag_name":"v1.1.0","published_at":"2025-03-01T00:00:00Z"}
+			]`,
+			wantTag: "v1.1.0",
+		},
+		{
+			name:     "only drafts",
+			releases: `[{"tag_name":"v1.0.0","draft":true}]`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewGitHubClient(
+				t,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, tt.releases) //nolint:errcheck
+				}),
+			)
+			release, err := getNewestRelease(context.Background(), client, "owner", "repo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getNewestRelease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && release.GetTagName() != tt.wantTag {
+				t.Errorf("getNewestRelease() tag = %q, want %q", release.GetTagName(), tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_getCommitRelease(t *testing.T) {
+	const (
+		targetSHA = "3f2a9c1d0e5b7a8c9d0e1f2a3b4c5d6e7f8a9b0c"
+		tagSHA    = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+		draftSHA  = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[
+			{"tag_name":"v1.2.0","target_commitish":%q},
+			{"tag_name":"v1.1.0","target_commitish":"main"},
+			{"tag_name":"v1.3.0-rc.1","target_commitish":%q,"draft":true}
+		]`, targetSHA, draftSHA) //nolint:errcheck
+	})
+	mux.HandleFunc("/repos/owner/repo/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[
+			{"name":"v1.2.0","commit":{"sha":%q}},
+			{"name":"v1.1.0","commit":{"sha":%q}}
+		]`, targetSHA, tagSHA) //nolint:errcheck
+	})
+	client := testutil.NewGitHubClient(t, mux)
+
+	tests := []struct {
+		name    string
+		sha     string
+		wantTag string
+		wantErr bool
+	}{
+		{name: "target commitish", sha: "3f2a9c1", wantTag: "v1.2.0"},
+		{name: "full target commitish", sha: targetSHA, wantTag: "v1.2.0"},
+		{name: "tag commit", sha: "a1b2c3d4", wantTag: "v1.1.0"},
+		{name: "draft ignored", sha: "bbbbbbb", wantErr: true},
+		{name: "no match", sha: "ccccccc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := getCommitRelease(context.Background(), client, "owner", "repo", tt.sha)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getCommitRelease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "owner/repo@<tag>") {
+					t.Errorf("getCommitRelease() error = %q, want a hint to use @<tag>", err)
+				}
+				return
+			}
+			if release.GetTagName() != tt.wantTag {
+				t.Errorf("getCommitRelease() tag = %q, want %q", release.GetTagName(), tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_getTaggedRelease_draft(t *testing.T) {
+	client := testutil.NewGitHubClient(
+		t,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/repo/releases/tags/v1.0.0":
+				fmt.Fprint(w, `{"tag_name":"v1.0.0"}`) //nolint:errcheck
+			case "/repos/owner/repo/releases":
+				fmt.Fprint( //nolint:errcheck
+					w,
+					`[{"tag_name":"v2.0.0","draft":true},{"tag_name":"v1.0.0"}]`,
+				)
+			default:
+				http.NotFound(w, r) // GitHub doesn't look up drafts by tag
+			}
+		}),
+	)
+
+	tests := []struct {
+		name          string
+		tag           string
+		includeDrafts bool
+		wantErr       string
+	}{
+		{name: "published", tag: "v1.0.0"},
+		{
+			name:    "draft",
+			tag:     "v2.0.0",
+			wantErr: "release v2.0.0 is a draft; pass --include-drafts to install it",
+		},
+		{name: "draft included", tag: "v2.0.0", includeDrafts: true},
+		{name: "missing", tag: "v3.0.0", wantErr: "release with tag 'v3.0.0' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := getTaggedRelease(
+				context.Background(), client, "owner", "repo", tt.tag, tt.includeDrafts,
+			)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getTaggedRelease() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getTaggedRelease() error = %v", err)
+			}
+			if release.GetTagName() != tt.tag {
+				t.Errorf("getTaggedRelease() tag = %s, want %s", release.GetTagName(), tt.tag)
+			}
+		})
+	}
+
+	var draftErr *DraftReleaseError
+	pa := utils.ParsedArgs{Owner: "owner", Repo: "repo", Version: "v2.0.0"}
+	_, err := ResolveRelease(context.Background(), client, pa, false, false)
+	if !errors.As(err, &draftErr) || draftErr.Tag != "v2.0.0" {
+		t.Errorf("ResolveRelease() error = %v, want a *DraftReleaseError", err)
+	}
+}
+
+func Test_getRelease_rateLimited(t *testing.T) {
+	reset := time.Now().Add(14*time.Minute + 32*time.Second)
+
+	tests := []struct {
+		name      string
+		limit     string
+		tag       string
+		wantToken bool
+	}{
+		{name: "latest release, unauthenticated", limit: "60", wantToken: true},
+		{name: "tagged release, unauthenticated", limit: "60", tag: "v1.0.0", wantToken: true},
+		{name: "latest release, authenticated", limit: "5000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewGitHubClient(
+				t,
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("X-RateLimit-Limit", tt.limit)
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+					fmt.Fprint(w, `{"message": "API rate limit exceeded"}`) //nolint:errcheck
+				}),
+			)
+
+			var err error
+			if tt.tag == "" {
+				_, err = GetLatestRelease(context.Background(), client, "owner", "repo")
+			} else {
+				_, err = getTaggedRelease(
+					context.Background(), client, "owner", "repo", tt.tag, false,
+				)
+			}
+			if err == nil {
+				t.Fatal("getRelease() error = nil, want a rate limit error")
+			}
+			want := "resets in 14m3" // 14m32s, give or take a second
+			if !strings.Contains(err.Error(), want) ||
+				!strings.Contains(err.Error(), reset.Local().Format("15:04 MST")) {
+				t.Errorf("getRelease() error = %q, want the reset countdown", err)
+			}
+			if got := strings.Contains(err.Error(), "set GITHUB_TOKEN"); got != tt.wantToken {
+				t.Errorf("getRelease() error = %q, want token hint %t", err, tt.wantToken)
+			}
+		})
+	}
+}
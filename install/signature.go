@@ -0,0 +1,276 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/esacteksab/gh-install/utils"
+)
+
+// githubHost is the host of releases whose cosign signatures can be verified.
+const githubHost = "github.com"
+
+// cosignAssets holds the release assets that sign a main asset.
+type cosignAssets struct {
+	Signature   *github.ReleaseAsset // Detached signature (.sig) or sigstore bundle
+	Certificate *github.ReleaseAsset // Signing certificate (.pem); nil for bundles
+}
+
+// findCosignAssets looks for a cosign signature of mainName among assets: either
+// "<mainName>.sig" with "<mainName>.pem" (or .crt/.cert), or a "<mainName>.bundle"/
+// "<mainName>.sigstore.json" bundle. Returns false if none is published.
+func findCosignAssets(assets []*github.ReleaseAsset, mainName string) (cosignAssets, bool) {
+	byName := make(map[string]*github.ReleaseAsset, len(assets))
+	for _, a := range assets {
+		if a != nil && a.Name != nil {
+			byName[strings.ToLower(a.GetName())] = a
+		}
+	}
+	lookup := func(suffixes ...string) *github.ReleaseAsset {
+		for _, suffix := range suffixes {
+			if a, ok := byName[strings.ToLower(mainName+suffix)]; ok {
+				return a
+			}
+		}
+		return nil
+	}
+
+	if sig, cert := lookup(".sig"), lookup(".pem", ".crt", ".cert"); sig != nil && cert != nil {
+		return cosignAssets{Signature: sig, Certificate: cert}, true
+	}
+	if bundle := lookup(".sigstore.json", ".sigstore", ".bundle"); bundle != nil {
+		return cosignAssets{Signature: bundle}, true
+	}
+	return cosignAssets{}, false
+}
+
+// verifyAssetSignature downloads the cosign signature of mainAsset and verifies the
+// file at mainAssetPath against it. The signing certificate must have been issued to a
+// GitHub Actions workflow of owner/repo on github.com, see signerIdentity. A missing
+// signature is an error, as verification was requested.
+func verifyAssetSignature(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	assets []*github.ReleaseAsset,
+	mainAsset *github.ReleaseAsset,
+	mainAssetPath string,
+	httpClient *http.Client,
+	progress bool,
+) error {
+	identity, err := signerIdentity(mainAsset, owner, repo)
+	if err != nil {
+		return err
+	}
+	signed, ok := findCosignAssets(assets, mainAsset.GetName())
+	if !ok {
+		return fmt.Errorf(
+			"--verify-signature was given but no cosign signature was found for '%s'",
+			mainAsset.GetName(),
+		)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gh-install-sig-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	download := func(asset *github.ReleaseAsset) (string, error) {
+		if asset == nil {
+			return "", nil
+		}
+		path := filepath.Join(tmpDir, filepath.Base(asset.GetName()))
+		return DownloadAndSaveAsset(ctx, client, owner, repo, asset, httpClient, path, progress)
+	}
+
+	sigPath, err := download(signed.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to download signature '%s': %w", signed.Signature.GetName(), err)
+	}
+	certPath, err := download(signed.Certificate)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to download certificate '%s': %w",
+			signed.Certificate.GetName(),
+			err,
+		)
+	}
+
+	if err := utils.VerifyCosignIdentity(mainAssetPath, sigPath, certPath, identity); err != nil {
+		return err
+	}
+	utils.Logger.Info(Green("✔") + " Signature verified!")
+	return nil
+}
+
+// signerIdentity returns the certificate identity the workflows of owner/repo sign
+// with, on the host mainAsset is downloaded from. GitHub Enterprise Server workflows
+// get their tokens from the server itself, which the public Sigstore instance doesn't
+// issue certificates for, so only github.com releases can be verified.
+//
+// Returns: A regular expression matching the workflow URLs, and an error for releases
+// on other hosts.
+func signerIdentity(mainAsset *github.ReleaseAsset, owner, repo string) (string, error) {
+	host := githubHost
+	if u, err := url.Parse(mainAsset.GetBrowserDownloadURL()); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if !strings.EqualFold(host, githubHost) {
+		return "", fmt.Errorf(
+			"--verify-signature only supports releases on %s, not %s: keyless cosign "+
+				"certificates aren't issued to GitHub Enterprise Server workflows",
+			githubHost,
+			host,
+		)
+	}
+	return fmt.Sprintf(
+		"^https://%s/%s/%s/",
+		regexp.QuoteMeta(githubHost),
+		regexp.QuoteMeta(owner),
+		regexp.QuoteMeta(repo),
+	), nil
+}
+
+// findChecksumSignature returns the detached GPG signature ("<checksum>.asc" or
+// "<checksum>.sig") published for the checksum file checksumName, if any.
+func findChecksumSignature(
+	assets []*github.ReleaseAsset,
+	checksumName string,
+) (*github.ReleaseAsset, bool) {
+	for _, suffix := range []string{".asc", ".sig"} {
+		for _, a := range assets {
+			if a != nil && strings.EqualFold(a.GetName(), checksumName+suffix) {
+				return a, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// verifyChecksumSignature downloads the GPG signature of checksumAsset and verifies
+// the checksum file at checksumPath against the public key files keys. It fails
+// closed: a missing signature or a signature that doesn't verify is an error.
+func verifyChecksumSignature(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	assets []*github.ReleaseAsset,
+	checksumAsset *github.ReleaseAsset,
+	checksumPath string,
+	httpClient *http.Client,
+	keys []string,
+	progress bool,
+) error {
+	sigAsset, ok := findChecksumSignature(assets, checksumAsset.GetName())
+	if !ok {
+		return fmt.Errorf(
+			"--gpg-key was given but no signature was found for checksum file '%s'",
+			checksumAsset.GetName(),
+		)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gh-install-sig-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	sigPath, err := DownloadAndSaveAsset(
+		ctx,
+		client,
+		owner,
+		repo,
+		sigAsset,
+		httpClient,
+		filepath.Join(tmpDir, filepath.Base(sigAsset.GetName())),
+		progress,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to download signature '%s': %w", sigAsset.GetName(), err)
+	}
+
+	if err := utils.VerifyGPGSignature(checksumPath, sigPath, keys); err != nil {
+		return err
+	}
+	utils.Logger.Info(Green("✔") + " Checksum file signature verified!")
+	return nil
+}
+
+// selectProvenance picks the attestation for mainName from the assets of a release:
+// "<mainName>.intoto.jsonl" if published, otherwise the first provenance asset, which
+// the SLSA generators name "multiple.intoto.jsonl" when it covers every asset.
+// Returns nil if the release has no provenance.
+func selectProvenance(assets []*github.ReleaseAsset, mainName string) *github.ReleaseAsset {
+	var first *github.ReleaseAsset
+	for _, a := range assets {
+		if !utils.IsProvenanceFile(a.GetName()) {
+			continue
+		}
+		if strings.EqualFold(a.GetName(), mainName+".intoto.jsonl") {
+			return a
+		}
+		if first == nil {
+			first = a
+		}
+	}
+	return first
+}
+
+// verifyAssetProvenance downloads the SLSA provenance of mainAsset and verifies the
+// file at mainAssetPath against it. The attestation must come from a trusted builder
+// running on owner/repo and, when tag is set, on that tag. A missing attestation is an
+// error, as verification was requested.
+func verifyAssetProvenance(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, tag string,
+	provenance *github.ReleaseAsset,
+	mainAsset *github.ReleaseAsset,
+	mainAssetPath string,
+	httpClient *http.Client,
+	progress bool,
+) error {
+	if provenance == nil {
+		return fmt.Errorf(
+			"--verify-provenance was given but no SLSA provenance was found for '%s'",
+			mainAsset.GetName(),
+		)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gh-install-provenance-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	provenancePath, err := DownloadAndSaveAsset(
+		ctx,
+		client,
+		owner,
+		repo,
+		provenance,
+		httpClient,
+		filepath.Join(tmpDir, filepath.Base(provenance.GetName())),
+		progress,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to download provenance '%s': %w", provenance.GetName(), err)
+	}
+
+	sourceURI := "github.com/" + owner + "/" + repo
+	if err := utils.VerifyProvenance(mainAssetPath, provenancePath, sourceURI, tag); err != nil {
+		return err
+	}
+	utils.Logger.Info(Green("✔") + " Provenance verified!")
+	return nil
+}
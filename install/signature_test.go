@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: MIT
+package install
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v80/github"
+)
+
+func Test_findCosignAssets(t *testing.T) {
+	mainName := "tool_linux_amd64.tar.gz"
+	tests := []struct {
+		name     string
+		assets   []string
+		wantSig  string
+		wantCert string
+		wantOK   bool
+	}{
+		{
+			name:     "signature and certificate",
+			assets:   []string{mainName, mainName + ".sig", mainName + ".pem", "checksums.txt"},
+			wantSig:  mainName + ".sig",
+			wantCert: mainName + ".pem",
+			wantOK:   true,
+		},
+		{
+			name:    "bundle",
+			assets:  []string{mainName, mainName + ".sigstore.json"},
+			wantSig: mainName + ".sigstore.json",
+			wantOK:  true,
+		},
+		{
+			name:   "signature without certificate",
+			assets: []string{mainName, mainName + ".sig"},
+		},
+		{
+			name:   "signature of another asset",
+			assets: []string{mainName, "tool_darwin_arm64.tar.gz.sig", "tool_darwin_arm64.tar.gz.pem"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := make([]*github.ReleaseAsset, 0, len(tt.assets))
+			for _, name := range tt.assets {
+				assets = append(assets, &github.ReleaseAsset{Name: github.Ptr(name)})
+			}
+
+			got, ok := findCosignAssets(assets, mainName)
+			if ok != tt.wantOK {
+				t.Fatalf("findCosignAssets() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Signature.GetName() != tt.wantSig {
+				t.Errorf("Signature = %q, want %q", got.Signature.GetName(), tt.wantSig)
+			}
+			if got.Certificate.GetName() != tt.wantCert {
+				t.Errorf("Certificate = %q, want %q", got.Certificate.GetName(), tt.wantCert)
+			}
+		})
+	}
+}
+
+func Test_findChecksumSignature(t *testing.T) {
+	assets := []*github.ReleaseAsset{
+		{Name: github.Ptr("checksums.txt")},
+		{Name: github.Ptr("checksums.txt.sig")},
+		{Name: github.Ptr("checksums.txt.asc")},
+	}
+
+	got, ok := findChecksumSignature(assets, "checksums.txt")
+	if !ok || got.GetName() != "checksums.txt.asc" {
+		t.Errorf("findChecksumSignature() = %q, %v; want checksums.txt.asc, true", got.GetName(), ok)
+	}
+
+	if _, ok := findChecksumSignature(assets, "SHA256SUMS"); ok {
+		t.Error("findChecksumSignature() found a signature for an unsigned checksum file")
+	}
+}
+
+func Test_selectProvenance(t *testing.T) {
+	mainName := "tool_linux_amd64.tar.gz"
+	tests := []struct {
+		name   string
+		assets []string
+		want   string
+	}{
+		{
+			name:   "per-asset attestation",
+			assets: []string{mainName, "multiple.intoto.jsonl", mainName + ".intoto.jsonl"},
+			want:   mainName + ".intoto.jsonl",
+		},
+		{
+			name:   "shared attestation",
+			assets: []string{"checksums.txt", "multiple.intoto.jsonl"},
+			want:   "multiple.intoto.jsonl",
+		},
+		{name: "no attestation", assets: []string{mainName, "checksums.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := make([]*github.ReleaseAsset, 0, len(tt.assets))
+			for _, name := range tt.assets {
+				assets = append(assets, &github.ReleaseAsset{Name: github.Ptr(name)})
+			}
+			if got := selectProvenance(assets, mainName); got.GetName() != tt.want {
+				t.Errorf("selectProvenance() = %q, want %q", got.GetName(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_signerIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "github.com",
+			url:  "https://github.com/owner/repo/releases/download/v1.0.0/tool",
+			want: `^https://github\.com/owner/repo/`,
+		},
+		{name: "unknown download URL", want: `^https://github\.com/owner/repo/`},
+		{
+			name:    "GitHub Enterprise Server",
+			url:     "https://ghe.example.com/owner/repo/releases/download/v1.0.0/tool",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset := &github.ReleaseAsset{BrowserDownloadURL: github.Ptr(tt.url)}
+			got, err := signerIdentity(asset, "owner", "repo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("signerIdentity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("signerIdentity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
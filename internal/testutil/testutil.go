@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: MIT
+
+// Package testutil holds the fixtures shared by the tests of several packages: fake
+// release assets, a GitHub client served by a test server and release archives.
+package testutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// Assets builds release assets with consecutive IDs from names, the first 1 KiB in size,
+// the second 2 KiB and so on.
+func Assets(names ...string) []*github.ReleaseAsset {
+	assets := make([]*github.ReleaseAsset, 0, len(names))
+	for i, name := range names {
+		assets = append(assets, &github.ReleaseAsset{
+			Name: github.Ptr(name),
+			ID:   github.Ptr(int64(i + 1)),
+			Size: github.Ptr(1024 * (i + 1)),
+		})
+	}
+	return assets
+}
+
+// NewGitHubClient returns a GitHub client whose API requests are served by handler. The
+// server is closed when the test ends.
+func NewGitHubClient(t *testing.T, handler http.Handler) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to parse test server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+	return client
+}
+
+// TarEntry describes a single file to be written into a test archive.
+type TarEntry struct {
+	Name     string
+	Body     string
+	Mode     int64
+	Typeflag byte // tar.TypeReg when zero
+}
+
+// TarGz builds a .tar.gz archive containing entries.
+func TarGz(t *testing.T, entries ...TarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, e := range entries {
+		typeflag := e.Typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		hdr := &tar.Header{
+			Name:     e.Name,
+			Mode:     e.Mode,
+			Size:     int64(len(e.Body)),
+			Typeflag: typeflag,
+		}
+		if typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.Body)); err != nil {
+				t.Fatalf("Failed to write tar body: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// WriteTarGz writes a .tar.gz archive containing entries to path.
+func WriteTarGz(t *testing.T, path string, entries ...TarEntry) {
+	t.Helper()
+	if err := os.WriteFile(path, TarGz(t, entries...), 0o644); err != nil { //nolint:gosec
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: MIT
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// latestFileName is the name of the file caching resolved latest release tags.
+const latestFileName = "latest.json"
+
+// latestEntry is a latest release tag and when it was resolved.
+type latestEntry struct {
+	Tag       string    `json:"tag"`        // Latest release tag
+	CheckedAt time.Time `json:"checked_at"` // When Tag was resolved
+}
+
+// latestFile is the on-disk layout of the latest release cache.
+type latestFile struct {
+	Latest map[string]latestEntry `json:"latest"` // Keyed by the caller, e.g. host/owner/repo
+}
+
+// LatestFilePath returns the location of the latest release cache,
+// $XDG_DATA_HOME/gh-install/latest.json.
+func LatestFilePath() string {
+	return filepath.Join(xdg.DataHome, appDirName, latestFileName)
+}
+
+// loadLatest reads the latest release cache. A missing or unreadable cache is empty.
+func loadLatest() latestFile {
+	lf := latestFile{Latest: map[string]latestEntry{}}
+	data, err := os.ReadFile(filepath.Clean(LatestFilePath()))
+	if err != nil {
+		return lf
+	}
+	if err := json.Unmarshal(data, &lf); err != nil || lf.Latest == nil {
+		return latestFile{Latest: map[string]latestEntry{}}
+	}
+	return lf
+}
+
+// CachedLatest returns the latest release tag saved for key with SaveLatest, if it was
+// resolved less than ttl ago. The cache only saves API calls, so a missing or corrupt
+// cache file is treated as empty rather than as an error.
+func CachedLatest(key string, ttl time.Duration) (string, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry, ok := loadLatest().Latest[key]
+	if !ok || entry.Tag == "" || time.Since(entry.CheckedAt) >= ttl {
+		return "", false
+	}
+	return entry.Tag, true
+}
+
+// SaveLatest records tag as the latest release for key, resolved now. Entries older
+// than ttl are dropped, keeping the cache small.
+func SaveLatest(key, tag string, ttl time.Duration) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	lf := loadLatest()
+	for k, entry := range lf.Latest {
+		if time.Since(entry.CheckedAt) >= ttl {
+			delete(lf.Latest, k)
+		}
+	}
+	lf.Latest[key] = latestEntry{Tag: tag, CheckedAt: time.Now().UTC()}
+
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode latest release cache: %w", err)
+	}
+	return writeAtomic(LatestFilePath(), append(data, '\n'))
+}
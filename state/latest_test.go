@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLatestFilePath(t *testing.T) {
+	dir := useTempDataHome(t)
+	want := filepath.Join(dir, "gh-install", "latest.json")
+	if got := LatestFilePath(); got != want {
+		t.Errorf("LatestFilePath() = %v, want %v", got, want)
+	}
+}
+
+func TestSaveLatestAndCachedLatest(t *testing.T) {
+	useTempDataHome(t)
+
+	if tag, ok := CachedLatest("github.com/owner/tool", time.Hour); ok {
+		t.Errorf("CachedLatest() = %q from an empty cache", tag)
+	}
+	if err := SaveLatest("github.com/owner/tool", "v1.2.3", time.Hour); err != nil {
+		t.Fatalf("SaveLatest() error = %v", err)
+	}
+	if err := SaveLatest("github.com/owner/other", "v2.0.0", time.Hour); err != nil {
+		t.Fatalf("SaveLatest() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		ttl    time.Duration
+		want   string
+		wantOK bool
+	}{
+		{name: "fresh", key: "github.com/owner/tool", ttl: time.Hour, want: "v1.2.3", wantOK: true},
+		{name: "other key", key: "github.com/owner/other", ttl: time.Hour, want: "v2.0.0", wantOK: true},
+		{name: "expired", key: "github.com/owner/tool", ttl: 0},
+		{name: "unknown", key: "github.com/owner/unknown", ttl: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CachedLatest(tt.key, tt.ttl)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("CachedLatest() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	// Saving with a zero TTL prunes every other entry
+	if err := SaveLatest("github.com/owner/tool", "v1.2.4", 0); err != nil {
+		t.Fatalf("SaveLatest() error = %v", err)
+	}
+	if _, ok := CachedLatest("github.com/owner/other", time.Hour); ok {
+		t.Error("SaveLatest() kept an expired entry")
+	}
+	if got, _ := CachedLatest("github.com/owner/tool", time.Hour); got != "v1.2.4" {
+		t.Errorf("CachedLatest() = %q, want v1.2.4", got)
+	}
+}
+
+func TestCachedLatestCorruptFile(t *testing.T) {
+	useTempDataHome(t)
+	if err := os.MkdirAll(filepath.Dir(LatestFilePath()), 0o755); err != nil {
+		t.Fatalf("Failed to create state dir: %v", err)
+	}
+	if err := os.WriteFile(LatestFilePath(), []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	if tag, ok := CachedLatest("github.com/owner/tool", time.Hour); ok {
+		t.Errorf("CachedLatest() = %q from a corrupt cache", tag)
+	}
+	if err := SaveLatest("github.com/owner/tool", "v1.0.0", time.Hour); err != nil {
+		t.Fatalf("SaveLatest() over a corrupt cache error = %v", err)
+	}
+	if got, ok := CachedLatest("github.com/owner/tool", time.Hour); !ok || got != "v1.0.0" {
+		t.Errorf("CachedLatest() = %q, %v; want v1.0.0, true", got, ok)
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: MIT
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	appDirName    = "gh-install"    // Directory under $XDG_DATA_HOME holding our data
+	stateFileName = "installs.json" // Name of the install-state file
+)
+
+// mu serializes the read-modify-write cycle in Save, as installs from a --config
+// manifest may finish concurrently.
+var mu sync.Mutex
+
+// Record describes a binary installed by gh-install.
+type Record struct {
+	Owner       string    `json:"owner"`                  // Repository owner (user or organization)
+	Repo        string    `json:"repo"`                   // Repository name
+	Tag         string    `json:"tag"`                    // Resolved release tag that was installed
+	AssetName   string    `json:"asset_name"`             // Name of the downloaded release asset
+	Path        string    `json:"path"`                   // Local path of the installed binary
+	Link        string    `json:"link,omitempty"`         // Stable symlink to Path, from --symlink
+	MIMEType    string    `json:"mime_type,omitempty"`    // MIME content type of the release asset
+	Checksum    string    `json:"checksum,omitempty"`     // Verified checksum of the asset, if any
+	Algorithm   string    `json:"algorithm,omitempty"`    // Algorithm used to compute Checksum
+	DownloadURL string    `json:"download_url,omitempty"` // Browser download URL of the asset
+	InstalledAt time.Time `json:"installed_at"`           // When the install happened
+}
+
+// Key returns the owner/repo identifier of the record.
+func (r Record) Key() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// stateFile is the on-disk layout of the install-state file.
+type stateFile struct {
+	Installs []Record `json:"installs"`
+}
+
+// FilePath returns the location of the install-state file,
+// $XDG_DATA_HOME/gh-install/installs.json.
+func FilePath() string {
+	return filepath.Join(xdg.DataHome, appDirName, stateFileName)
+}
+
+// Load reads all install records from the state file.
+// A missing state file is not an error and yields no records.
+func Load() ([]Record, error) {
+	path := FilePath()
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file '%s': %w", path, err)
+	}
+
+	var sf stateFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("failed to parse state file '%s': %w", path, err)
+	}
+	return sf.Installs, nil
+}
+
+// Save adds record to the state file, replacing any existing record for the same owner/repo.
+func Save(record Record) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	records, err := Load()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, r := range records {
+		if r.Key() == record.Key() {
+			records[i] = record
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		records = append(records, record)
+	}
+
+	return write(records)
+}
+
+// Find returns the record for owner/repo and whether one was found.
+func Find(owner, repo string) (Record, bool, error) {
+	records, err := Load()
+	if err != nil {
+		return Record{}, false, err
+	}
+	for _, r := range records {
+		if r.Owner == owner && r.Repo == repo {
+			return r, true, nil
+		}
+	}
+	return Record{}, false, nil
+}
+
+// write persists records to the state file, sorted by owner/repo.
+func write(records []Record) error {
+	sort.Slice(records, func(i, j int) bool { return records[i].Key() < records[j].Key() })
+
+	data, err := json.MarshalIndent(stateFile{Installs: records}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	return writeAtomic(FilePath(), append(data, '\n'))
+}
+
+// writeAtomic writes data to path, creating its directory if needed.
+// The data is written to a temporary file first and renamed into place so a
+// crash mid-write never leaves a truncated file behind.
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil { //nolint:mnd
+		return fmt.Errorf("failed to create state directory '%s': %w", filepath.Dir(path), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for '%s': %w", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()           //nolint:errcheck,gosec
+		os.Remove(tmp.Name()) //nolint:errcheck,gosec
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck,gosec
+		return fmt.Errorf("failed to close '%s': %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck,gosec
+		return fmt.Errorf("failed to replace '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: MIT
+
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// useTempDataHome points $XDG_DATA_HOME at a fresh temporary directory for the test.
+func useTempDataHome(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	return dir
+}
+
+func TestFilePath(t *testing.T) {
+	dir := useTempDataHome(t)
+	want := filepath.Join(dir, "gh-install", "installs.json")
+	if got := FilePath(); got != want {
+		t.Errorf("FilePath() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	useTempDataHome(t)
+
+	records, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Load() = %v, want no records", records)
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	useTempDataHome(t)
+	if err := os.MkdirAll(filepath.Dir(FilePath()), 0o755); err != nil {
+		t.Fatalf("Failed to create state dir: %v", err)
+	}
+	if err := os.WriteFile(FilePath(), []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() expected error for corrupt state file, got nil")
+	}
+}
+
+func TestSave(t *testing.T) {
+	useTempDataHome(t)
+
+	installedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	first := Record{
+		Owner:       "owner",
+		Repo:        "b",
+		Tag:         "v1.0.0",
+		AssetName:   "b_1.0.0_linux_amd64.tar.gz",
+		Path:        "/bin/b",
+		MIMEType:    "application/gzip",
+		Checksum:    "abc",
+		Algorithm:   "sha256",
+		InstalledAt: installedAt,
+	}
+	second := Record{Owner: "owner", Repo: "a", Tag: "v0.1.0", Path: "/bin/a", InstalledAt: installedAt}
+	upgraded := first
+	upgraded.Tag = "v1.1.0"
+	upgraded.Checksum = "def"
+
+	for _, r := range []Record{first, second, upgraded} {
+		if err := Save(r); err != nil {
+			t.Fatalf("Save(%v) error = %v", r, err)
+		}
+	}
+
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// Records are sorted by owner/repo and the second save of owner/b replaces the first
+	want := []Record{second, upgraded}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	useTempDataHome(t)
+
+	record := Record{Owner: "owner", Repo: "tool", Tag: "v1.0.0", Path: "/bin/tool"}
+	if err := Save(record); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		owner     string
+		repo      string
+		want      Record
+		wantFound bool
+	}{
+		{name: "found", owner: "owner", repo: "tool", want: record, wantFound: true},
+		{name: "unknown repo", owner: "owner", repo: "other", wantFound: false},
+		{name: "unknown owner", owner: "someone", repo: "tool", wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := Find(tt.owner, tt.repo)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if found != tt.wantFound {
+				t.Errorf("Find() found = %v, want %v", found, tt.wantFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveConcurrent(t *testing.T) {
+	useTempDataHome(t)
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record := Record{Owner: "owner", Repo: fmt.Sprintf("tool%d", i), Tag: "v1.0.0"}
+			if err := Save(record); err != nil {
+				t.Errorf("Save() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	records, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(records) != n {
+		t.Errorf("Load() returned %d records, want %d", len(records), n)
+	}
+}
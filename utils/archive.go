@@ -0,0 +1,561 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// nonBinaryExts lists extensions of files commonly shipped alongside a binary in a
+// release archive (docs, man pages, shell completions) that are never the binary itself.
+var nonBinaryExts = map[string]bool{
+	".md":    true,
+	".txt":   true,
+	".rst":   true,
+	".html":  true,
+	".1":     true,
+	".json":  true,
+	".yaml":  true,
+	".yml":   true,
+	".bash":  true,
+	".zsh":   true,
+	".fish":  true,
+	".ps1":   true,
+	".pem":   true,
+	".sig":   true,
+	".asc":   true,
+	".sbom":  true,
+	".spdx":  true,
+	".cdx":   true,
+	".plist": true,
+}
+
+// nonBinaryPrefixes lists (upper-cased) base name prefixes of well-known non-binary files.
+var nonBinaryPrefixes = []string{"LICENSE", "LICENCE", "README", "CHANGELOG", "NOTICE", "COPYING"}
+
+// compressedExts lists the extensions of a single compressed file, e.g. a bare binary
+// shipped as "tool.zst".
+var compressedExts = []string{".gz", ".bz2", ".xz", ".zst"}
+
+// decompressTools names the command-line tools decompressing the formats the standard
+// library can't read.
+var decompressTools = map[string]string{".xz": "xz", ".zst": "zstd"}
+
+// IsTarGz reports whether the given filename looks like a gzip-compressed tarball.
+func IsTarGz(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// IsZip reports whether the given filename looks like a zip archive.
+func IsZip(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".zip")
+}
+
+// CompressedExt returns the extension of a single compressed file, e.g. ".zst" for
+// "tool.zst", or "" when name isn't one. A compressed tarball such as "tool.tar.zst" is
+// told apart by its double extension.
+func CompressedExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(lower, ext) && !strings.HasSuffix(lower, ".tar"+ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// IsCompressedFile reports whether the given filename looks like a single compressed file
+// rather than an archive of several, see CompressedExt.
+func IsCompressedFile(name string) bool {
+	return CompressedExt(name) != ""
+}
+
+// IsArchive reports whether the given filename is an archive format that
+// ExtractArchive knows how to unpack (.tar.gz, .tgz, .zip, or a single .gz, .bz2, .xz or
+// .zst compressed file).
+func IsArchive(name string) bool {
+	return IsTarGz(name) || IsZip(name) || IsCompressedFile(name)
+}
+
+// ExtractArchive extracts archivePath into destDir, dispatching on the archive's extension.
+// A single compressed file is decompressed into destDir under its name without the
+// compression extension, e.g. "tool" for "tool.zst"; callers wanting it under another
+// name, such as the binary's, use DecompressFile instead.
+//
+// -ctx: Stops an external decompression tool when done.
+// Returns: The paths of all extracted regular files and an error if extraction fails
+// or the format isn't supported.
+func ExtractArchive(ctx context.Context, archivePath, destDir string) ([]string, error) {
+	switch {
+	case IsTarGz(archivePath):
+		return ExtractTarGz(archivePath, destDir)
+	case IsZip(archivePath):
+		return ExtractZip(archivePath, destDir)
+	case IsCompressedFile(archivePath):
+		base := filepath.Base(archivePath)
+		target := filepath.Join(destDir, base[:len(base)-len(CompressedExt(base))])
+		if err := DecompressFile(ctx, archivePath, target); err != nil {
+			return nil, err
+		}
+		return []string{target}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format: '%s'", filepath.Base(archivePath))
+	}
+}
+
+// ExtractTarGz extracts a gzip-compressed tar archive into destDir.
+// Only directories and regular files are written; other entry types (symlinks,
+// devices, etc.) are skipped. Entries that would escape destDir are rejected.
+//
+// -archivePath: Path to the .tar.gz archive on disk.
+// -destDir: Directory to extract into. It is created if it doesn't exist.
+// Returns: The paths of all extracted regular files and an error if extraction fails.
+func ExtractTarGz(archivePath, destDir string) ([]string, error) {
+	safeArchive := filepath.Clean(archivePath)
+	file, err := os.Open(safeArchive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive '%s': %w", safeArchive, err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	gzr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip stream of '%s': %w", safeArchive, err)
+	}
+	defer gzr.Close() //nolint:errcheck
+
+	if err := os.MkdirAll(destDir, 0o750); err != nil { //nolint:mnd
+		return nil, fmt.Errorf("failed to create extraction directory '%s': %w", destDir, err)
+	}
+
+	var extracted []string
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return extracted, fmt.Errorf("failed to read tar entry in '%s': %w", safeArchive, err)
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if err != nil {
+			return extracted, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o750); err != nil { //nolint:mnd
+				return extracted, fmt.Errorf("failed to create directory '%s': %w", target, err)
+			}
+		case tar.TypeReg:
+			// Preserve the permission bits (including the executable bit) from the header
+			mode := os.FileMode(header.Mode).Perm() //nolint:gosec
+			if err := writeFile(target, tr, mode); err != nil {
+				return extracted, err
+			}
+			Logger.Debugf("Extracted '%s' (mode %04o)", target, mode)
+			extracted = append(extracted, target)
+		default:
+			Logger.Debugf("Skipping unsupported tar entry '%s' (type %c)", header.Name, header.Typeflag)
+		}
+	}
+
+	return extracted, nil
+}
+
+// ExtractZip extracts a zip archive into destDir.
+// Only directories and regular files are written; entries that would escape
+// destDir are rejected.
+//
+// -archivePath: Path to the .zip archive on disk.
+// -destDir: Directory to extract into. It is created if it doesn't exist.
+// Returns: The paths of all extracted regular files and an error if extraction fails.
+func ExtractZip(archivePath, destDir string) ([]string, error) {
+	safeArchive := filepath.Clean(archivePath)
+	zr, err := zip.OpenReader(safeArchive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive '%s': %w", safeArchive, err)
+	}
+	defer zr.Close() //nolint:errcheck
+
+	if err := os.MkdirAll(destDir, 0o750); err != nil { //nolint:mnd
+		return nil, fmt.Errorf("failed to create extraction directory '%s': %w", destDir, err)
+	}
+
+	var extracted []string
+	for _, f := range zr.File {
+		target, err := safeJoin(destDir, f.Name)
+		if err != nil {
+			return extracted, err
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o750); err != nil { //nolint:mnd
+				return extracted, fmt.Errorf("failed to create directory '%s': %w", target, err)
+			}
+		case mode.IsRegular():
+			if err := extractZipFile(f, target); err != nil {
+				return extracted, err
+			}
+			Logger.Debugf("Extracted '%s' (mode %04o)", target, mode.Perm())
+			extracted = append(extracted, target)
+		default:
+			Logger.Debugf("Skipping unsupported zip entry '%s' (mode %s)", f.Name, mode)
+		}
+	}
+
+	return extracted, nil
+}
+
+// DecompressFile decompresses the single compressed file at archivePath (see
+// CompressedExt) to destPath, which is made executable since it's usually a binary.
+// gzip and bzip2 are read directly; xz and zstd need the xz and zstd tools on $PATH.
+//
+// -ctx: Stops the xz or zstd tool when done.
+// -archivePath: Path to the compressed file on disk.
+// -destPath: Path of the decompressed file. Missing parent directories are created.
+// Returns: An error if decompression fails or the format isn't supported.
+func DecompressFile(ctx context.Context, archivePath, destPath string) error {
+	safeArchive := filepath.Clean(archivePath)
+	ext := CompressedExt(safeArchive)
+	if tool, ok := decompressTools[ext]; ok {
+		return decompressWithTool(ctx, tool, safeArchive, destPath)
+	}
+
+	file, err := os.Open(safeArchive)
+	if err != nil {
+		return fmt.Errorf("failed to open compressed file '%s': %w", safeArchive, err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	var r io.Reader
+	switch ext {
+	case ".gz":
+		gzr, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip stream of '%s': %w", safeArchive, err)
+		}
+		defer gzr.Close() //nolint:errcheck
+		r = gzr
+	case ".bz2":
+		r = bzip2.NewReader(file)
+	default:
+		return fmt.Errorf("unsupported compression format: '%s'", filepath.Base(safeArchive))
+	}
+
+	if err := writeFile(destPath, r, 0o755); err != nil { //nolint:mnd
+		return err
+	}
+	Logger.Debugf("Decompressed '%s' to '%s'", safeArchive, destPath)
+	return nil
+}
+
+// decompressWithTool decompresses archivePath to destPath by piping it through tool,
+// e.g. "zstd -dc", for the formats the standard library can't read. The tool is killed
+// when ctx is done.
+func decompressWithTool(ctx context.Context, tool, archivePath, destPath string) error {
+	if !hasCommand(tool) {
+		return fmt.Errorf(
+			"decompressing '%s' requires '%s', which isn't on $PATH",
+			filepath.Base(archivePath),
+			tool,
+		)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, tool, "-dc", archivePath) //nolint:gosec
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", tool, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", tool, err)
+	}
+	if err := writeFile(destPath, stdout, 0o755); err != nil { //nolint:mnd
+		_ = cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf(
+			"failed to decompress '%s' with %s: %w: %s",
+			filepath.Base(archivePath),
+			tool,
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+	Logger.Debugf("Decompressed '%s' to '%s' with %s", archivePath, destPath, tool)
+	return nil
+}
+
+// extractZipFile writes a single zip entry to target, preserving its permission bits.
+// Zip files created on Windows carry no Unix permissions, so those fall back to 0o644.
+func extractZipFile(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open zip entry '%s': %w", f.Name, err)
+	}
+	defer rc.Close() //nolint:errcheck
+
+	mode := f.Mode().Perm()
+	if mode == 0 {
+		mode = 0o644 //nolint:mnd
+	}
+	return writeFile(target, rc, mode)
+}
+
+// safeJoin joins name onto destDir, rejecting names that are absolute or that
+// would resolve to a location outside of destDir (e.g. "../../etc/passwd").
+func safeJoin(destDir, name string) (string, error) {
+	if name == "" || filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("illegal file path in archive: '%s'", name)
+	}
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return "", fmt.Errorf("illegal file path in archive: '%s'", name)
+		}
+	}
+
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	cleanDest := filepath.Clean(destDir)
+	if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path in archive: '%s'", name)
+	}
+	return target, nil
+}
+
+// writeFile copies the contents of r into a newly created file at path with the given mode,
+// creating any missing parent directories.
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil { //nolint:mnd
+		return fmt.Errorf("failed to create directory '%s': %w", filepath.Dir(path), err)
+	}
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create file '%s': %w", path, err)
+	}
+
+	if _, err := io.Copy(out, r); err != nil { //nolint:gosec
+		out.Close() //nolint:errcheck,gosec
+		return fmt.Errorf("failed to write file '%s': %w", path, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// MoveFile moves src to dst. It first attempts a rename and falls back to
+// copying and removing the source when a rename isn't possible (e.g. across filesystems).
+func MoveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat '%s': %w", src, err)
+	}
+
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return fmt.Errorf("failed to open '%s': %w", src, err)
+	}
+	defer in.Close() //nolint:errcheck
+
+	if err := writeFile(dst, in, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// FindBinaryInDir walks an extracted archive and picks the file most likely to be the executable.
+// A file whose name (ignoring a trailing ".exe") matches repoName is preferred. Otherwise the
+// single remaining candidate without a recognized extension is used, or failing that the
+// single candidate with an executable bit set.
+//
+// -dir: The directory the archive was extracted into.
+// -repoName: The preferred binary name, usually the repository name or --binName.
+// Returns: The path of the selected binary, or an error listing the candidates when
+// the choice is ambiguous.
+func FindBinaryInDir(dir, repoName string) (string, error) {
+	candidates, err := binaryCandidates(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if c, ok := findNamedBinary(candidates, repoName); ok {
+		Logger.Debugf("Selected '%s' as binary (name matches '%s')", c, repoName)
+		return c, nil
+	}
+
+	var noExt, executables []string
+	for _, c := range candidates {
+		ext := strings.ToLower(filepath.Ext(c))
+		if ext == "" || ext == ".exe" {
+			noExt = append(noExt, c)
+		}
+		if isExecutable(c) {
+			executables = append(executables, c)
+		}
+	}
+	if len(noExt) == 1 {
+		Logger.Debugf("Selected '%s' as binary (only file without an extension)", noExt[0])
+		return noExt[0], nil
+	}
+	if len(executables) == 1 {
+		Logger.Debugf("Selected '%s' as binary (only executable file)", executables[0])
+		return executables[0], nil
+	}
+
+	return "", fmt.Errorf(
+		"could not determine which file is the binary; candidates: %s (use --binName to choose)",
+		strings.Join(candidateNames(dir, candidates), ", "),
+	)
+}
+
+// FindBinariesInDir walks an extracted archive for several binaries at once, e.g. a tool
+// shipped together with its helpers.
+//
+// -dir: The directory the archive was extracted into.
+// -names: The binaries to find, matched like FindBinaryInDir matches repoName; empty
+// for every file without an extension or with an executable bit set.
+// Returns: The paths of the binaries, in the order of names or of the walk, or an error
+// listing the candidates when one of names isn't in the archive.
+func FindBinariesInDir(dir string, names []string) ([]string, error) {
+	candidates, err := binaryCandidates(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	if len(names) == 0 {
+		for _, c := range candidates {
+			ext := strings.ToLower(filepath.Ext(c))
+			if ext == "" || ext == ".exe" || isExecutable(c) {
+				found = append(found, c)
+			}
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf(
+				"no executables found; candidates: %s",
+				strings.Join(candidateNames(dir, candidates), ", "),
+			)
+		}
+		return found, nil
+	}
+
+	for _, name := range names {
+		c, ok := findNamedBinary(candidates, name)
+		if !ok {
+			return nil, fmt.Errorf(
+				"no binary named '%s' found; candidates: %s",
+				name,
+				strings.Join(candidateNames(dir, candidates), ", "),
+			)
+		}
+		found = append(found, c)
+	}
+	return found, nil
+}
+
+// binaryCandidates lists the regular files under dir that may be a binary, skipping
+// documentation, licenses and similar files.
+func binaryCandidates(dir string) ([]string, error) {
+	var candidates []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if isNonBinaryFile(d.Name()) {
+			Logger.Debugf("Skipping non-binary file '%s'", path)
+			return nil
+		}
+		candidates = append(candidates, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory '%s': %w", dir, err)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no binary candidates found in '%s'", dir)
+	}
+	return candidates, nil
+}
+
+// findNamedBinary returns the candidate named name, ignoring case and a trailing ".exe".
+func findNamedBinary(candidates []string, name string) (string, bool) {
+	if strings.EqualFold(filepath.Ext(name), exeSuffix) {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	for _, c := range candidates {
+		base := filepath.Base(c)
+		if strings.EqualFold(filepath.Ext(base), exeSuffix) {
+			base = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		if strings.EqualFold(base, name) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// isExecutable reports whether any execute bit is set on the file at path.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&(S_IXUSR|S_IXGRP|S_IXOTH) != 0
+}
+
+// candidateNames returns the paths of candidates relative to dir, for error messages.
+func candidateNames(dir string, candidates []string) []string {
+	names := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		rel, err := filepath.Rel(dir, c)
+		if err != nil {
+			rel = filepath.Base(c)
+		}
+		names = append(names, rel)
+	}
+	return names
+}
+
+// isNonBinaryFile reports whether a file name is documentation, a license,
+// a shell completion script, a checksum file or similar non-executable content.
+func isNonBinaryFile(name string) bool {
+	if IsChecksumFile(name) {
+		return true
+	}
+	if nonBinaryExts[strings.ToLower(filepath.Ext(name))] {
+		return true
+	}
+	upper := strings.ToUpper(name)
+	for _, prefix := range nonBinaryPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
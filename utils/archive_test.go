@@ -0,0 +1,579 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/esacteksab/gh-install/internal/testutil"
+)
+
+func TestIsTarGz(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want bool
+	}{
+		{name: "tar.gz", file: "tool_1.0.0_linux_amd64.tar.gz", want: true},
+		{name: "TAR.GZ", file: "TOOL_1.0.0_LINUX_AMD64.TAR.GZ", want: true},
+		{name: "tgz", file: "tool_1.0.0_linux_amd64.tgz", want: true},
+		{name: "zip", file: "tool_1.0.0_linux_amd64.zip", want: false},
+		{name: "bare binary", file: "tool_1.0.0_linux_amd64", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTarGz(tt.file); got != tt.want {
+				t.Errorf("IsTarGz(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractTarGz(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name      string
+		entries   []testutil.TarEntry
+		wantFiles []string
+		wantExec  []string
+		wantErr   bool
+	}{
+		{
+			name: "binary with license and readme",
+			entries: []testutil.TarEntry{
+				{Name: "tool", Body: "#!/bin/sh\necho hi\n", Mode: 0o755},
+				{Name: "LICENSE", Body: "MIT", Mode: 0o644},
+				{Name: "README.md", Body: "# tool", Mode: 0o644},
+			},
+			wantFiles: []string{"tool", "LICENSE", "README.md"},
+			wantExec:  []string{"tool"},
+		},
+		{
+			name: "nested directory",
+			entries: []testutil.TarEntry{
+				{Name: "tool_1.0.0/", Mode: 0o755, Typeflag: tar.TypeDir},
+				{Name: "tool_1.0.0/tool", Body: "binary", Mode: 0o755},
+			},
+			wantFiles: []string{filepath.Join("tool_1.0.0", "tool")},
+			wantExec:  []string{filepath.Join("tool_1.0.0", "tool")},
+		},
+		{
+			name: "symlinks are skipped",
+			entries: []testutil.TarEntry{
+				{Name: "tool", Body: "binary", Mode: 0o755},
+				{Name: "link", Mode: 0o777, Typeflag: tar.TypeSymlink},
+			},
+			wantFiles: []string{"tool"},
+			wantExec:  []string{"tool"},
+		},
+		{
+			name: "path traversal is rejected",
+			entries: []testutil.TarEntry{
+				{Name: "../../evil", Body: "evil", Mode: 0o755},
+			},
+			wantErr: true,
+		},
+		{
+			name: "absolute path is rejected",
+			entries: []testutil.TarEntry{
+				{Name: "/tmp/evil", Body: "evil", Mode: 0o755},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			archivePath := filepath.Join(tempDir, "archive.tar.gz")
+			destDir := filepath.Join(tempDir, "out")
+			testutil.WriteTarGz(t, archivePath, tt.entries...)
+
+			got, err := ExtractTarGz(archivePath, destDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractTarGz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.wantFiles) {
+				t.Fatalf("ExtractTarGz() returned %d files, want %d: %v", len(got), len(tt.wantFiles), got)
+			}
+			for i, want := range tt.wantFiles {
+				if got[i] != filepath.Join(destDir, want) {
+					t.Errorf("ExtractTarGz()[%d] = %s, want %s", i, got[i], filepath.Join(destDir, want))
+				}
+			}
+			for _, exec := range tt.wantExec {
+				info, err := os.Stat(filepath.Join(destDir, exec))
+				if err != nil {
+					t.Fatalf("Expected extracted file %s: %v", exec, err)
+				}
+				if info.Mode().Perm()&S_IXUSR == 0 {
+					t.Errorf("Expected %s to be executable, mode %s", exec, info.Mode())
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarGzNotGzip(t *testing.T) {
+	CreateLogger(true)
+	tempDir := t.TempDir()
+	archivePath := filepath.Join(tempDir, "archive.tar.gz")
+	if err := os.WriteFile(archivePath, []byte("not a gzip stream"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := ExtractTarGz(archivePath, filepath.Join(tempDir, "out")); err == nil {
+		t.Error("ExtractTarGz() expected error for invalid gzip data, got nil")
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "src")
+	dst := filepath.Join(tempDir, "sub", "dst")
+	if err := os.WriteFile(src, []byte("data"), 0o755); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected source to be gone, stat err = %v", err)
+	}
+	content, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("Failed to read destination: %v", err)
+	}
+	if string(content) != "data" {
+		t.Errorf("MoveFile() content = %q, want %q", content, "data")
+	}
+}
+
+// writeTestZip builds a .zip archive at path containing the given entries.
+func writeTestZip(t *testing.T, path string, entries []testutil.TarEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.Name, Method: zip.Deflate}
+		hdr.SetMode(os.FileMode(e.Mode))
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		if _, err := w.Write([]byte(e.Body)); err != nil {
+			t.Fatalf("Failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want bool
+	}{
+		{name: "tar.gz", file: "tool_1.0.0_linux_amd64.tar.gz", want: true},
+		{name: "tgz", file: "tool_1.0.0_linux_amd64.tgz", want: true},
+		{name: "zip", file: "tool_1.0.0_windows_amd64.zip", want: true},
+		{name: "ZIP", file: "TOOL_1.0.0_WINDOWS_AMD64.ZIP", want: true},
+		{name: "deb", file: "tool_1.0.0_linux_amd64.deb", want: false},
+		{name: "exe", file: "tool_1.0.0_windows_amd64.exe", want: false},
+		{name: "bare binary", file: "tool_1.0.0_linux_amd64", want: false},
+		{name: "gzip binary", file: "tool_1.0.0_linux_amd64.gz", want: true},
+		{name: "zstd binary", file: "tool_1.0.0_linux_amd64.zst", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsArchive(tt.file); got != tt.want {
+				t.Errorf("IsArchive(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressedExt(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "tool_linux_amd64.gz", want: ".gz"},
+		{file: "tool_linux_amd64.bz2", want: ".bz2"},
+		{file: "tool_linux_amd64.xz", want: ".xz"},
+		{file: "TOOL_LINUX_AMD64.ZST", want: ".zst"},
+		{file: "tool_linux_amd64.tar.gz", want: ""},
+		{file: "tool_linux_amd64.tar.zst", want: ""},
+		{file: "tool_linux_amd64.tgz", want: ""},
+		{file: "tool_linux_amd64", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := CompressedExt(tt.file); got != tt.want {
+				t.Errorf("CompressedExt(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractArchiveCompressedFile(t *testing.T) {
+	CreateLogger(true)
+	body := "#!/bin/sh\necho tool\n"
+	var gzipped bytes.Buffer
+	gzw := gzip.NewWriter(&gzipped)
+	if _, err := gzw.Write([]byte(body)); err != nil {
+		t.Fatalf("Failed to write gzip stream: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		data     []byte
+		wantFile string
+		wantErr  bool
+	}{
+		{name: "gzip binary", file: "tool_linux_amd64.gz", data: gzipped.Bytes(),
+			wantFile: "tool_linux_amd64"},
+		{name: "corrupt gzip", file: "tool_linux_amd64.gz", data: []byte("not gzip"),
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			archivePath := filepath.Join(tempDir, tt.file)
+			destDir := filepath.Join(tempDir, "out")
+			if err := os.WriteFile(archivePath, tt.data, 0o644); err != nil {
+				t.Fatalf("Failed to write compressed file: %v", err)
+			}
+
+			got, err := ExtractArchive(context.Background(), archivePath, destDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := filepath.Join(destDir, tt.wantFile)
+			if !reflect.DeepEqual(got, []string{want}) {
+				t.Fatalf("ExtractArchive() = %v, want [%s]", got, want)
+			}
+			content, err := os.ReadFile(want)
+			if err != nil {
+				t.Fatalf("Failed to read decompressed file: %v", err)
+			}
+			if string(content) != body {
+				t.Errorf("decompressed content = %q, want %q", content, body)
+			}
+			if !isExecutable(want) {
+				t.Errorf("decompressed file %s is not executable", want)
+			}
+		})
+	}
+}
+
+func TestDecompressFileMissingTool(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	archivePath := filepath.Join(t.TempDir(), "tool.zst")
+	if err := os.WriteFile(archivePath, []byte("zstd"), 0o644); err != nil {
+		t.Fatalf("Failed to write compressed file: %v", err)
+	}
+
+	err := DecompressFile(context.Background(), archivePath, filepath.Join(t.TempDir(), "tool"))
+	if err == nil || !strings.Contains(err.Error(), "requires 'zstd'") {
+		t.Errorf("DecompressFile() error = %v, want a missing zstd error", err)
+	}
+}
+
+func TestDecompressFileCancelled(t *testing.T) {
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nsleep 10\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zstd"), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake zstd: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+	archivePath := filepath.Join(t.TempDir(), "tool.zst")
+	if err := os.WriteFile(archivePath, []byte("zstd"), 0o644); err != nil {
+		t.Fatalf("Failed to write compressed file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := DecompressFile(ctx, archivePath, filepath.Join(t.TempDir(), "tool"))
+	if err == nil {
+		t.Error("DecompressFile() with a cancelled context error = nil, want an error")
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name      string
+		entries   []testutil.TarEntry
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name: "binary with license",
+			entries: []testutil.TarEntry{
+				{Name: "tool.exe", Body: "MZ", Mode: 0o755},
+				{Name: "LICENSE", Body: "MIT", Mode: 0o644},
+			},
+			wantFiles: []string{"tool.exe", "LICENSE"},
+		},
+		{
+			name: "nested directory",
+			entries: []testutil.TarEntry{
+				{Name: "tool_1.0.0/tool", Body: "binary", Mode: 0o755},
+			},
+			wantFiles: []string{filepath.Join("tool_1.0.0", "tool")},
+		},
+		{
+			name: "path traversal is rejected",
+			entries: []testutil.TarEntry{
+				{Name: "../evil", Body: "evil", Mode: 0o755},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			archivePath := filepath.Join(tempDir, "archive.zip")
+			destDir := filepath.Join(tempDir, "out")
+			writeTestZip(t, archivePath, tt.entries)
+
+			got, err := ExtractArchive(context.Background(), archivePath, destDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractZip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.wantFiles) {
+				t.Fatalf("ExtractZip() returned %d files, want %d: %v", len(got), len(tt.wantFiles), got)
+			}
+			for i, want := range tt.wantFiles {
+				if got[i] != filepath.Join(destDir, want) {
+					t.Errorf("ExtractZip()[%d] = %s, want %s", i, got[i], filepath.Join(destDir, want))
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchiveUnsupported(t *testing.T) {
+	if _, err := ExtractArchive(context.Background(), "tool.deb", t.TempDir()); err == nil {
+		t.Error("ExtractArchive() expected error for unsupported format, got nil")
+	}
+}
+
+func TestFindBinaryInDir(t *testing.T) {
+	CreateLogger(true)
+
+	type file struct {
+		name string
+		mode os.FileMode
+	}
+	tests := []struct {
+		name     string
+		files    []file
+		repoName string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "binary with docs and completions",
+			files: []file{
+				{"tool", 0o755},
+				{"LICENSE", 0o644},
+				{"README.md", 0o644},
+				{"completions/tool.bash", 0o644},
+				{"checksums.txt", 0o644},
+			},
+			repoName: "tool",
+			want:     "tool",
+		},
+		{
+			name: "repo name match among several binaries",
+			files: []file{
+				{"tool-helper", 0o755},
+				{"tool", 0o755},
+			},
+			repoName: "tool",
+			want:     "tool",
+		},
+		{
+			name: "windows exe matches repo name",
+			files: []file{
+				{"tool.exe", 0o644},
+				{"LICENSE.txt", 0o644},
+			},
+			repoName: "tool",
+			want:     "tool.exe",
+		},
+		{
+			name: "windows exe matches name with exe",
+			files: []file{
+				{"tool-helper.exe", 0o644},
+				{"tool.exe", 0o644},
+			},
+			repoName: "tool.exe",
+			want:     "tool.exe",
+		},
+		{
+			name: "single file without extension",
+			files: []file{
+				{"gh_2.0.0_linux_amd64/bin/gh", 0o644},
+				{"gh_2.0.0_linux_amd64/share/man/man1/gh.1", 0o644},
+			},
+			repoName: "cli",
+			want:     "gh_2.0.0_linux_amd64/bin/gh",
+		},
+		{
+			name: "single executable",
+			files: []file{
+				{"tool", 0o755},
+				{"NOTES", 0o644},
+			},
+			repoName: "something",
+			want:     "tool",
+		},
+		{
+			name: "ambiguous",
+			files: []file{
+				{"foo", 0o755},
+				{"bar", 0o755},
+			},
+			repoName: "baz",
+			wantErr:  true,
+		},
+		{
+			name: "no candidates",
+			files: []file{
+				{"README.md", 0o644},
+			},
+			repoName: "tool",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				p := filepath.Join(dir, filepath.FromSlash(f.name))
+				if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+					t.Fatalf("Failed to create dir: %v", err)
+				}
+				if err := os.WriteFile(p, []byte(f.name), f.mode); err != nil {
+					t.Fatalf("Failed to write %s: %v", f.name, err)
+				}
+			}
+
+			got, err := FindBinaryInDir(dir, tt.repoName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindBinaryInDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := filepath.Join(dir, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("FindBinaryInDir() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFindBinariesInDir(t *testing.T) {
+	CreateLogger(false)
+
+	dir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{
+		"tool_1.0.0/foo":        0o755,
+		"tool_1.0.0/foo-helper": 0o755,
+		"tool_1.0.0/setup.sh":   0o755,
+		"tool_1.0.0/README.md":  0o644,
+		"tool_1.0.0/data.db":    0o644,
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(name), mode); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "named binaries in the given order",
+			names: []string{"foo-helper", "FOO.exe"},
+			want:  []string{"foo-helper", "foo"},
+		},
+		{
+			name: "every executable",
+			want: []string{"foo", "foo-helper", "setup.sh"},
+		},
+		{
+			name:    "missing name",
+			names:   []string{"foo", "bar"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindBinariesInDir(dir, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindBinariesInDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "foo-helper") {
+					t.Errorf("FindBinariesInDir() error = %q, want the candidates listed", err)
+				}
+				return
+			}
+			want := make([]string, 0, len(tt.want))
+			for _, name := range tt.want {
+				want = append(want, filepath.Join(dir, "tool_1.0.0", name))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindBinariesInDir() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// maxBinNameLength is the longest binary name accepted, the usual filename length limit.
+const maxBinNameLength = 255
+
+// unsafeNameChars matches the characters replaced when sanitizing a binary name.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._+-]+`)
+
+// BinNameData holds the fields available to a --binName template.
+type BinNameData struct {
+	Owner   string // Repository owner
+	Repo    string // Repository name
+	Tag     string // Release tag, e.g. v1.2.3
+	Version string // Release tag without a leading "v", e.g. 1.2.3
+	OS      string // Target operating system, e.g. linux
+	Arch    string // Target architecture, e.g. amd64
+}
+
+// NewBinNameData returns the template data for release tag of owner/repo built for
+// osName/arch.
+func NewBinNameData(owner, repo, tag, osName, arch string) BinNameData {
+	return BinNameData{
+		Owner:   owner,
+		Repo:    repo,
+		Tag:     tag,
+		Version: strings.TrimPrefix(tag, "v"),
+		OS:      osName,
+		Arch:    arch,
+	}
+}
+
+// RenderBinName renders name as a text/template when it contains "{{", e.g.
+// "tool_{{.Version}}_{{.OS}}", and sanitizes the result into a safe filename by
+// replacing path separators and other unusual characters with "_". Names without
+// template markers are returned unchanged.
+//
+// -name: The value of --binName.
+// -data: The fields available to the template.
+// Returns: The name to save the binary as, or an error if the template is invalid or
+// renders to an unusable name.
+func RenderBinName(name string, data BinNameData) (string, error) {
+	if !strings.Contains(name, "{{") {
+		return name, nil
+	}
+
+	tmpl, err := template.New("binName").Option("missingkey=error").Parse(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid binary name template '%s': %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render binary name template '%s': %w", name, err)
+	}
+
+	rendered := unsafeNameChars.ReplaceAllString(b.String(), "_")
+	if strings.Trim(rendered, "._") == "" {
+		return "", fmt.Errorf("binary name template '%s' rendered to an empty name", name)
+	}
+	Logger.Debugf("Rendered binary name template '%s' to '%s'", name, rendered)
+	return rendered, nil
+}
+
+// ValidateBinName checks that name, the value of --binName or a manifest's name, is a
+// plain filename, so the binary can't be written outside the target directory.
+//
+// -name: The name to save the binary as.
+// Returns: The validated name, or an error if it's empty, "." or "..", contains a path
+// separator or a null byte, or is longer than 255 bytes.
+func ValidateBinName(name string) (string, error) {
+	switch {
+	case name == "":
+		return "", errors.New("invalid binary name: name cannot be empty")
+	case name == "." || name == "..":
+		return "", fmt.Errorf("invalid binary name '%s': must be a filename", name)
+	case strings.ContainsAny(name, `/\`) || filepath.Base(name) != name:
+		return "", fmt.Errorf(
+			"invalid binary name '%s': must be a filename only (no directory separators)",
+			name,
+		)
+	case strings.ContainsRune(name, '\x00'):
+		return "", fmt.Errorf("invalid binary name %q: contains a null byte", name)
+	case len(name) > maxBinNameLength:
+		return "", fmt.Errorf(
+			"invalid binary name '%s': exceeds the maximum length of %d",
+			name,
+			maxBinNameLength,
+		)
+	}
+	return name, nil
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBinName(t *testing.T) {
+	CreateLogger(false)
+	data := NewBinNameData("owner", "tool", "v1.2.3", "linux", "amd64")
+
+	tests := []struct {
+		name    string
+		binName string
+		want    string
+		wantErr bool
+	}{
+		{name: "literal", binName: "toml-fmt", want: "toml-fmt"},
+		{name: "literal is not sanitized", binName: "my tool", want: "my tool"},
+		{name: "version and os", binName: "tool_{{.Version}}_{{.OS}}", want: "tool_1.2.3_linux"},
+		{
+			name:    "all fields",
+			binName: "{{.Owner}}-{{.Repo}}-{{.Tag}}-{{.Arch}}",
+			want:    "owner-tool-v1.2.3-amd64",
+		},
+		{name: "path separators sanitized", binName: "../{{.Repo}}/x", want: ".._tool_x"},
+		{name: "spaces sanitized", binName: "{{.Repo}} {{.Version}}", want: "tool_1.2.3"},
+		{name: "unknown field", binName: "{{.Nope}}", wantErr: true},
+		{name: "invalid template", binName: "{{.Repo", wantErr: true},
+		{name: "empty result", binName: `{{""}}..`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderBinName(tt.binName, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderBinName(%q) error = %v, wantErr %v", tt.binName, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderBinName(%q) = %q, want %q", tt.binName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBinName(t *testing.T) {
+	tests := []struct {
+		name    string
+		binName string
+		wantErr bool
+	}{
+		{name: "valid filename", binName: "tool", wantErr: false},
+		{name: "valid with dots and dashes", binName: "tool-v1.2_x.exe", wantErr: false},
+		{name: "empty", binName: "", wantErr: true},
+		{name: "dot", binName: ".", wantErr: true},
+		{name: "dot dot", binName: "..", wantErr: true},
+		{name: "traversal", binName: "../../etc/cron.d/x", wantErr: true},
+		{name: "absolute path", binName: "/usr/local/bin/tool", wantErr: true},
+		{name: "subdirectory", binName: "bin/tool", wantErr: true},
+		{name: "windows separator", binName: `bin\tool`, wantErr: true},
+		{name: "null byte", binName: "tool\x00", wantErr: true},
+		{name: "max length", binName: strings.Repeat("a", 255), wantErr: false},
+		{name: "too long", binName: strings.Repeat("a", 256), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateBinName(tt.binName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateBinName(%q) error = %v, wantErr %v", tt.binName, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.binName {
+				t.Errorf("ValidateBinName(%q) = %q, want it unchanged", tt.binName, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import "errors"
+
+// ErrDiskSpaceUnknown is returned by AvailableBytes on platforms where the free space
+// of a filesystem can't be determined.
+var ErrDiskSpaceUnknown = errors.New("available disk space can't be determined on this platform")
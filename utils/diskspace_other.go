@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: MIT
+
+//go:build !linux && !darwin && !freebsd && !windows
+
+package utils
+
+// AvailableBytes isn't supported on this platform.
+// Returns: ErrDiskSpaceUnknown.
+func AvailableBytes(_ string) (uint64, error) {
+	return 0, ErrDiskSpaceUnknown
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAvailableBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr bool
+	}{
+		{name: "existing directory", dir: t.TempDir()},
+		{name: "missing directory", dir: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AvailableBytes(tt.dir)
+			if errors.Is(err, ErrDiskSpaceUnknown) {
+				t.Skip(err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AvailableBytes(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+			if !tt.wantErr && got == 0 {
+				t.Errorf("AvailableBytes(%q) = 0, want free space", tt.dir)
+			}
+		})
+	}
+}
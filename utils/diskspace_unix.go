@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: MIT
+
+//go:build linux || darwin || freebsd
+
+package utils
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// AvailableBytes returns the space available to unprivileged users on the filesystem
+// holding dir.
+//
+// -dir: An existing directory on the filesystem to check.
+// Returns: The available bytes and an error if the filesystem can't be queried.
+func AvailableBytes(dir string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return 0, fmt.Errorf("failed to get free space of '%s': %w", dir, err)
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil //nolint:gosec,unconvert
+}
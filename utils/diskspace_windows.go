@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// AvailableBytes returns the space available to the current user on the volume
+// holding dir, which respects disk quotas.
+//
+// -dir: An existing directory on the volume to check.
+// Returns: The available bytes and an error if the volume can't be queried.
+func AvailableBytes(dir string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, fmt.Errorf("invalid directory '%s': %w", dir, err)
+	}
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &total, &free); err != nil {
+		return 0, fmt.Errorf("failed to get free space of '%s': %w", dir, err)
+	}
+	return available, nil
+}
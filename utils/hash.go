@@ -3,11 +3,13 @@
 package utils
 
 import (
+	"context"
 	"crypto/md5"  //nolint:gosec
 	"crypto/sha1" //nolint:gosec
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
 	"hash/crc32"
@@ -43,9 +45,13 @@ var algorithmExts = map[string]bool{
 
 // This regex is used by IsChecksumFile to identify general checksum files like "checksums.txt".
 var checksumFileRegex = regexp.MustCompile(
-	`(?i)(^(sha\d*sums?(\.txt)?|md5sums?(\.txt)?|checksums\.txt)$|checksums?(\.txt)?)`,
+	`(?i)(^(sha\d*sums?\d*(\.txt)?|md5sums?(\.txt)?|checksums\.txt)$|checksums?(\.txt)?)`,
 )
 
+// shasumsFileRegex matches Node.js-style checksum manifests, which embed the SHA
+// variant in their name, e.g. "SHASUMS256.txt".
+var shasumsFileRegex = regexp.MustCompile(`(?i)^shasums(\d+)(\.txt)?$`)
+
 // GetHasher returns a new hash.Hash instance for the given algorithm,
 // mirroring GoReleaser's supported algorithms and their specific instantiations.
 func GetHasher(algorithm string) (hash.Hash, error) { //nolint:gocyclo
@@ -94,9 +100,24 @@ func GetHasher(algorithm string) (hash.Hash, error) { //nolint:gocyclo
 	return h, nil
 }
 
+// hashChunkSize is how much of a file HashFileContext hashes between checks of its
+// context.
+const hashChunkSize = 1 << 20 // 1 MiB
+
 // HashFile calculates the specified checksum of a file.
 // Returns the hex-encoded checksum string and an error if any occurs.
 func HashFile(assetPath, algorithm string) (string, error) {
+	return HashFileContext(context.Background(), assetPath, algorithm)
+}
+
+// HashFileContext is like HashFile, but stops with ctx's error once ctx is done, so
+// hashing a large asset respects timeouts and Ctrl-C.
+//
+// -ctx: Checked between chunks of the file.
+// -assetPath: The file to hash.
+// -algorithm: One of the algorithms supported by GetHasher.
+// Returns: The hex-encoded checksum and an error if any occurs.
+func HashFileContext(ctx context.Context, assetPath, algorithm string) (string, error) {
 	safeFile := filepath.Clean(assetPath)
 	file, err := os.Open(safeFile)
 	if err != nil {
@@ -110,13 +131,22 @@ func HashFile(assetPath, algorithm string) (string, error) {
 		return "", fmt.Errorf("failed to initialize hasher for file '%s': %w", safeFile, err)
 	}
 
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", fmt.Errorf(
-			"failed to read data from file '%s' for hashing with %s: %w",
-			safeFile,
-			algorithm,
-			err,
-		)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("hashing '%s' stopped: %w", safeFile, err)
+		}
+		_, err := io.CopyN(hasher, file, hashChunkSize)
+		if errors.Is(err, io.EOF) {
+			break // Hashed the last, partial chunk
+		}
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to read data from file '%s' for hashing with %s: %w",
+				safeFile,
+				algorithm,
+				err,
+			)
+		}
 	}
 
 	checksum := hex.EncodeToString(hasher.Sum(nil))
@@ -132,6 +162,11 @@ func HashFile(assetPath, algorithm string) (string, error) {
 // IsChecksumFile checks if the given filename indicates a checksum file
 // based on common checksum manifest filenames or recognized algorithm extensions.
 func IsChecksumFile(filePath string) bool {
+	// Signatures of checksum files (e.g. "checksums.txt.sig") aren't checksum files themselves
+	if IsSignatureFile(filePath) {
+		return false
+	}
+
 	// Pattern 1: Check for common checksum manifest filenames (e.g., "checksums.txt")
 	// We use filepath.Base to only check the filename itself, not the directory path.
 	if checksumFileRegex.MatchString(filepath.Base(filePath)) {
@@ -148,8 +183,9 @@ func IsChecksumFile(filePath string) bool {
 }
 
 // GetAlgorithmFromFilename attempts to derive the hash algorithm name (e.g., "sha256")
-// from a filename, typically by looking at its extension (e.g., ".sha256").
-// It returns the algorithm name and true if a recognized algorithm extension is found.
+// from a filename, typically by looking at its extension (e.g., ".sha256"), or at the
+// number of a Node.js-style manifest (e.g., "SHASUMS256.txt").
+// It returns the algorithm name and true if a recognized algorithm is found.
 func GetAlgorithmFromFilename(filename string) (string, bool) {
 	ext := filepath.Ext(strings.ToLower(filename)) // Ensure lowercase for map lookup
 	if isKnownExt, ok := algorithmExts[ext]; ok && isKnownExt {
@@ -157,6 +193,11 @@ func GetAlgorithmFromFilename(filename string) (string, bool) {
 		// e.g., ".sha256" -> "sha256"
 		return strings.TrimPrefix(ext, "."), true
 	}
+	if m := shasumsFileRegex.FindStringSubmatch(filepath.Base(filename)); m != nil {
+		if algo := "sha" + m[1]; IsKnownAlgorithm(algo) {
+			return algo, true
+		}
+	}
 	return "", false
 }
 
@@ -168,3 +209,73 @@ func ListSupportedAlgorithms() []string {
 		"sha3-256", "sha3-512",
 	}
 }
+
+// algorithmsByStrength lists the supported algorithms from strongest to weakest.
+// It's used to pick one when a checksum file lists several for the same asset.
+var algorithmsByStrength = []string{
+	"sha3-512", "sha512", "blake2b", "sha3-384", "sha384", "sha3-256", "sha256",
+	"blake2s", "sha3-224", "sha224", "sha1", "md5", "crc32",
+}
+
+// algorithmsByHashLength maps the length of a hex-encoded checksum to the most common
+// algorithm producing it. SHA-3 and BLAKE2 digests share these lengths.
+var algorithmsByHashLength = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	56:  "sha224",
+	64:  "sha256",
+	96:  "sha384",
+	128: "sha512",
+}
+
+// GuessAlgorithmFromHashLength guesses the algorithm of a hex-encoded checksum from its
+// length, for checksum files whose name gives no hint, e.g. a sha512 "checksums.txt".
+//
+// -checksum: The hex-encoded checksum.
+// Returns: The algorithm and true, or false if checksum isn't hex or of a known length.
+func GuessAlgorithmFromHashLength(checksum string) (string, bool) {
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return "", false
+	}
+	algo, ok := algorithmsByHashLength[len(checksum)]
+	return algo, ok
+}
+
+// IsKnownAlgorithm reports whether name (case-insensitive) is a supported checksum algorithm.
+func IsKnownAlgorithm(name string) bool {
+	return algorithmExts["."+strings.ToLower(name)]
+}
+
+// weakAlgorithms are the supported algorithms that aren't collision-resistant: a
+// matching checksum rules out a corrupted download, but not a tampered one.
+var weakAlgorithms = map[string]bool{"crc32": true, "md5": true, "sha1": true}
+
+// IsWeakAlgorithm reports whether name (case-insensitive) is md5, sha1 or crc32, whose
+// checksums a crafted file can be made to match.
+func IsWeakAlgorithm(name string) bool {
+	return weakAlgorithms[strings.ToLower(name)]
+}
+
+// StrongestChecksum picks the checksum to verify from the algorithm -> checksum map
+// returned by ParseChecksumFileMulti. When preferred is set (e.g. from --sha) only that
+// algorithm is considered; otherwise the strongest supported algorithm wins.
+// Entries without an algorithm annotation (the "" key) are never picked.
+//
+// -sums: Checksums keyed by lowercase algorithm name.
+// -preferred: The algorithm the user asked for, or "".
+// Returns: The algorithm, its checksum and true, or false if none applies.
+func StrongestChecksum(sums map[string]string, preferred string) (string, string, bool) {
+	if preferred != "" {
+		preferred = strings.ToLower(preferred)
+		if sum, ok := sums[preferred]; ok {
+			return preferred, sum, true
+		}
+		return "", "", false
+	}
+	for _, algo := range algorithmsByStrength {
+		if sum, ok := sums[algo]; ok {
+			return algo, sum, true
+		}
+	}
+	return "", "", false
+}
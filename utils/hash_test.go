@@ -3,6 +3,10 @@
 package utils
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"maps"
 	"os"
@@ -102,6 +106,35 @@ func TestHashFile(t *testing.T) {
 
 // In utils_test.go
 
+func TestHashFileContext(t *testing.T) {
+	CreateLogger(false)
+	// Span several chunks, ending in a partial one
+	data := make([]byte, 2*hashChunkSize+hashChunkSize/2)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	path := filepath.Join(t.TempDir(), "large.bin")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	sum := sha256.Sum256(data)
+
+	got, err := HashFileContext(context.Background(), path, "sha256")
+	if err != nil {
+		t.Fatalf("HashFileContext() error = %v", err)
+	}
+	if want := hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("HashFileContext() = %s, want %s", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := HashFileContext(ctx, path, "sha256"); !errors.Is(err, context.Canceled) {
+		t.Errorf("HashFileContext() with a cancelled context error = %v, want %v",
+			err, context.Canceled)
+	}
+}
+
 func TestVerifyChecksum(t *testing.T) {
 	CreateLogger(true)
 
@@ -342,6 +375,35 @@ func TestVerifyChecksum(t *testing.T) {
 	})
 }
 
+func TestGuessAlgorithmFromHashLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		checksum string
+		want     string
+		wantOK   bool
+	}{
+		{name: "md5", checksum: strings.Repeat("a", 32), want: "md5", wantOK: true},
+		{name: "sha1", checksum: strings.Repeat("b", 40), want: "sha1", wantOK: true},
+		{name: "sha224", checksum: strings.Repeat("c", 56), want: "sha224", wantOK: true},
+		{name: "sha256", checksum: strings.Repeat("d", 64), want: "sha256", wantOK: true},
+		{name: "sha384", checksum: strings.Repeat("e", 96), want: "sha384", wantOK: true},
+		{name: "sha512", checksum: strings.Repeat("F", 128), want: "sha512", wantOK: true},
+		{name: "crc32 is too short to tell", checksum: "deadbeef"},
+		{name: "unknown length", checksum: strings.Repeat("a", 48)},
+		{name: "not hex", checksum: strings.Repeat("z", 64)},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GuessAlgorithmFromHashLength(tt.checksum)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GuessAlgorithmFromHashLength() = (%q, %t), want (%q, %t)",
+					got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestHashFileAllTheAlgo(t *testing.T) {
 	CreateLogger(false)
 	// --- Setup a dummy file for testing ---
@@ -375,6 +437,11 @@ func TestHashFileAllTheAlgo(t *testing.T) {
 		{"document.pdf", false, ""},
 		{"checksums.txt", true, ""}, // IsChecksumFile=true (regex), GetAlgorithmFromFilename=false
 		{"SHA512SUMS", true, ""},    // IsChecksumFile=true (regex), GetAlgorithmFromFilename=false
+		// Node.js-style manifests name the algorithm with the embedded number
+		{"SHASUMS256.txt", true, "sha256"},
+		{"SHASUMS512.txt", true, "sha512"},
+		{"shasums256", true, "sha256"},
+		{"SHASUMS128.txt", true, ""},
 		{"data.bin.blake2b", true, "blake2b"},
 		{"backup.tar.gz.sha3-512", true, "sha3-512"},
 		{"README.md", false, ""},
@@ -421,3 +488,101 @@ func TestHashFileAllTheAlgo(t *testing.T) {
 		t.Fatal("ERROR: HashFile should have failed for 'nonexistent-file.txt'")
 	}
 }
+
+func TestParseChecksumFileMulti(t *testing.T) {
+	CreateLogger(true)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "tagged sections",
+			content: "MD5 (tool.tar.gz) = aaa\nMD5 (other.tar.gz) = bbb\n" +
+				"SHA256 (tool.tar.gz) = ccc\nSHA256 (other.tar.gz) = ddd\n",
+			want: map[string]string{"md5": "aaa", "sha256": "ccc"},
+		},
+		{
+			name:    "algorithm prefixes",
+			content: "sha256:ccc  tool.tar.gz\nsha512:eee  tool.tar.gz\n",
+			want:    map[string]string{"sha256": "ccc", "sha512": "eee"},
+		},
+		{
+			name:    "no annotation",
+			content: "ccc  tool.tar.gz\n",
+			want:    map[string]string{"": "ccc"},
+		},
+		{
+			name:    "target missing",
+			content: "ccc  other.tar.gz\n",
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("sums-%d", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			got, err := ParseChecksumFileMulti(path, "tool.tar.gz")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChecksumFileMulti() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("ParseChecksumFileMulti() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrongestChecksum(t *testing.T) {
+	sums := map[string]string{"md5": "aaa", "sha256": "ccc", "sha1": "fff", "": "zzz"}
+	tests := []struct {
+		name      string
+		sums      map[string]string
+		preferred string
+		wantAlgo  string
+		wantSum   string
+		wantOK    bool
+	}{
+		{name: "strongest", sums: sums, wantAlgo: "sha256", wantSum: "ccc", wantOK: true},
+		{name: "preferred", sums: sums, preferred: "MD5", wantAlgo: "md5", wantSum: "aaa", wantOK: true},
+		{name: "preferred missing", sums: sums, preferred: "sha512"},
+		{name: "unannotated only", sums: map[string]string{"": "zzz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algo, sum, ok := StrongestChecksum(tt.sums, tt.preferred)
+			if algo != tt.wantAlgo || sum != tt.wantSum || ok != tt.wantOK {
+				t.Errorf(
+					"StrongestChecksum() = (%q, %q, %v), want (%q, %q, %v)",
+					algo, sum, ok, tt.wantAlgo, tt.wantSum, tt.wantOK,
+				)
+			}
+		})
+	}
+}
+
+func TestIsWeakAlgorithm(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "md5", want: true},
+		{name: "SHA1", want: true},
+		{name: "crc32", want: true},
+		{name: "sha256", want: false},
+		{name: "sha3-224", want: false},
+		{name: "blake2s", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWeakAlgorithm(tt.name); got != tt.want {
+				t.Errorf("IsWeakAlgorithm(%q) = %t, want %t", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	HookPathEnv    = "GH_INSTALL_PATH"    // Environment variable with the installed binary's path
+	HookBinEnv     = "GH_INSTALL_BIN"     // Environment variable with the installed binary's name
+	HookVersionEnv = "GH_INSTALL_VERSION" // Environment variable with the installed release tag
+)
+
+// RunPostInstallHook runs command through the shell (sh, or cmd on Windows) after a
+// binary was installed, with GH_INSTALL_PATH, GH_INSTALL_BIN and GH_INSTALL_VERSION
+// describing the install. The hook's output is logged line by line.
+//
+// - ctx: Cancels the hook when done.
+// - command: The command line to run, e.g. "sudo setcap cap_net_raw+ep $GH_INSTALL_PATH".
+// - path: The path the binary was installed to.
+// - version: The release tag that was installed.
+// Returns: An error if the hook can't be started or exits non-zero.
+func RunPostInstallHook(ctx context.Context, command, path, version string) error {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	Logger.Infof("Running post-install hook: %s", command)
+	cmd := exec.CommandContext(ctx, shell, flag, command) //nolint:gosec
+	cmd.Env = append(
+		os.Environ(),
+		HookPathEnv+"="+path,
+		HookBinEnv+"="+filepath.Base(path),
+		HookVersionEnv+"="+version,
+	)
+	out, err := cmd.CombinedOutput()
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\r\n"), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			Logger.Info("  " + line)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("post-install hook '%s' failed: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunPostInstallHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks require a POSIX shell")
+	}
+	CreateLogger(true)
+
+	tests := []struct {
+		name    string
+		command string
+		want    string // Substring of the logged output
+		wantErr bool
+	}{
+		{
+			name:    "environment",
+			command: `echo "$GH_INSTALL_PATH|$GH_INSTALL_BIN|$GH_INSTALL_VERSION"`,
+			want:    "/opt/bin/tool|tool|v1.2.3",
+		},
+		{
+			name:    "output on stderr",
+			command: "echo oops >&2",
+			want:    "oops",
+		},
+		{
+			name:    "non-zero exit",
+			command: "echo failing; exit 3",
+			want:    "failing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logFile := filepath.Join(t.TempDir(), "hook.log")
+			if err := SetLogFile(logFile, true); err != nil {
+				t.Fatalf("SetLogFile() error = %v", err)
+			}
+			t.Cleanup(func() { CreateLogger(true) })
+
+			err := RunPostInstallHook(context.Background(), tt.command, "/opt/bin/tool", "v1.2.3")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunPostInstallHook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			logged, _ := os.ReadFile(logFile)
+			if !strings.Contains(string(logged), tt.want) {
+				t.Errorf("RunPostInstallHook() logged %q, want %q", logged, tt.want)
+			}
+		})
+	}
+}
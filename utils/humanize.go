@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import "fmt"
+
+// byteUnits are the binary prefixes HumanBytes steps through.
+var byteUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// HumanBytes formats a byte count for people, e.g. 1536 as "1.5 KiB".
+//
+// -n: The number of bytes.
+// Returns: n in bytes below 1 KiB, otherwise in the largest binary unit that keeps
+// the value at or above 1, with one decimal.
+func HumanBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / unit
+	i := 0
+	for (value >= unit || value <= -unit) && i < len(byteUnits)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, byteUnits[i])
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1024, want: "1.0 KiB"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 10 * 1024 * 1024, want: "10.0 MiB"},
+		{n: 3 << 30, want: "3.0 GiB"},
+		{n: math.MaxInt64, want: "8.0 EiB"},
+		{n: -2048, want: "-2.0 KiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := HumanBytes(tt.n); got != tt.want {
+				t.Errorf("HumanBytes(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
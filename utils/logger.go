@@ -3,8 +3,12 @@
 package utils
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
@@ -13,13 +17,6 @@ import (
 // The commented out code below was in the original file.
 // It's preserved here for reference but is not currently used.
 //
-// validFilenameChars = regexp.MustCompile(`^[a-zA-Z0-9_\-\.]+$`)
-//
-// const (
-// 	// Max filename length (common limit)
-// 	maxFilenameLength = 255
-// )
-//
 // doesExist checks if a file or directory exists at the specified path.
 //
 // This function uses os.Stat to determine if the path exists in the filesystem.
@@ -38,12 +35,42 @@ import (
 // 	return true
 // }
 
-// CreateLogger creates and configures the package-level Logger instance
-// based on the desired verbosity. This function can create a new logger
-// or reconfigure an existing one.
+// Log formats accepted by SetLogFormat.
+const (
+	LogFormatText   = "text"   // Styled, human-readable output; the default
+	LogFormatJSON   = "json"   // One JSON object per line
+	LogFormatLogfmt = "logfmt" // key=value pairs, one line per message
+)
+
+var (
+	// loggerOnce creates the package-level Logger; it's never replaced afterwards
+	loggerOnce sync.Once
+	// loggerMu serializes reconfiguring the Logger, which takes several setter calls
+	loggerMu sync.Mutex
+)
+
+// EnsureLogger creates the package-level Logger with CreateLogger's defaults unless it
+// already exists, for code that may run before CreateLogger, e.g. install.Install
+// embedded in another program. It's safe for concurrent use.
+func EnsureLogger() {
+	loggerOnce.Do(func() {
+		Logger = log.NewWithOptions(os.Stderr, log.Options{Level: log.InfoLevel})
+		Logger.SetStyles(textStyles())
+		log.SetDefault(Logger)
+	})
+}
+
+// CreateLogger configures the package-level Logger, creating it on the first call,
+// based on the desired verbosity. Calls are serialized, but as charmbracelet/log reads
+// its styles unguarded, configure the Logger before goroutines start logging through it;
+// logging from several goroutines is safe after that.
 //
 // -verbose: Boolean indicating if debug-level logging should be enabled.
 func CreateLogger(verbose bool) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	EnsureLogger()
+
 	var level log.Level
 	var reportCaller, reportTimestamp bool
 	var timeFormat string
@@ -63,31 +90,25 @@ func CreateLogger(verbose bool) {
 		level = log.InfoLevel   // Only show info-level and higher messages
 	}
 
-	// Use a local variable first before assigning to the package-level Logger
-	var instanceToUse *log.Logger
-
-	// Create a new logger if one doesn't exist yet
-	if Logger == nil {
-		instanceToUse = log.NewWithOptions(os.Stderr, log.Options{
-			ReportCaller:    reportCaller,    // Whether to include caller info
-			ReportTimestamp: reportTimestamp, // Whether to show timestamps
-			TimeFormat:      timeFormat,      // Format for timestamps
-			Level:           level,           // Minimum log level to display
-		})
-
-		// Safety check for logger creation
-		if instanceToUse == nil {
-			os.Exit(1) // Exit if logger creation failed
-		}
-	} else {
-		// Reconfigure the existing logger if it already exists
-		instanceToUse = Logger
-		instanceToUse.SetLevel(level)                     // Update log level
-		instanceToUse.SetReportTimestamp(reportTimestamp) // Update timestamp display
-		instanceToUse.SetTimeFormat(timeFormat)           // Update time format
-		instanceToUse.SetReportCaller(reportCaller)       // Update caller reporting
-	}
+	// Reconfigure the logger in place; callers may hold on to it
+	Logger.SetLevel(level)                     // Update log level
+	Logger.SetReportTimestamp(reportTimestamp) // Update timestamp display
+	Logger.SetTimeFormat(timeFormat)           // Update time format
+	Logger.SetReportCaller(reportCaller)       // Update caller reporting
+	Logger.SetFormatter(log.TextFormatter)     // Restore the default format
+	Logger.SetStyles(textStyles())
+
+	// Log the configuration at debug level
+	// This will only be visible if verbose mode is enabled
+	Logger.Debugf(
+		"Logger configured. Verbose: %t, Level set to: %s",
+		verbose,
+		Logger.GetLevel(),
+	)
+}
 
+// textStyles returns the styles of the text format.
+func textStyles() *log.Styles {
 	// Configure custom styles for log levels
 	maxWidth := 4 // Width for level display in log messages
 	styles := log.DefaultStyles()
@@ -102,99 +123,92 @@ func CreateLogger(verbose bool) {
 		SetString(strings.ToUpper(log.FatalLevel.String())).          // "FATAL"
 		Bold(true).MaxWidth(maxWidth).Foreground(lipgloss.Color("9")) // Red color
 
-	// Apply the styles to the logger
-	instanceToUse.SetStyles(styles)
-
-	// Set the package-level Logger variable to our configured instance
-	Logger = instanceToUse
+	// Info messages are the regular, user-facing output; print them without a level
+	// prefix so they read like plain output but are still hidden by SetQuiet
+	delete(styles.Levels, log.InfoLevel)
+	return styles
+}
 
-	// Also set this as the default logger for the log package
-	log.SetDefault(Logger)
+// CreateLoggerWithFormat is like CreateLogger, but writes messages in the given format.
+//
+// -verbose: Boolean indicating if debug-level logging should be enabled.
+// -format: One of LogFormatText, LogFormatJSON or LogFormatLogfmt.
+// Returns: An error if format isn't known.
+func CreateLoggerWithFormat(verbose bool, format string) error {
+	CreateLogger(verbose)
+	return SetLogFormat(format)
+}
 
-	// Final verification that Logger was properly initialized
-	if Logger != nil {
-		// Log the configuration at debug level
-		// This will only be visible if verbose mode is enabled
-		Logger.Debugf(
-			"Logger configured. Verbose: %t, Level set to: %s",
-			verbose,
-			Logger.GetLevel(),
+// SetLogFormat switches the package-level Logger to the given format, e.g. to feed
+// log pipelines. It must be called after CreateLogger.
+//
+// -format: One of LogFormatText, LogFormatJSON or LogFormatLogfmt.
+// Returns: An error if format isn't known.
+func SetLogFormat(format string) error {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	switch format {
+	case LogFormatText:
+		Logger.SetFormatter(log.TextFormatter)
+		Logger.SetStyles(textStyles())
+	case LogFormatJSON:
+		Logger.SetFormatter(log.JSONFormatter)
+		// The structured formats take the level from the styles; keep the info level
+		Logger.SetStyles(log.DefaultStyles())
+	case LogFormatLogfmt:
+		Logger.SetFormatter(log.LogfmtFormatter)
+		Logger.SetStyles(log.DefaultStyles())
+	default:
+		return fmt.Errorf(
+			"invalid log format '%s': must be '%s', '%s' or '%s'",
+			format,
+			LogFormatText,
+			LogFormatJSON,
+			LogFormatLogfmt,
 		)
 	}
+	return nil
 }
 
-// The commented out code below was in the original file.
-// It's preserved here for reference but is not currently used.
-//
-// validateFilePath checks if a given path string represents a simple, safe filename
-// intended for use within the current directory.
-// It performs checks for:
-// - Emptiness
-// - Directory traversal components (e.g., "..", "/") after cleaning
-// - Allowed characters (alphanumeric, underscore, hyphen, period)
-// - Maximum length
-// - Null bytes
-//
-// Parameters:
-//
-//	path - The input path string to validate.
-//
-// Returns:
-//
-//	string - The validated simple filename (without "./") if validation succeeds.
-//	error - An error detailing the validation failure if any check fails. On failure,
-//	        the returned string is the original input path.
-// func validateFilePath(path string) (string, error) {
-// 	// --- Validate the filename parameter ---
-// 	if path == "" {
-// 		err := errors.New("invalid file path: filename cannot be empty")
-// 		// Return original path (empty) and error
-// 		return path, err
-// 	}
-//
-// 	// 1. Basic cleaning (removes ., .., extra slashes)
-// 	validatedFilename := filepath.Clean(path)
-//
-// 	// 2. Enforce filename only (check for separators *after* cleaning)
-// 	//    Also reject "." and ".." explicitly as filenames.
-// 	if filepath.Base(validatedFilename) != validatedFilename || validatedFilename == "." ||
-// 		validatedFilename == ".." {
-// 		err := fmt.Errorf(
-// 			"invalid file path: %q must be a filename only (no directory separators)",
-// 			path, // Use original path in error message for clarity
-// 		)
-// 		// Return original path and error
-// 		return path, err
-// 	}
-//
-// 	// 3. Check for allowed characters using regex
-// 	if !validFilenameChars.MatchString(validatedFilename) {
-// 		err := fmt.Errorf(
-// 			"invalid file path: filename %q contains invalid characters (allowed: a-z, A-Z, 0-9, _, -, .)",
-// 			validatedFilename, // Use validated filename here as it's the one checked
-// 		)
-// 		// Return original path and error
-// 		return path, err
-// 	}
-//
-// 	// 4. Check filename length
-// 	if len(validatedFilename) > maxFilenameLength {
-// 		err := fmt.Errorf(
-// 			"invalid file path: filename %q exceeds maximum length of %d",
-// 			validatedFilename,
-// 			maxFilenameLength,
-// 		)
-// 		// Return original path and error
-// 		return path, err
-// 	}
-//
-// 	// 5. Check for null bytes
-// 	if strings.ContainsRune(validatedFilename, '\x00') {
-// 		err := fmt.Errorf("invalid file path: filename %q contains null byte", validatedFilename)
-// 		// Return original path and error
-// 		return path, err
-// 	}
-//
-// 	// If all checks pass, return the validated filename (which is just the base name) and nil error
-// 	return validatedFilename, nil
-// }
+// logFile is the file opened by SetLogFile, closed when it's replaced; guarded by
+// loggerMu.
+var logFile *os.File
+
+// SetLogFile makes the package-level Logger also write to the file at path, appending
+// to it, e.g. to keep a log of cron runs. It must be called after CreateLogger.
+//
+// -path: The log file; created with owner-only permissions if it doesn't exist.
+// -only: Write to the file alone instead of to stderr and the file.
+// Returns: An error if the file can't be opened, in which case the Logger keeps
+// writing to stderr.
+func SetLogFile(path string, only bool) error {
+	f, err := os.OpenFile( //nolint:gosec
+		filepath.Clean(path),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		0o600, //nolint:mnd
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open log file '%s': %w", path, err)
+	}
+
+	var out io.Writer = f
+	if !only {
+		out = io.MultiWriter(os.Stderr, f)
+	}
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	Logger.SetOutput(out)
+	if logFile != nil {
+		logFile.Close() //nolint:errcheck,gosec
+	}
+	logFile = f
+	return nil
+}
+
+// SetQuiet restricts the package-level Logger to errors, hiding informational
+// messages and warnings. It must be called after CreateLogger.
+func SetQuiet() {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	Logger.SetLevel(log.ErrorLevel)
+}
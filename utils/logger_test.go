@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetQuiet(t *testing.T) {
+	CreateLogger(false)
+	var buf bytes.Buffer
+	Logger.SetOutput(&buf)
+	defer func() {
+		Logger.SetOutput(os.Stderr)
+		CreateLogger(false)
+	}()
+
+	Logger.Info("informational")
+	if !strings.Contains(buf.String(), "informational") {
+		t.Errorf("Info message missing before SetQuiet: %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "INFO") {
+		t.Errorf("Info message printed with a level prefix: %q", buf.String())
+	}
+
+	buf.Reset()
+	SetQuiet()
+	Logger.Info("informational")
+	Logger.Warn("warning")
+	Logger.Error("failure")
+	if got := buf.String(); strings.Contains(got, "informational") ||
+		strings.Contains(got, "warning") || !strings.Contains(got, "failure") {
+		t.Errorf("SetQuiet() output = %q, want only the error", got)
+	}
+}
+
+// TestLogger_concurrent logs from several goroutines, as concurrent --config installs do;
+// run it with -race.
+func TestLogger_concurrent(t *testing.T) {
+	CreateLogger(false)
+	var buf bytes.Buffer
+	Logger.SetOutput(&buf)
+	defer func() {
+		Logger.SetOutput(os.Stderr)
+		CreateLogger(false)
+	}()
+
+	const goroutines, messages = 8, 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			EnsureLogger() // As install.Install does
+			for j := range messages {
+				Logger.Infof("goroutine %d message %d", i, j)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := strings.Count(buf.String(), "\n"); got != goroutines*messages {
+		t.Errorf("logged %d lines, want %d", got, goroutines*messages)
+	}
+}
+
+func TestCreateLoggerWithFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+		check   func(t *testing.T, line string)
+	}{
+		{
+			format: LogFormatJSON,
+			check: func(t *testing.T, line string) {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("output %q is not valid JSON: %v", line, err)
+				}
+				if entry["msg"] != "installed" || entry["level"] != "info" ||
+					entry["tool"] != "gh" {
+					t.Errorf("JSON entry = %v, want msg, level and tool fields", entry)
+				}
+			},
+		},
+		{
+			format: LogFormatLogfmt,
+			check: func(t *testing.T, line string) {
+				for _, want := range []string{"level=info", "msg=installed", "tool=gh"} {
+					if !strings.Contains(line, want) {
+						t.Errorf("logfmt output %q is missing %q", line, want)
+					}
+				}
+			},
+		},
+		{
+			format: LogFormatText,
+			check: func(t *testing.T, line string) {
+				if !strings.HasPrefix(line, "installed") {
+					t.Errorf("text output = %q, want the plain message", line)
+				}
+			},
+		},
+		{format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			err := CreateLoggerWithFormat(false, tt.format)
+			var buf bytes.Buffer
+			Logger.SetOutput(&buf)
+			defer func() {
+				Logger.SetOutput(os.Stderr)
+				CreateLogger(false)
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateLoggerWithFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			Logger.Info("installed", "tool", "gh")
+			tt.check(t, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestSetLogFile(t *testing.T) {
+	CreateLogger(false)
+	defer func() {
+		Logger.SetOutput(os.Stderr)
+		if logFile != nil {
+			logFile.Close() //nolint:errcheck
+			logFile = nil
+		}
+	}()
+
+	path := filepath.Join(t.TempDir(), "gh-install.log")
+	if err := os.WriteFile(path, []byte("earlier run\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+	if err := SetLogFile(path, true); err != nil {
+		t.Fatalf("SetLogFile() error = %v", err)
+	}
+	Logger.Info("installed gh")
+	Logger.Debug("hidden at info level")
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.HasPrefix(string(got), "earlier run\n") {
+		t.Errorf("log file = %q, want the earlier contents kept", got)
+	}
+	if !strings.Contains(string(got), "installed gh") || strings.Contains(string(got), "hidden") {
+		t.Errorf("log file = %q, want only the info message appended", got)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing", "gh-install.log")
+	if err := SetLogFile(missing, false); err == nil {
+		t.Error("SetLogFile() in a missing directory succeeded")
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// metadataSuffixes lists the file name suffixes of SBOMs and attestations published
+// next to release assets.
+var metadataSuffixes = []string{
+	".sbom",
+	".sbom.json",
+	".spdx",
+	".spdx.json",
+	".cdx.json",
+	".cyclonedx.json",
+	".att",
+	provenanceSuffix,
+}
+
+// sourceArchiveRegex matches source code archives, e.g. GitHub's "Source code (tar.gz)"
+// or "tool-1.0.0-src.tar.gz".
+var sourceArchiveRegex = regexp.MustCompile(
+	`(?i)^source code\b|(?:^|[-_.])(?:src|source)\.(?:tar(?:\.(?:gz|bz2|xz|zst))?|tgz|zip)$`,
+)
+
+// sourceArchiveExts are the extensions of the source archives GitHub generates for a
+// tag, e.g. v1.0.0.tar.gz.
+var sourceArchiveExts = []string{".tar.gz", ".zip"}
+
+// IsMetadataAsset reports whether the given filename is an SBOM, an attestation or a
+// source archive: release assets describing the build rather than installable ones.
+func IsMetadataAsset(filePath string) bool {
+	name := strings.ToLower(filepath.Base(filePath))
+	for _, suffix := range metadataSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return IsSourceArchive(name, "")
+}
+
+// IsSourceArchive reports whether the given filename is a source code archive, either
+// named like one or named after the release tag alone, as GitHub names the archives it
+// generates for a tag.
+//
+// -filePath: The asset name.
+// -tag: The release tag, e.g. v1.0.0; empty to only go by the name.
+func IsSourceArchive(filePath, tag string) bool {
+	name := filepath.Base(filePath)
+	if sourceArchiveRegex.MatchString(name) {
+		return true
+	}
+	if tag == "" {
+		return false
+	}
+	tag = strings.ToLower(tag)
+	for _, ext := range sourceArchiveExts {
+		base, ok := strings.CutSuffix(strings.ToLower(name), ext)
+		if ok && (base == tag || base == strings.TrimPrefix(tag, "v")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import "testing"
+
+func TestIsMetadataAsset(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "tool_linux_amd64.tar.gz.sbom.json", want: true},
+		{file: "tool_1.0.0_linux_amd64.sbom", want: true},
+		{file: "tool_linux_amd64.spdx.json", want: true},
+		{file: "tool_linux_amd64.cdx.json", want: true},
+		{file: "tool_linux_amd64.tar.gz.att", want: true},
+		{file: "multiple.intoto.jsonl", want: true},
+		{file: "Source code (tar.gz)", want: true},
+		{file: "tool-1.0.0-src.tar.gz", want: true},
+		{file: "tool_1.0.0_source.zip", want: true},
+		{file: "tool_linux_amd64.tar.gz", want: false},
+		{file: "sourcegraph_linux_amd64", want: false},
+		{file: "spdx-tool_linux_amd64.tar.gz", want: false},
+		{file: "checksums.txt", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsMetadataAsset(tt.file); got != tt.want {
+				t.Errorf("IsMetadataAsset(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSourceArchive(t *testing.T) {
+	tests := []struct {
+		file string
+		tag  string
+		want bool
+	}{
+		{file: "Source code (zip)", want: true},
+		{file: "Source code (tar.gz)", tag: "v1.0.0", want: true},
+		{file: "tool-1.0.0-src.tar.gz", want: true},
+		{file: "v1.0.0.tar.gz", tag: "v1.0.0", want: true},
+		{file: "v1.0.0.zip", tag: "v1.0.0", want: true},
+		{file: "1.0.0.tar.gz", tag: "v1.0.0", want: true},
+		{file: "v1.0.0.tar.gz", want: false},
+		{file: "v1.0.1.tar.gz", tag: "v1.0.0", want: false},
+		{file: "tool_v1.0.0_linux_amd64.tar.gz", tag: "v1.0.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsSourceArchive(tt.file, tt.tag); got != tt.want {
+				t.Errorf("IsSourceArchive(%q, %q) = %v, want %v", tt.file, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// OS families whose native package format InstallSystemPackage knows how to install.
+const (
+	FamilyDebian = "debian" // .deb packages, installed with apt-get or dpkg
+	FamilyRHEL   = "rhel"   // .rpm packages, installed with dnf or rpm
+	FamilySUSE   = "suse"   // .rpm packages, installed with zypper or rpm
+	FamilyAlpine = "alpine" // .apk packages, installed with apk
+)
+
+// osReleasePath is the file describing the running Linux distribution.
+var osReleasePath = "/etc/os-release"
+
+// familyByID maps the distribution IDs found in os-release's ID and ID_LIKE to their family.
+// The families are keys too, so either can be looked up. Distributions without a .deb,
+// .rpm or .apk package manager, e.g. arch, gentoo or nixos, have no family.
+var familyByID = map[string]string{
+	"debian":       FamilyDebian,
+	"ubuntu":       FamilyDebian,
+	"linuxmint":    FamilyDebian,
+	"raspbian":     FamilyDebian,
+	"pop":          FamilyDebian,
+	"kali":         FamilyDebian,
+	"devuan":       FamilyDebian,
+	"elementary":   FamilyDebian,
+	"zorin":        FamilyDebian,
+	"neon":         FamilyDebian,
+	"rhel":         FamilyRHEL,
+	"fedora":       FamilyRHEL,
+	"centos":       FamilyRHEL,
+	"rocky":        FamilyRHEL,
+	"almalinux":    FamilyRHEL,
+	"amzn":         FamilyRHEL,
+	"ol":           FamilyRHEL,
+	"scientific":   FamilyRHEL,
+	"suse":         FamilySUSE,
+	"opensuse":     FamilySUSE,
+	"sles":         FamilySUSE,
+	"sled":         FamilySUSE,
+	"alpine":       FamilyAlpine,
+	"postmarketos": FamilyAlpine,
+}
+
+// packageExtByFamily maps each family to the extension of its package format.
+var packageExtByFamily = map[string]string{
+	FamilyDebian: ".deb",
+	FamilyRHEL:   ".rpm",
+	FamilySUSE:   ".rpm",
+	FamilyAlpine: ".apk",
+}
+
+// IsSystemPackage reports whether the given filename is a .deb, .rpm or .apk package.
+func IsSystemPackage(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".deb", ".rpm", ".apk":
+		return true
+	default:
+		return false
+	}
+}
+
+// familyForID returns the family of a distribution ID or family, case-insensitively.
+func familyForID(id string) (string, bool) {
+	id = strings.ToLower(id)
+	if strings.HasPrefix(id, "opensuse") {
+		id = "opensuse" // opensuse-leap, opensuse-tumbleweed
+	}
+	family, ok := familyByID[id]
+	return family, ok
+}
+
+// PackageExtForFamily returns the extension of the package format of family, which may
+// also be a distribution ID such as "fedora" or "ubuntu".
+// Returns: The extension, e.g. ".rpm", and false when family has no supported format.
+func PackageExtForFamily(family string) (ext string, ok bool) {
+	if family, ok = familyForID(family); !ok {
+		return "", false
+	}
+	ext, ok = packageExtByFamily[family]
+	return ext, ok
+}
+
+// PackageMatchesFamily reports whether the package name uses the package format of family.
+func PackageMatchesFamily(name, family string) bool {
+	ext, ok := PackageExtForFamily(family)
+	return ok && strings.EqualFold(filepath.Ext(name), ext)
+}
+
+// DetectOSFamily determines the family of the running Linux distribution from
+// /etc/os-release. Returns: One of the Family constants, or "" on other operating
+// systems and unknown distributions.
+func DetectOSFamily() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	data, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		Logger.Debugf("Could not read '%s': %v", osReleasePath, err)
+		return ""
+	}
+	family := osFamilyFromOSRelease(string(data))
+	Logger.Debugf("Detected OS family: '%s'", family)
+	return family
+}
+
+// ResolveOSFamily returns the family system packages must fit: override when it's set,
+// e.g. from --os-family where detection fails in containers or minimal images, and
+// DetectOSFamily's otherwise.
+//
+// -override: A family or a distribution ID such as "ubuntu"; empty to detect it.
+// Returns: One of the Family constants, or "" when none applies.
+func ResolveOSFamily(override string) string {
+	if override == "" {
+		return DetectOSFamily()
+	}
+	family, ok := familyForID(override)
+	if !ok {
+		Logger.Warnf("Unknown OS family '%s'; no system package will match", override)
+		return ""
+	}
+	Logger.Debugf("Using OS family '%s' from --os-family", family)
+	return family
+}
+
+// osFamilyFromOSRelease maps the ID, then each ID_LIKE entry, of an os-release file
+// to a family. Returns: The first family found, or "" if none is known.
+func osFamilyFromOSRelease(content string) string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok {
+			fields[key] = strings.Trim(value, `"'`)
+		}
+	}
+
+	ids := append([]string{fields["ID"]}, strings.Fields(fields["ID_LIKE"])...)
+	for _, id := range ids {
+		if family, ok := familyForID(id); ok {
+			return family
+		}
+	}
+	return ""
+}
+
+// SystemPackageCommand builds the command line that installs the package at path with
+// the native package manager of family, preferring the high-level tool (apt-get, dnf,
+// zypper) when it's on $PATH because it also resolves dependencies.
+//
+// -path: The downloaded .deb, .rpm or .apk file.
+// -family: One of the Family constants, or a distribution ID of one.
+// -sudo: Whether to run the package manager through sudo.
+// Returns: The command and its arguments, or an error if the package can't be
+// installed on family.
+func SystemPackageCommand(path, family string, sudo bool) ([]string, error) {
+	if !PackageMatchesFamily(path, family) {
+		return nil, fmt.Errorf(
+			"cannot install '%s' on a system of the '%s' family",
+			filepath.Base(path),
+			family,
+		)
+	}
+	// apt-get only treats the argument as a file when it contains a path separator
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path of '%s': %w", path, err)
+	}
+
+	var args []string
+	family, _ = familyForID(family)
+	switch family {
+	case FamilyDebian:
+		args = []string{"dpkg", "-i", absPath}
+		if hasCommand("apt-get") {
+			args = []string{"apt-get", "install", "-y", absPath}
+		}
+	case FamilyRHEL:
+		args = []string{"rpm", "-i", absPath}
+		if hasCommand("dnf") {
+			args = []string{"dnf", "install", "-y", absPath}
+		}
+	case FamilySUSE:
+		args = []string{"rpm", "-i", absPath}
+		if hasCommand("zypper") {
+			args = []string{"zypper", "--non-interactive", "install", absPath}
+		}
+	case FamilyAlpine:
+		args = []string{"apk", "add", "--allow-untrusted", absPath}
+	}
+	if sudo {
+		args = append([]string{"sudo"}, args...)
+	}
+	return args, nil
+}
+
+// hasCommand reports whether the named executable is on $PATH.
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// InstallSystemPackage installs the package at path with the native package manager
+// of family (see SystemPackageCommand). The package manager's output goes to stderr.
+// A warning is logged when not running as root and sudo isn't requested, since the
+// package manager will most likely refuse to run.
+// Returns: An error if the package doesn't fit family or the package manager fails.
+func InstallSystemPackage(path, family string, sudo bool) error {
+	args, err := SystemPackageCommand(path, family, sudo)
+	if err != nil {
+		return err
+	}
+	if !sudo && os.Geteuid() != 0 {
+		Logger.Warnf(
+			"Not running as root; '%s' will likely fail. Re-run as root or with --sudo.",
+			args[0],
+		)
+	}
+
+	Logger.Infof("Running %s", strings.Join(args, " "))
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec
+	cmd.Stdin = os.Stdin                      // sudo may ask for a password
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install package '%s': %w", filepath.Base(path), err)
+	}
+	return nil
+}
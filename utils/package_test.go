@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestIsSystemPackage(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "tool_1.0.0_amd64.deb", want: true},
+		{file: "tool-1.0.0-1.x86_64.rpm", want: true},
+		{file: "tool_1.0.0_x86_64.APK", want: true},
+		{file: "tool_linux_amd64.tar.gz", want: false},
+		{file: "debug_linux_amd64", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsSystemPackage(tt.file); got != tt.want {
+				t.Errorf("IsSystemPackage(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageMatchesFamily(t *testing.T) {
+	tests := []struct {
+		file   string
+		family string
+		want   bool
+	}{
+		{file: "tool_amd64.deb", family: FamilyDebian, want: true},
+		{file: "tool.x86_64.rpm", family: FamilyRHEL, want: true},
+		{file: "tool.x86_64.rpm", family: FamilySUSE, want: true},
+		{file: "tool_x86_64.apk", family: FamilyAlpine, want: true},
+		{file: "tool_amd64.deb", family: FamilyRHEL, want: false},
+		{file: "tool_amd64.deb", family: "", want: false},
+		{file: "tool.x86_64.rpm", family: "fedora", want: true},
+		{file: "tool_amd64.deb", family: "Ubuntu", want: true},
+		{file: "tool_amd64.deb", family: "arch", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.family, func(t *testing.T) {
+			if got := PackageMatchesFamily(tt.file, tt.family); got != tt.want {
+				t.Errorf(
+					"PackageMatchesFamily(%q, %q) = %v, want %v",
+					tt.file, tt.family, got, tt.want,
+				)
+			}
+		})
+	}
+}
+
+func TestPackageExtForFamily(t *testing.T) {
+	tests := []struct {
+		family string
+		want   string
+		wantOK bool
+	}{
+		{family: FamilyDebian, want: ".deb", wantOK: true},
+		{family: FamilyRHEL, want: ".rpm", wantOK: true},
+		{family: FamilySUSE, want: ".rpm", wantOK: true},
+		{family: FamilyAlpine, want: ".apk", wantOK: true},
+		{family: "ubuntu", want: ".deb", wantOK: true},
+		{family: "kali", want: ".deb", wantOK: true},
+		{family: "fedora", want: ".rpm", wantOK: true},
+		{family: "CentOS", want: ".rpm", wantOK: true},
+		{family: "rocky", want: ".rpm", wantOK: true},
+		{family: "almalinux", want: ".rpm", wantOK: true},
+		{family: "opensuse-leap", want: ".rpm", wantOK: true},
+		{family: "postmarketos", want: ".apk", wantOK: true},
+		{family: "arch", wantOK: false},
+		{family: "gentoo", wantOK: false},
+		{family: "nixos", wantOK: false},
+		{family: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			got, ok := PackageExtForFamily(tt.family)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf(
+					"PackageExtForFamily(%q) = (%q, %t), want (%q, %t)",
+					tt.family, got, ok, tt.want, tt.wantOK,
+				)
+			}
+		})
+	}
+}
+
+func Test_osFamilyFromOSRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "debian", content: "NAME=\"Debian GNU/Linux\"\nID=debian\n", want: FamilyDebian},
+		{name: "ubuntu", content: "ID=ubuntu\nID_LIKE=debian\n", want: FamilyDebian},
+		{name: "fedora", content: "ID=fedora\n", want: FamilyRHEL},
+		{name: "rocky", content: "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", want: FamilyRHEL},
+		{
+			name:    "opensuse",
+			content: "ID=\"opensuse-tumbleweed\"\nID_LIKE=\"opensuse suse\"\n",
+			want:    FamilySUSE,
+		},
+		{name: "alpine", content: "ID=alpine\n", want: FamilyAlpine},
+		{name: "derivative", content: "ID=mydistro\nID_LIKE=\"ubuntu debian\"\n", want: FamilyDebian},
+		{name: "unknown", content: "ID=arch\n", want: ""},
+		{name: "empty", content: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := osFamilyFromOSRelease(tt.content); got != tt.want {
+				t.Errorf("osFamilyFromOSRelease() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectOSFamily(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("os-release is only read on Linux")
+	}
+	CreateLogger(false)
+	path := filepath.Join(t.TempDir(), "os-release")
+	if err := os.WriteFile(path, []byte("ID=alpine\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write os-release: %v", err)
+	}
+	defer func(orig string) { osReleasePath = orig }(osReleasePath)
+
+	osReleasePath = path
+	if got := DetectOSFamily(); got != FamilyAlpine {
+		t.Errorf("DetectOSFamily() = %q, want %q", got, FamilyAlpine)
+	}
+	osReleasePath = filepath.Join(t.TempDir(), "missing")
+	if got := DetectOSFamily(); got != "" {
+		t.Errorf("DetectOSFamily() without os-release = %q, want empty", got)
+	}
+}
+
+func TestResolveOSFamily(t *testing.T) {
+	CreateLogger(false)
+	defer func(orig string) { osReleasePath = orig }(osReleasePath)
+	// Nothing is detected, as in a minimal container image
+	osReleasePath = filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		override string
+		want     string
+	}{
+		{override: "", want: ""},
+		{override: FamilyDebian, want: FamilyDebian},
+		{override: "RHEL", want: FamilyRHEL},
+		{override: "ubuntu", want: FamilyDebian},
+		{override: "arch", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.override, func(t *testing.T) {
+			if got := ResolveOSFamily(tt.override); got != tt.want {
+				t.Errorf("ResolveOSFamily(%q) = %q, want %q", tt.override, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSystemPackageCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake package managers require a POSIX shell")
+	}
+	// Only apt-get and dnf are "installed", so dpkg isn't used but rpm is for SUSE
+	binDir := t.TempDir()
+	for _, name := range []string{"apt-get", "dnf"} {
+		err := os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0o755) //nolint:gosec
+		if err != nil {
+			t.Fatalf("Failed to write fake %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", binDir)
+
+	pkgDir := t.TempDir()
+	tests := []struct {
+		name    string
+		pkg     string
+		family  string
+		sudo    bool
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "apt-get",
+			pkg:    "tool_amd64.deb",
+			family: FamilyDebian,
+			want:   []string{"apt-get", "install", "-y"},
+		},
+		{
+			name:   "dnf with sudo",
+			pkg:    "tool.x86_64.rpm",
+			family: FamilyRHEL,
+			sudo:   true,
+			want:   []string{"sudo", "dnf", "install", "-y"},
+		},
+		{name: "rpm fallback", pkg: "tool.x86_64.rpm", family: FamilySUSE, want: []string{"rpm", "-i"}},
+		{
+			name:   "distribution ID",
+			pkg:    "tool.x86_64.rpm",
+			family: "fedora",
+			want:   []string{"dnf", "install", "-y"},
+		},
+		{
+			name:   "apk",
+			pkg:    "tool_x86_64.apk",
+			family: FamilyAlpine,
+			want:   []string{"apk", "add", "--allow-untrusted"},
+		},
+		{name: "wrong family", pkg: "tool_amd64.deb", family: FamilyAlpine, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgPath := filepath.Join(pkgDir, tt.pkg)
+			got, err := SystemPackageCommand(pkgPath, tt.family, tt.sudo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SystemPackageCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := append(tt.want, pkgPath); !slices.Equal(got, want) {
+				t.Errorf("SystemPackageCommand() = %v, want %v", got, want)
+			}
+		})
+	}
+}
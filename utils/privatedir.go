@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// EnsurePrivateDir creates dir with mode 0700 if it doesn't exist, and otherwise makes
+// sure it's safe to keep files in: a real directory (not a symlink) that only the
+// current user can access. Its parent must already exist.
+//
+// -dir: The directory, e.g. where a partial download is kept between runs.
+// Returns: An error if dir can't be created or might be controlled by another user.
+func EnsurePrivateDir(dir string) error {
+	err := os.Mkdir(dir, 0o700) //nolint:mnd
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to stat '%s': %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("'%s' exists and is not a directory", dir)
+	}
+	return checkPrivate(dir, info)
+}
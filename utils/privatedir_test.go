@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEnsurePrivateDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string)
+		unix    bool // Only meaningful where permission bits are enforced
+		wantErr bool
+	}{
+		{name: "missing directory is created", setup: func(*testing.T, string) {}},
+		{
+			name: "existing private directory",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				if err := os.Mkdir(dir, 0o700); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "directory accessible to others",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				if err := os.Mkdir(dir, 0o700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(dir, 0o777); err != nil { //nolint:gosec
+					t.Fatal(err)
+				}
+			},
+			unix:    true,
+			wantErr: true,
+		},
+		{
+			name: "symlink to a directory",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				if err := os.Symlink(t.TempDir(), dir); err != nil {
+					t.Skip(err)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "regular file",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				if err := os.WriteFile(dir, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unix && runtime.GOOS == "windows" {
+				t.Skip("permission bits aren't enforced on Windows")
+			}
+			dir := filepath.Join(t.TempDir(), "private")
+			tt.setup(t, dir)
+			err := EnsurePrivateDir(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnsurePrivateDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			info, err := os.Stat(dir)
+			if err != nil || !info.IsDir() {
+				t.Fatalf("expected %s to be a directory, stat error = %v", dir, err)
+			}
+		})
+	}
+}
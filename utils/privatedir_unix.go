@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+
+//go:build unix
+
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// checkPrivate makes sure dir is owned by the current user and not accessible to
+// anyone else.
+func checkPrivate(dir string, info fs.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("'%s' is owned by another user", dir)
+	}
+	if info.Mode().Perm()&0o077 != 0 { //nolint:mnd
+		return fmt.Errorf(
+			"'%s' is accessible to other users (mode %#o), expected 0700",
+			dir,
+			info.Mode().Perm(),
+		)
+	}
+	return nil
+}
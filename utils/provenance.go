@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// slsaVerifierBinary is the name of the slsa-verifier executable looked up on $PATH.
+const slsaVerifierBinary = "slsa-verifier"
+
+// provenanceSuffix ends the in-toto attestation files published by the SLSA GitHub
+// generators, e.g. "multiple.intoto.jsonl" or "tool_linux_amd64.intoto.jsonl".
+const provenanceSuffix = ".intoto.jsonl"
+
+// IsProvenanceFile reports whether the given filename is a SLSA provenance attestation
+// rather than an installable asset.
+func IsProvenanceFile(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Base(filePath)), provenanceSuffix)
+}
+
+// VerifyProvenance verifies the SLSA provenance of assetPath by running
+// `slsa-verifier verify-artifact`. slsa-verifier checks that the attestation was
+// produced by a trusted builder and lists the asset's digest as a subject.
+//
+// -assetPath: The downloaded release asset.
+// -provenancePath: The provenance attestation, e.g. multiple.intoto.jsonl.
+// -sourceURI: The repository the asset must have been built from, e.g. github.com/owner/repo.
+// -sourceTag: The release tag the asset must have been built from; empty to skip the check.
+// Returns: An error if slsa-verifier isn't installed or verification fails.
+func VerifyProvenance(assetPath, provenancePath, sourceURI, sourceTag string) error {
+	verifier, err := exec.LookPath(slsaVerifierBinary)
+	if err != nil {
+		return fmt.Errorf(
+			"slsa-verifier is required to verify provenance but was not found: %w",
+			err,
+		)
+	}
+
+	args := []string{
+		"verify-artifact", assetPath,
+		"--provenance-path", provenancePath,
+		"--source-uri", sourceURI,
+	}
+	if sourceTag != "" {
+		args = append(args, "--source-tag", sourceTag)
+	}
+
+	Logger.Debugf("Running %s %s", verifier, strings.Join(args, " "))
+	var output bytes.Buffer
+	cmd := exec.Command(verifier, args...) //nolint:gosec
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf(
+				"SLSA provenance verification failed for '%s': %s",
+				filepath.Base(assetPath),
+				strings.TrimSpace(output.String()),
+			)
+		}
+		return fmt.Errorf("failed to run slsa-verifier: %w", err)
+	}
+	Logger.Debugf("slsa-verifier: %s", strings.TrimSpace(output.String()))
+	return nil
+}
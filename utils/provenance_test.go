@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsProvenanceFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "multiple.intoto.jsonl", want: true},
+		{file: "tool_linux_amd64.intoto.jsonl", want: true},
+		{file: "dist/Tool.INTOTO.JSONL", want: true},
+		{file: "tool_linux_amd64.tar.gz", want: false},
+		{file: "events.jsonl", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsProvenanceFile(tt.file); got != tt.want {
+				t.Errorf("IsProvenanceFile(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeFakeSLSAVerifier puts a slsa-verifier script on $PATH that records its
+// arguments to a file and exits with exitCode.
+func writeFakeSLSAVerifier(t *testing.T, exitCode int) (argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake slsa-verifier script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	script := fmt.Sprintf(
+		"#!/bin/sh\necho \"$@\" > %s\necho 'PASSED: SLSA verification passed'\nexit %d\n",
+		argsFile,
+		exitCode,
+	)
+	path := filepath.Join(dir, slsaVerifierBinary)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil { //nolint:gosec
+		t.Fatalf("Failed to write fake slsa-verifier: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	return argsFile
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name      string
+		exitCode  int
+		sourceTag string
+		wantArgs  string
+		wantErr   bool
+	}{
+		{
+			name:      "with tag",
+			sourceTag: "v1.0.0",
+			wantArgs: "verify-artifact asset --provenance-path multiple.intoto.jsonl " +
+				"--source-uri github.com/owner/repo --source-tag v1.0.0",
+		},
+		{
+			name: "without tag",
+			wantArgs: "verify-artifact asset --provenance-path multiple.intoto.jsonl " +
+				"--source-uri github.com/owner/repo",
+		},
+		{name: "verification failure", exitCode: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := writeFakeSLSAVerifier(t, tt.exitCode)
+
+			err := VerifyProvenance(
+				"asset",
+				"multiple.intoto.jsonl",
+				"github.com/owner/repo",
+				tt.sourceTag,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyProvenance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("Failed to read recorded slsa-verifier arguments: %v", err)
+			}
+			if got := strings.TrimSpace(string(args)); got != tt.wantArgs {
+				t.Errorf("slsa-verifier called with %q, want %q", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestVerifyProvenance_NotInstalled(t *testing.T) {
+	CreateLogger(true)
+	t.Setenv("PATH", t.TempDir())
+
+	err := VerifyProvenance("asset", "multiple.intoto.jsonl", "github.com/owner/repo", "")
+	if err == nil || !strings.Contains(err.Error(), "slsa-verifier is required") {
+		t.Errorf("VerifyProvenance() error = %v, want missing slsa-verifier error", err)
+	}
+}
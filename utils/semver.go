@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a parsed semantic version such as v1.2.3-rc.1.
+type Semver struct {
+	Major      int    // Major version number
+	Minor      int    // Minor version number
+	Patch      int    // Patch version number
+	Prerelease string // Pre-release identifier without the leading "-", e.g. "rc.1"
+}
+
+// String formats the version without a leading "v", e.g. "1.2.3-rc.1".
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// ParseSemver parses a release tag as a semantic version. A leading "v" is optional,
+// missing minor/patch components default to 0 and build metadata ("+...") is ignored.
+// Returns: The parsed version and true, or false if tag isn't a semantic version.
+func ParseSemver(tag string) (Semver, bool) {
+	v, _, ok := parseSemverParts(tag)
+	return v, ok
+}
+
+// parseSemverParts parses a version like ParseSemver and also reports how many
+// numeric components (1-3) were present, which matters for ~ and ^ ranges.
+func parseSemverParts(tag string) (Semver, int, bool) {
+	s := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(tag), "v"), "V")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+
+	var v Semver
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.Prerelease = s[i+1:]
+		s = s[:i]
+		if v.Prerelease == "" {
+			return Semver{}, 0, false
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) == 0 || len(parts) > 3 { //nolint:mnd
+		return Semver{}, 0, false
+	}
+	nums := make([]int, 3) //nolint:mnd
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Semver{}, 0, false
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, len(parts), true
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower than, equal to,
+// or greater than other. A pre-release sorts before its release (1.0.0-rc.1 < 1.0.0).
+func (v Semver) Compare(other Semver) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// comparePrerelease compares two pre-release strings following the semver precedence rules.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return compareInts(aNum, bNum)
+			}
+		case aErr == nil: // Numeric identifiers sort before alphanumeric ones
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(aParts), len(bParts))
+}
+
+// compareInts returns -1, 0 or 1 depending on whether a is lower than, equal to, or greater than b.
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparator is a single "<op><version>" term of a constraint.
+type comparator struct {
+	op      string
+	version Semver
+}
+
+// matches reports whether v satisfies the comparator.
+func (c comparator) matches(v Semver) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default: // "="
+		return cmp == 0
+	}
+}
+
+// Constraint is a parsed version constraint such as "^1.2.0" or ">=2.0.0 <3.0.0".
+// Space- or comma-separated terms must all match; "||" separates alternatives.
+type Constraint struct {
+	raw    string
+	groups [][]comparator
+}
+
+// String returns the constraint expression as it was given.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// constraintOperators lists the prefixes that mark a version string as a constraint
+// expression rather than a literal tag. Longer operators come first so they match first.
+var constraintOperators = []string{">=", "<=", "^", "~", ">", "<", "="}
+
+// IsConstraint reports whether s looks like a version constraint expression
+// (it starts with one of ^ ~ > < =) rather than a literal release tag.
+func IsConstraint(s string) bool {
+	s = strings.TrimSpace(s)
+	for _, op := range constraintOperators {
+		if strings.HasPrefix(s, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConstraint parses a version constraint expression.
+// Supported forms: exact ("1.2.3", "=1.2.3"), comparisons (">", ">=", "<", "<="),
+// caret ("^1.2.3" allows changes that don't modify the left-most non-zero component)
+// and tilde ("~1.2.3" allows patch-level changes).
+func ParseConstraint(expr string) (Constraint, error) {
+	c := Constraint{raw: expr}
+	for _, group := range strings.Split(expr, "||") {
+		terms := strings.FieldsFunc(group, func(r rune) bool { return r == ' ' || r == ',' })
+		var comparators []comparator
+		for i := 0; i < len(terms); i++ {
+			term := terms[i]
+			// Allow a space between an operator and its version, e.g. ">= 2.0.0"
+			if isBareOperator(term) && i+1 < len(terms) {
+				term += terms[i+1]
+				i++
+			}
+			parsed, err := parseConstraintTerm(term)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid constraint '%s': %w", expr, err)
+			}
+			comparators = append(comparators, parsed...)
+		}
+		if len(comparators) == 0 {
+			return Constraint{}, fmt.Errorf("invalid constraint '%s': empty expression", expr)
+		}
+		c.groups = append(c.groups, comparators)
+	}
+	return c, nil
+}
+
+// isBareOperator reports whether term consists solely of a comparison operator.
+func isBareOperator(term string) bool {
+	for _, op := range constraintOperators {
+		if term == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseConstraintTerm expands a single term into one or more comparators.
+func parseConstraintTerm(term string) ([]comparator, error) {
+	op := "="
+	for _, candidate := range constraintOperators {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			term = strings.TrimPrefix(term, candidate)
+			break
+		}
+	}
+
+	v, parts, ok := parseSemverParts(term)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a semantic version", term)
+	}
+
+	switch op {
+	case "^":
+		return []comparator{{">=", v}, {"<", caretUpperBound(v, parts)}}, nil
+	case "~":
+		return []comparator{{">=", v}, {"<", tildeUpperBound(v, parts)}}, nil
+	default:
+		return []comparator{{op, v}}, nil
+	}
+}
+
+// caretUpperBound returns the exclusive upper bound for ^v: the next version that
+// increments the left-most non-zero component (^1.2.3 -> 2.0.0, ^0.2.3 -> 0.3.0).
+func caretUpperBound(v Semver, parts int) Semver {
+	switch {
+	case v.Major > 0 || parts == 1:
+		return Semver{Major: v.Major + 1}
+	case v.Minor > 0 || parts == 2: //nolint:mnd
+		return Semver{Minor: v.Minor + 1}
+	default:
+		return Semver{Patch: v.Patch + 1}
+	}
+}
+
+// tildeUpperBound returns the exclusive upper bound for ~v: the next minor version
+// (~1.2.3 -> 1.3.0), or the next major when only the major was given (~1 -> 2.0.0).
+func tildeUpperBound(v Semver, parts int) Semver {
+	if parts == 1 {
+		return Semver{Major: v.Major + 1}
+	}
+	return Semver{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// Check reports whether v satisfies the constraint. Pre-release versions only match
+// when a term of the matching alternative itself names a pre-release of the same
+// major.minor.patch, so "^1.0.0" never selects "1.1.0-beta".
+func (c Constraint) Check(v Semver) bool {
+	for _, group := range c.groups {
+		if groupMatches(group, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupMatches reports whether v satisfies every comparator in group.
+func groupMatches(group []comparator, v Semver) bool {
+	prereleaseAllowed := v.Prerelease == ""
+	for _, cmp := range group {
+		if !cmp.matches(v) {
+			return false
+		}
+		if cmp.version.Prerelease != "" && cmp.version.Major == v.Major &&
+			cmp.version.Minor == v.Minor && cmp.version.Patch == v.Patch {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		name   string
+		tag    string
+		want   Semver
+		wantOk bool
+	}{
+		{name: "with v", tag: "v1.2.3", want: Semver{1, 2, 3, ""}, wantOk: true},
+		{name: "without v", tag: "1.2.3", want: Semver{1, 2, 3, ""}, wantOk: true},
+		{name: "two parts", tag: "v1.2", want: Semver{1, 2, 0, ""}, wantOk: true},
+		{name: "major only", tag: "v2", want: Semver{2, 0, 0, ""}, wantOk: true},
+		{name: "prerelease", tag: "v1.0.0-rc.1", want: Semver{1, 0, 0, "rc.1"}, wantOk: true},
+		{name: "build metadata", tag: "v1.0.0+build.5", want: Semver{1, 0, 0, ""}, wantOk: true},
+		{name: "not a version", tag: "latest", wantOk: false},
+		{name: "too many parts", tag: "1.2.3.4", wantOk: false},
+		{name: "empty prerelease", tag: "1.2.3-", wantOk: false},
+		{name: "prefixed tag", tag: "tool-v1.2.3", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseSemver(tt.tag)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseSemver(%q) ok = %v, want %v", tt.tag, ok, tt.wantOk)
+			}
+			if ok && got != tt.want {
+				t.Errorf("ParseSemver(%q) = %v, want %v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.3.0", "1.2.9", 1},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			a, _ := ParseSemver(tt.a)
+			b, _ := ParseSemver(tt.b)
+			if got := a.Compare(b); got != tt.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsConstraint(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"^1.2.0", true},
+		{"~1.2", true},
+		{">=2.0.0 <3.0.0", true},
+		{"<1.0.0", true},
+		{"=1.0.0", true},
+		{"v1.2.3", false},
+		{"latest", false},
+		{"nightly", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := IsConstraint(tt.version); got != tt.want {
+				t.Errorf("IsConstraint(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"^1.2.0", "1.2.0", true},
+		{"^1.2.0", "1.9.9", true},
+		{"^1.2.0", "2.0.0", false},
+		{"^1.2.0", "1.1.9", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{">=2.0.0 <3.0.0", "2.5.1", true},
+		{">=2.0.0 <3.0.0", "3.0.0", false},
+		{">=2.0.0, <3.0.0", "1.9.0", false},
+		{">= 2.0.0 < 3.0.0", "2.0.0", true},
+		{"<1.0.0 || >=2.0.0", "0.9.0", true},
+		{"<1.0.0 || >=2.0.0", "1.5.0", false},
+		{"<1.0.0 || >=2.0.0", "2.1.0", true},
+		{"=1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{"^1.0.0", "1.1.0-beta", false},
+		{">=1.1.0-beta", "1.1.0-rc.1", true},
+		{">=1.1.0-beta", "1.2.0-rc.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+"_"+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q) error = %v", tt.constraint, err)
+			}
+			v, ok := ParseSemver(tt.version)
+			if !ok {
+				t.Fatalf("ParseSemver(%q) failed", tt.version)
+			}
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("%q.Check(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, expr := range []string{"^", ">=abc", "", "^1.x"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseConstraint(expr); err == nil {
+				t.Errorf("ParseConstraint(%q) expected error, got nil", expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // Still the only OpenPGP implementation in our deps
+)
+
+const (
+	cosignBinary = "cosign" // Name of the cosign executable looked up on $PATH
+	// actionsOIDCIssuer issues the GitHub Actions tokens keyless signing certificates of
+	// github.com workflows are requested with
+	actionsOIDCIssuer = "https://token.actions.githubusercontent.com"
+)
+
+// signatureSuffixes lists the file name suffixes of detached signatures, certificates
+// and bundles published next to release assets.
+var signatureSuffixes = []string{
+	".sig",
+	".asc",
+	".pem",
+	".crt",
+	".cert",
+	".bundle",
+	".sigstore",
+	".sigstore.json",
+}
+
+// IsSignatureFile reports whether the given filename is a detached signature,
+// signing certificate or sigstore bundle rather than an installable asset.
+func IsSignatureFile(filePath string) bool {
+	name := strings.ToLower(filepath.Base(filePath))
+	for _, suffix := range signatureSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCosignBundle reports whether the given filename is a sigstore bundle, which
+// holds the signature and certificate in a single file.
+func IsCosignBundle(filePath string) bool {
+	name := strings.ToLower(filepath.Base(filePath))
+	return strings.HasSuffix(name, ".bundle") || strings.HasSuffix(name, ".sigstore") ||
+		strings.HasSuffix(name, ".sigstore.json")
+}
+
+// VerifyCosignIdentity verifies a keyless cosign signature of assetPath by running
+// `cosign verify-blob`. Only certificates issued to a GitHub Actions workflow whose
+// identity (the workflow URL) matches identityRegexp are accepted.
+//
+// -assetPath: The file that was signed.
+// -sigPath: The detached signature, or a sigstore bundle when certPath is empty.
+// -certPath: The signing certificate (.pem); empty when sigPath is a bundle.
+// -identityRegexp: The workflow identities to accept, e.g. "^https://github\.com/o/r/".
+// Returns: An error if identityRegexp is empty, cosign isn't installed or verification
+// fails.
+func VerifyCosignIdentity(assetPath, sigPath, certPath, identityRegexp string) error {
+	if identityRegexp == "" {
+		return errors.New("a certificate identity is required to verify cosign signatures")
+	}
+	cosign, err := exec.LookPath(cosignBinary)
+	if err != nil {
+		return fmt.Errorf("cosign is required to verify signatures but was not found: %w", err)
+	}
+
+	args := []string{"verify-blob"}
+	switch {
+	case certPath != "":
+		args = append(args, "--signature", sigPath, "--certificate", certPath)
+	case IsCosignBundle(sigPath):
+		args = append(args, "--bundle", sigPath)
+	default:
+		return fmt.Errorf("no certificate provided for signature '%s'", sigPath)
+	}
+	args = append(
+		args,
+		"--certificate-identity-regexp", identityRegexp,
+		"--certificate-oidc-issuer", actionsOIDCIssuer,
+		assetPath,
+	)
+
+	Logger.Debugf("Running %s %s", cosign, strings.Join(args, " "))
+	var output bytes.Buffer
+	cmd := exec.Command(cosign, args...) //nolint:gosec
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf(
+				"cosign signature verification failed for '%s': %s",
+				filepath.Base(assetPath),
+				strings.TrimSpace(output.String()),
+			)
+		}
+		return fmt.Errorf("failed to run cosign: %w", err)
+	}
+	Logger.Debugf("cosign: %s", strings.TrimSpace(output.String()))
+	return nil
+}
+
+// armoredSignatureHeader starts an ASCII-armored OpenPGP signature.
+const armoredSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+
+// VerifyGPGSignature verifies the detached OpenPGP signature at sigPath (armored .asc
+// or binary .sig) of the file at dataPath against the given public keys.
+//
+// -dataPath: The signed file, e.g. checksums.txt.
+// -sigPath: The detached signature, e.g. checksums.txt.asc.
+// -keyring: Paths of ASCII-armored public key files to trust.
+// Returns: An error if no key could be loaded or the signature doesn't verify.
+func VerifyGPGSignature(dataPath, sigPath string, keyring []string) error {
+	if len(keyring) == 0 {
+		return errors.New("no GPG public keys provided")
+	}
+
+	var entities openpgp.EntityList
+	for _, keyPath := range keyring {
+		keyFile, err := os.Open(keyPath) //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("failed to open GPG key '%s': %w", keyPath, err)
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(keyFile)
+		keyFile.Close() //nolint:errcheck,gosec
+		if err != nil {
+			return fmt.Errorf("failed to read GPG key '%s': %w", keyPath, err)
+		}
+		entities = append(entities, keys...)
+	}
+
+	sig, err := os.ReadFile(sigPath) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to read signature '%s': %w", sigPath, err)
+	}
+	data, err := os.Open(dataPath) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open signed file '%s': %w", dataPath, err)
+	}
+	defer data.Close() //nolint:errcheck
+
+	var signer *openpgp.Entity
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte(armoredSignatureHeader)) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(entities, data, bytes.NewReader(sig))
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(entities, data, bytes.NewReader(sig))
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"GPG signature verification failed for '%s': %w",
+			filepath.Base(dataPath),
+			err,
+		)
+	}
+
+	Logger.Debugf("'%s' signed by key %s", filepath.Base(dataPath), signer.PrimaryKey.KeyIdString())
+	return nil
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
+)
+
+func TestIsSignatureFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "tool_linux_amd64.tar.gz.sig", want: true},
+		{file: "tool_linux_amd64.tar.gz.pem", want: true},
+		{file: "checksums.txt.asc", want: true},
+		{file: "tool_linux_amd64.tar.gz.sigstore.json", want: true},
+		{file: "cosign.bundle", want: true},
+		{file: "tool_linux_amd64.tar.gz", want: false},
+		{file: "checksums.txt", want: false},
+		{file: "signal_linux_amd64", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := IsSignatureFile(tt.file); got != tt.want {
+				t.Errorf("IsSignatureFile(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeFakeCosign puts a cosign script on $PATH that records its arguments to a
+// file and exits with exitCode.
+func writeFakeCosign(t *testing.T, exitCode int) (argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cosign script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	script := fmt.Sprintf(
+		"#!/bin/sh\necho \"$@\" > %s\necho 'verification output'\nexit %d\n",
+		argsFile,
+		exitCode,
+	)
+	if err := os.WriteFile(filepath.Join(dir, "cosign"), []byte(script), 0o755); err != nil { //nolint:gosec
+		t.Fatalf("Failed to write fake cosign: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	return argsFile
+}
+
+func TestVerifyCosignIdentity(t *testing.T) {
+	CreateLogger(true)
+	const identity = "^https://github\\.com/owner/repo/"
+
+	tests := []struct {
+		name     string
+		exitCode int
+		sigPath  string
+		certPath string
+		identity string
+		wantArgs string
+		wantErr  bool
+	}{
+		{
+			name:     "signature and certificate",
+			sigPath:  "asset.sig",
+			certPath: "asset.pem",
+			identity: identity,
+			wantArgs: "verify-blob --signature asset.sig --certificate asset.pem",
+		},
+		{
+			name:     "bundle",
+			sigPath:  "asset.sigstore.json",
+			identity: identity,
+			wantArgs: "verify-blob --bundle asset.sigstore.json",
+		},
+		{
+			name:     "no identity",
+			sigPath:  "asset.sig",
+			certPath: "asset.pem",
+			wantErr:  true,
+		},
+		{
+			name:     "verification failure",
+			exitCode: 1,
+			sigPath:  "asset.sig",
+			certPath: "asset.pem",
+			identity: identity,
+			wantErr:  true,
+		},
+		{
+			name:     "signature without certificate",
+			sigPath:  "asset.sig",
+			identity: identity,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := writeFakeCosign(t, tt.exitCode)
+
+			err := VerifyCosignIdentity("asset", tt.sigPath, tt.certPath, tt.identity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyCosignIdentity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("Failed to read recorded cosign arguments: %v", err)
+			}
+			if !strings.HasPrefix(string(args), tt.wantArgs) {
+				t.Errorf("cosign called with %q, want prefix %q", args, tt.wantArgs)
+			}
+			wantIssuer := "--certificate-oidc-issuer " + actionsOIDCIssuer
+			if !strings.Contains(string(args), wantIssuer) {
+				t.Errorf("cosign called with %q, want the issuer pinned with %q", args, wantIssuer)
+			}
+		})
+	}
+}
+
+func TestVerifyCosignIdentity_NotInstalled(t *testing.T) {
+	CreateLogger(true)
+	t.Setenv("PATH", t.TempDir())
+
+	err := VerifyCosignIdentity("asset", "asset.sig", "asset.pem", "^https://github\\.com/")
+	if err == nil || !strings.Contains(err.Error(), "cosign is required") {
+		t.Errorf("VerifyCosignIdentity() error = %v, want missing cosign error", err)
+	}
+}
+
+// writeGPGFixtures creates a signing key, writes its armored public key and the
+// data file, and returns the entity so tests can sign with it.
+func writeGPGFixtures(t *testing.T, dir string) (*openpgp.Entity, string, string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create GPG entity: %v", err)
+	}
+
+	keyPath := filepath.Join(dir, "key.asc")
+	var key bytes.Buffer
+	w, err := armor.Encode(&key, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("Failed to armor public key: %v", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("Failed to serialize public key: %v", err)
+	}
+	w.Close() //nolint:errcheck,gosec
+	if err := os.WriteFile(keyPath, key.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to write public key: %v", err)
+	}
+
+	dataPath := filepath.Join(dir, "checksums.txt")
+	if err := os.WriteFile(dataPath, []byte("abc123  tool.tar.gz\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+	return entity, keyPath, dataPath
+}
+
+func TestVerifyGPGSignature(t *testing.T) {
+	CreateLogger(true)
+	dir := t.TempDir()
+	entity, keyPath, dataPath := writeGPGFixtures(t, dir)
+	other, otherKeyPath, _ := writeGPGFixtures(t, t.TempDir())
+
+	sign := func(name string, armored bool, signer *openpgp.Entity, content string) string {
+		var sig bytes.Buffer
+		var err error
+		if armored {
+			err = openpgp.ArmoredDetachSign(&sig, signer, strings.NewReader(content), nil)
+		} else {
+			err = openpgp.DetachSign(&sig, signer, strings.NewReader(content), nil)
+		}
+		if err != nil {
+			t.Fatalf("Failed to sign: %v", err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, sig.Bytes(), 0o600); err != nil {
+			t.Fatalf("Failed to write signature: %v", err)
+		}
+		return path
+	}
+	content := "abc123  tool.tar.gz\n"
+
+	tests := []struct {
+		name    string
+		sigPath string
+		keyring []string
+		wantErr bool
+	}{
+		{
+			name:    "armored signature",
+			sigPath: sign("good.asc", true, entity, content),
+			keyring: []string{keyPath},
+		},
+		{
+			name:    "binary signature",
+			sigPath: sign("good.sig", false, entity, content),
+			keyring: []string{keyPath},
+		},
+		{
+			name:    "signed by one of several keys",
+			sigPath: sign("good2.asc", true, entity, content),
+			keyring: []string{otherKeyPath, keyPath},
+		},
+		{
+			name:    "tampered data",
+			sigPath: sign("tampered.asc", true, entity, "def456  tool.tar.gz\n"),
+			keyring: []string{keyPath},
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			sigPath: sign("unknown.asc", true, other, content),
+			keyring: []string{keyPath},
+			wantErr: true,
+		},
+		{
+			name:    "no keys",
+			sigPath: sign("nokeys.asc", true, entity, content),
+			wantErr: true,
+		},
+		{
+			name:    "missing key file",
+			sigPath: sign("missing.asc", true, entity, content),
+			keyring: []string{filepath.Join(dir, "missing.asc.key")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyGPGSignature(dataPath, tt.sigPath, tt.keyring)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyGPGSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// EnsureSymlink points linkPath at target, replacing an existing symlink at linkPath
+// atomically by renaming a new link over it. A target in the same directory is linked
+// by its relative name so the directory can be moved. Creating symlinks on Windows
+// requires privileges, so there target is copied to linkPath instead.
+//
+// -target: The file the link should point to, e.g. the versioned binary tool-v1.2.3.
+// -linkPath: The stable path, e.g. tool.
+// Returns: An error if linkPath exists but isn't a symlink (or, on Windows, a regular
+// file), or if the link can't be created.
+func EnsureSymlink(target, linkPath string) error {
+	return ensureSymlink(target, linkPath, runtime.GOOS == "windows")
+}
+
+// ensureSymlink implements EnsureSymlink; copyTarget selects the copying fallback.
+func ensureSymlink(target, linkPath string, copyTarget bool) error {
+	if info, err := os.Lstat(linkPath); err == nil {
+		isLink := info.Mode()&os.ModeSymlink != 0
+		if !isLink && !(copyTarget && info.Mode().IsRegular()) {
+			return fmt.Errorf("'%s' already exists and is not a symlink", linkPath)
+		}
+	}
+
+	tmpPath := fmt.Sprintf("%s.tmp-%d", linkPath, os.Getpid())
+	_ = os.Remove(tmpPath) // Left over from an interrupted run
+	if copyTarget {
+		if err := CopyFile(target, tmpPath); err != nil {
+			return err
+		}
+	} else {
+		linkTarget := target
+		if filepath.Dir(target) == filepath.Dir(linkPath) {
+			linkTarget = filepath.Base(target)
+		}
+		if err := os.Symlink(linkTarget, tmpPath); err != nil {
+			return fmt.Errorf("failed to create symlink '%s': %w", tmpPath, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, linkPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace '%s': %w", linkPath, err)
+	}
+	Logger.Debugf("Linked '%s' to '%s'", linkPath, target)
+	return nil
+}
+
+// CopyFile copies src to dst, keeping its permission bits.
+func CopyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat '%s': %w", src, err)
+	}
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return fmt.Errorf("failed to open '%s': %w", src, err)
+	}
+	defer in.Close() //nolint:errcheck
+	return writeFile(dst, in, info.Mode().Perm())
+}
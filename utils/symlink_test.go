@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEnsureSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires privileges on Windows")
+	}
+	CreateLogger(false)
+	dir := t.TempDir()
+	v1 := filepath.Join(dir, "tool-v1.0.0")
+	v2 := filepath.Join(dir, "tool-v1.1.0")
+	for _, path := range []string{v1, v2} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0o755); err != nil { //nolint:gosec
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	link := filepath.Join(dir, "tool")
+
+	for _, target := range []string{v1, v2} {
+		if err := EnsureSymlink(target, link); err != nil {
+			t.Fatalf("EnsureSymlink(%s) error = %v", target, err)
+		}
+		got, err := os.Readlink(link)
+		if err != nil {
+			t.Fatalf("Readlink() error = %v", err)
+		}
+		if got != filepath.Base(target) {
+			t.Errorf("link points to %q, want %q", got, filepath.Base(target))
+		}
+	}
+
+	regular := filepath.Join(dir, "regular")
+	if err := os.WriteFile(regular, []byte("binary"), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", regular, err)
+	}
+	if err := EnsureSymlink(v2, regular); err == nil {
+		t.Error("EnsureSymlink() replaced a regular file, want an error")
+	}
+}
+
+func Test_ensureSymlink_copy(t *testing.T) {
+	CreateLogger(false)
+	dir := t.TempDir()
+	link := filepath.Join(dir, "tool.exe")
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		target := filepath.Join(dir, "tool-"+version+".exe")
+		if err := os.WriteFile(target, []byte(version), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", target, err)
+		}
+		if err := ensureSymlink(target, link, true); err != nil {
+			t.Fatalf("ensureSymlink(%s) error = %v", target, err)
+		}
+		got, err := os.ReadFile(link)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", link, err)
+		}
+		if string(got) != version {
+			t.Errorf("copy contains %q, want %q", got, version)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	ghInstallTokenEnv = "GH_INSTALL_TOKEN" // Token for gh-install only, preferred to GITHUB_TOKEN
+	githubTokenEnv    = "GITHUB_TOKEN"     // Environment variable holding a GitHub token
+	ghCLIBinary       = "gh"               // The GitHub CLI, asked for a token when neither is set
+	githubHost        = "github.com"       // The public GitHub host the token variables are for
+
+	// Tokens for GitHub Enterprise Server hosts, named as the gh CLI names them
+	ghEnterpriseTokenEnv     = "GH_ENTERPRISE_TOKEN"
+	githubEnterpriseTokenEnv = "GITHUB_ENTERPRISE_TOKEN"
+
+	// ghAuthTokenTimeout bounds `gh auth token`, which should answer from its config
+	ghAuthTokenTimeout = 5 * time.Second
+)
+
+// ResolveToken returns the token to authenticate GitHub API requests with: for
+// github.com the GH_INSTALL_TOKEN or GITHUB_TOKEN environment variable, in that order,
+// and for a GitHub Enterprise Server host GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN,
+// so a github.com token is never sent to another host. When none of them is set, the
+// token the gh CLI is logged in to host with is used.
+//
+// -host: The GitHub Enterprise Server host name; empty for github.com.
+// Returns: The token and a description of where it came from for logging, or two
+// empty strings when no token is available.
+func ResolveToken(host string) (token, source string) {
+	envs := []string{ghInstallTokenEnv, githubTokenEnv}
+	if host != "" && !strings.EqualFold(host, githubHost) {
+		envs = []string{ghEnterpriseTokenEnv, githubEnterpriseTokenEnv}
+	}
+	for _, env := range envs {
+		if token := strings.TrimSpace(os.Getenv(env)); token != "" {
+			return token, env
+		}
+	}
+	if token := ghAuthToken(host); token != "" {
+		return token, "gh auth token"
+	}
+	return "", ""
+}
+
+// ghAuthToken runs `gh auth token` for host (github.com when empty).
+// Returns: The token, or "" if gh isn't installed or isn't logged in.
+func ghAuthToken(host string) string {
+	gh, err := exec.LookPath(ghCLIBinary)
+	if err != nil {
+		Logger.Debugf("gh CLI not found, not asking it for a token: %v", err)
+		return ""
+	}
+
+	args := []string{"auth", "token"}
+	if host != "" {
+		args = append(args, "--hostname", host)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ghAuthTokenTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, gh, args...) //nolint:gosec
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		Logger.Debugf("gh auth token failed, is gh logged in? %v", err)
+		return ""
+	}
+	return strings.TrimSpace(stdout.String())
+}
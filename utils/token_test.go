@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeFakeGH puts a gh script on $PATH that records its arguments to a file, prints
+// token and exits with exitCode.
+func writeFakeGH(t *testing.T, token string, exitCode int) (argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gh script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	script := fmt.Sprintf(
+		"#!/bin/sh\necho \"$@\" > %s\necho '%s'\nexit %d\n",
+		argsFile,
+		token,
+		exitCode,
+	)
+	if err := os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0o755); err != nil { //nolint:gosec
+		t.Fatalf("Failed to write fake gh: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	return argsFile
+}
+
+func TestResolveToken(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name       string
+		toolToken  string
+		envToken   string
+		gheToken   string
+		ghToken    string
+		ghExitCode int
+		host       string
+		wantToken  string
+		wantSource string
+		wantArgs   string
+	}{
+		{
+			name:       "GH_INSTALL_TOKEN wins",
+			toolToken:  "tool-token",
+			envToken:   "env-token",
+			ghToken:    "gh-token",
+			wantToken:  "tool-token",
+			wantSource: "GH_INSTALL_TOKEN",
+		},
+		{
+			name:       "GITHUB_TOKEN before gh",
+			envToken:   "env-token",
+			ghToken:    "gh-token",
+			wantToken:  "env-token",
+			wantSource: "GITHUB_TOKEN",
+		},
+		{
+			name:       "gh auth token",
+			ghToken:    "gh-token",
+			wantToken:  "gh-token",
+			wantSource: "gh auth token",
+			wantArgs:   "auth token",
+		},
+		{
+			name:       "gh auth token for an enterprise host",
+			ghToken:    "ghe-token",
+			host:       "ghe.example.com",
+			wantToken:  "ghe-token",
+			wantSource: "gh auth token",
+			wantArgs:   "auth token --hostname ghe.example.com",
+		},
+		{
+			name:       "enterprise host doesn't get github.com tokens",
+			toolToken:  "tool-token",
+			envToken:   "env-token",
+			ghToken:    "ghe-token",
+			host:       "ghe.example.com",
+			wantToken:  "ghe-token",
+			wantSource: "gh auth token",
+			wantArgs:   "auth token --hostname ghe.example.com",
+		},
+		{
+			name:       "GH_ENTERPRISE_TOKEN for an enterprise host",
+			envToken:   "env-token",
+			gheToken:   "enterprise-token",
+			ghToken:    "ghe-token",
+			host:       "ghe.example.com",
+			wantToken:  "enterprise-token",
+			wantSource: "GH_ENTERPRISE_TOKEN",
+		},
+		{
+			name:       "github.com named explicitly",
+			envToken:   "env-token",
+			host:       "github.com",
+			wantToken:  "env-token",
+			wantSource: "GITHUB_TOKEN",
+		},
+		{name: "gh not logged in", ghExitCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := writeFakeGH(t, tt.ghToken, tt.ghExitCode)
+			t.Setenv("GH_INSTALL_TOKEN", tt.toolToken)
+			t.Setenv("GITHUB_TOKEN", tt.envToken)
+			t.Setenv("GH_ENTERPRISE_TOKEN", tt.gheToken)
+			t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+
+			token, source := ResolveToken(tt.host)
+			if token != tt.wantToken || source != tt.wantSource {
+				t.Errorf("ResolveToken() = %q, %q; want %q, %q",
+					token, source, tt.wantToken, tt.wantSource)
+			}
+			if tt.wantArgs == "" {
+				return
+			}
+			args, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("Failed to read recorded gh arguments: %v", err)
+			}
+			if got := strings.TrimSpace(string(args)); got != tt.wantArgs {
+				t.Errorf("gh called with %q, want %q", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestResolveToken_NoGH(t *testing.T) {
+	CreateLogger(true)
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("GH_INSTALL_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	if token, source := ResolveToken(""); token != "" || source != "" {
+		t.Errorf("ResolveToken() = %q, %q; want no token", token, source)
+	}
+}
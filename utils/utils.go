@@ -4,12 +4,18 @@ package utils
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
 
 	"github.com/charmbracelet/log"
 )
@@ -18,8 +24,32 @@ var (
 	// Global logger instance used across the package
 	Logger *log.Logger
 
-	// Pre-compiled regular expressions for matching OS/architecture in filenames
-	osArchRegexes []*regexp.Regexp
+	// Matcher for the platform set with GetOSArch or GetOSArchFor, used by MatchFile and
+	// MatchFallbackFile; nil until then
+	platformMatcher atomic.Pointer[Matcher]
+
+	// Extra architecture names registered with AddArchAlias, keyed by GOARCH and guarded
+	// by archAliasesMu, as concurrent installs compile patterns while they may be added
+	archAliases   = map[string][]string{}
+	archAliasesMu sync.RWMutex
+
+	// Matches the version in a release asset name: semver (1.2.3), two-part (1.2) and
+	// calendar (2024.06.01) versions, or an eight digit date (20240601)
+	binaryVersionRegex = regexp.MustCompile(`[_-]v?(?:\d{4}\.\d{2}\.\d{2}|\d+\.\d+|\d{8}(?:[_.-]|$))`)
+
+	// OS and architecture tokens trimmed from the end of release asset names without a
+	// version, including the parts of Rust target triples (x86_64-unknown-linux-gnu);
+	// "x86_64" is split into "x86" and "64"
+	platformTokens = map[string]bool{
+		"linux": true, "darwin": true, "macos": true, "osx": true, "windows": true,
+		"win": true, "win64": true, "win32": true, "freebsd": true, "openbsd": true,
+		"netbsd": true, "android": true, "amd64": true, "x86": true, "x64": true, "64": true,
+		"386": true, "i386": true, "i686": true, "arm64": true, "aarch64": true, "arm": true,
+		"armv6": true, "armv7": true, "armhf": true, "64bit": true, "32bit": true,
+		"universal": true, "universal2": true, "musl": true, "gnu": true, "static": true,
+		"unknown": true, "pc": true, "apple": true, "msvc": true, "gnueabihf": true,
+		"musleabihf": true,
+	}
 
 	// Compile regex patterns once at package level
 	// checksumFileRegex = regexp.MustCompile(`(?i)_?checksums?\.txt$|_?checksums?`)
@@ -28,9 +58,11 @@ var (
 // ParsedArgs holds the parsed components of the argument string.
 // This represents the GitHub repository and version information.
 type ParsedArgs struct {
-	Owner   string // Repository owner (user or organization)
-	Repo    string // Repository name
-	Version string // Will be "latest" or a specific tag
+	Owner      string // Repository owner (user or organization)
+	Repo       string // Repository name
+	Version    string // Will be "latest", a specific tag, or a constraint expression
+	Constraint string // Semver constraint (e.g. "^1.2.0"), empty when Version is a literal tag
+	Commit     string // Commit SHA (e.g. "3f2a9c1"), empty when Version is not a commit
 }
 
 // ParseArgs parses an argument string in the format owner/repo[@version].
@@ -38,6 +70,8 @@ type ParsedArgs struct {
 // - owner/repo (version defaults to "latest")
 // - owner/repo@latest
 // - owner/repo@vX.Y.Z (or any other tag)
+// - owner/repo@^1.2.0, owner/repo@">=2.0.0 <3.0.0" (semver constraints)
+// - owner/repo@3f2a9c1 (the release created from a commit, see IsCommitSHA)
 //
 // -argString: The input string to parse.
 // Returns:
@@ -74,26 +108,24 @@ func ParseArgs(argString string) (ParsedArgs, error) {
 		}
 
 		// Now parse the owner/repo part
-		orParts := strings.Split(ownerRepoPart, "/")
-		if len(orParts) != 2 || orParts[0] == "" || orParts[1] == "" {
+		var err error
+		owner, repo, err = ParseOwnerRepo(ownerRepoPart)
+		if err != nil {
 			return ParsedArgs{}, fmt.Errorf(
 				"invalid owner/repo format '%s' before '@': expected owner/repo",
 				ownerRepoPart,
 			)
 		}
-		owner = orParts[0]
-		repo = orParts[1]
 	} else { // Does not contain "@"
 		// Format must be owner/repo, version is implicitly "latest"
-		orParts := strings.Split(argString, "/")
-		if len(orParts) != 2 || orParts[0] == "" || orParts[1] == "" {
+		var err error
+		owner, repo, err = ParseOwnerRepo(argString)
+		if err != nil {
 			return ParsedArgs{}, fmt.Errorf(
 				"invalid owner/repo format '%s': expected owner/repo or owner/repo@version",
 				argString,
 			)
 		}
-		owner = orParts[0]
-		repo = orParts[1]
 		version = "latest" // Default version
 		Logger.Debugf("Owner: %s", owner)
 		Logger.Debugf("Repo: %s", repo)
@@ -109,7 +141,63 @@ func ParseArgs(argString string) (ParsedArgs, error) {
 		return ParsedArgs{}, fmt.Errorf("invalid characters in repo '%s'", repo)
 	}
 
-	return ParsedArgs{Owner: owner, Repo: repo, Version: version}, nil
+	// Versions starting with a comparison operator are semver constraints that are
+	// resolved against the repository's releases rather than used as a literal tag.
+	var constraint string
+	if IsConstraint(version) {
+		if _, err := ParseConstraint(version); err != nil {
+			return ParsedArgs{}, err
+		}
+		constraint = version
+	}
+	var commit string
+	if IsCommitSHA(version) {
+		commit = strings.ToLower(version)
+	}
+
+	return ParsedArgs{
+		Owner:      owner,
+		Repo:       repo,
+		Version:    version,
+		Constraint: constraint,
+		Commit:     commit,
+	}, nil
+}
+
+// ParseOwnerRepo splits s, in the format owner/repo, into the repository owner and name.
+// Returns: An error if s doesn't have exactly one '/' between a non-empty owner and
+// repository name, or contains an '@'.
+func ParseOwnerRepo(s string) (owner, repo string, err error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.Contains(s, "@") {
+		return "", "", fmt.Errorf("invalid owner/repo format '%s': expected owner/repo", s)
+	}
+	return parts[0], parts[1], nil
+}
+
+// Lengths of an abbreviated and of a full commit SHA.
+const (
+	minCommitSHALength = 7
+	maxCommitSHALength = 40
+)
+
+// IsCommitSHA reports whether version looks like a (possibly abbreviated) commit SHA:
+// 7 to 40 hex digits. Versions made only of decimal digits are treated as tags, as
+// date-based tags such as 20240101 are far more common than such SHAs.
+func IsCommitSHA(version string) bool {
+	if len(version) < minCommitSHALength || len(version) > maxCommitSHALength {
+		return false
+	}
+	hasLetter := false
+	for _, r := range strings.ToLower(version) {
+		switch {
+		case r >= 'a' && r <= 'f':
+			hasLetter = true
+		case r < '0' || r > '9':
+			return false
+		}
+	}
+	return hasLetter
 }
 
 // GetOSArch identifies the current operating system and architecture,
@@ -117,9 +205,109 @@ func ParseArgs(argString string) (ParsedArgs, error) {
 // This prepares the system to identify assets that are compatible with the current machine.
 func GetOSArch() {
 	// Get the current system's OS and architecture from Go runtime
-	osName := runtime.GOOS
-	arch := runtime.GOARCH
+	GetOSArchFor(runtime.GOOS, runtime.GOARCH)
+}
+
+// GetOSArchFor creates the regular expressions matching release assets built for
+// another platform, e.g. to download arm64 binaries on an amd64 machine.
+//
+// -osName: The operating system, as in runtime.GOOS.
+// -arch: The architecture, as in runtime.GOARCH.
+func GetOSArchFor(osName, arch string) {
+	platformMatcher.Store(NewMatcher(osName, arch))
+}
+
+// goARM returns the ARM version (e.g. "6" or "7") gh-install was built for.
+// The GOARM environment variable takes precedence over the value recorded in the
+// binary's build info; "7" is assumed when neither is set.
+func goARM() string {
+	if v := os.Getenv("GOARM"); v != "" {
+		return normalizeGOARM(v)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "GOARM" && setting.Value != "" {
+				return normalizeGOARM(setting.Value)
+			}
+		}
+	}
+	return "7"
+}
+
+// normalizeGOARM strips the optional float ABI suffix, e.g. "6,softfloat" -> "6".
+func normalizeGOARM(v string) string {
+	v, _, _ = strings.Cut(v, ",")
+	return strings.TrimSpace(v)
+}
+
+// archSeparator matches the characters release assets use to delimit the
+// architecture from the surrounding tokens, e.g. the "_" and "." in "tool_linux_amd64.tar.gz".
+const archSeparator = `[-_./]`
+
+// armArchPatterns returns the architecture names used by release assets built for
+// the given ARM version.
+//
+// -goarm: The ARM version, e.g. "6" or "7".
+// Returns: The regex patterns to use in place of Go's "arm".
+func armArchPatterns(goarm string) []string {
+	switch goarm {
+	case "5":
+		return []string{"armv5", "armel", "arm"}
+	case "6":
+		return []string{"armv6", "arm"}
+	default: // 7
+		return []string{"armv7l?", "armhf", "arm"}
+	}
+}
+
+// darwinUniversalArchPatterns are the architecture names of macOS universal (fat)
+// binaries, which run natively on both amd64 and arm64.
+var darwinUniversalArchPatterns = []string{"universal2?", "all"}
+
+// osAliases lists other names release assets use for an OS, keyed by GOOS. They are
+// short or common words ("win" ends "darwin"), so they only match at the start of a
+// separator-delimited token.
+var osAliases = map[string][]string{
+	"darwin":  {"osx", "apple"},
+	"windows": {"win"},
+}
+
+// fusedOSArchTokens lists tokens that combine the OS and architecture, keyed by
+// "GOOS/GOARCH", e.g. "tool_win64.zip".
+var fusedOSArchTokens = map[string][]string{
+	"windows/amd64": {"win64"},
+	"windows/386":   {"win32"},
+}
+
+// Matcher tells whether release assets are built for a platform. It's immutable once
+// created, so one Matcher per platform can be shared by concurrent installs.
+type Matcher struct {
+	// Regular expressions matching the OS/architecture in filenames
+	regexes []*regexp.Regexp
+	// Regular expressions for assets that run through emulation, e.g. darwin/amd64
+	// assets on Apple Silicon; nil when there's no fallback
+	fallback []*regexp.Regexp
+	// Whether MatchFallback rejects every asset, see WithoutFallback
+	noFallback bool
+}
 
+// NewMatcher compiles the regular expressions matching release assets built for a
+// platform, including the architecture aliases registered with AddArchAlias so far.
+//
+// -osName: The operating system, as in runtime.GOOS; matched case-insensitively.
+// -arch: The architecture, as in runtime.GOARCH; matched case-insensitively.
+// Returns: The matcher.
+func NewMatcher(osName, arch string) *Matcher {
+	return newMatcher(strings.ToLower(osName), strings.ToLower(arch), goARM())
+}
+
+// newMatcher compiles the OS/architecture regexes for the given platform.
+//
+// -osName: The operating system, as in runtime.GOOS.
+// -arch: The architecture, as in runtime.GOARCH.
+// -goarm: The ARM version, only used when arch is "arm".
+func newMatcher(osName, arch, goarm string) *Matcher {
+	m := &Matcher{}
 	// Build OS patterns - OS name and common alternatives used in release asset naming
 	var osPatterns []string
 	osPatterns = append(osPatterns, regexp.QuoteMeta(osName))
@@ -132,12 +320,57 @@ func GetOSArch() {
 			regexp.QuoteMeta("macos"),
 		) // Common alternative for darwin (e.g., trivy uses macOS-64bit)
 	}
+	var aliasPatterns []string
+	for _, alias := range osAliases[osName] {
+		aliasPatterns = append(aliasPatterns, regexp.QuoteMeta(alias))
+	}
+
+	archPatterns := archNamePatterns(arch, goarm)
+	if osName == "darwin" {
+		// Universal binaries match regardless of the architecture
+		archPatterns = append(archPatterns, darwinUniversalArchPatterns...)
+	}
+
+	Logger.Debug("Compiling OS/Arch regex patterns...")
+	m.regexes = compileOSArchPatterns(osPatterns, aliasPatterns, archPatterns)
+	for _, token := range fusedOSArchTokens[osName+"/"+arch] {
+		pattern := fmt.Sprintf(
+			"(?i)(?:^|.*%s)%s(?:%s|$)",
+			archSeparator,
+			regexp.QuoteMeta(token),
+			archSeparator,
+		)
+		Logger.Debugf("  Pattern %d: %s", len(m.regexes), pattern)
+		m.regexes = append(m.regexes, regexp.MustCompile(pattern))
+	}
 
+	// Apple Silicon Macs can run amd64 binaries through Rosetta 2
+	if osName == "darwin" && arch == "arm64" {
+		Logger.Debug("Compiling Rosetta fallback regex patterns...")
+		m.fallback = compileOSArchPatterns(
+			osPatterns,
+			aliasPatterns,
+			archNamePatterns("amd64", ""),
+		)
+	}
+	Logger.Debug("OS/Arch regex compilation complete.")
+	return m
+}
+
+// archNamePatterns returns the regex patterns of the names release assets use for
+// the given architecture.
+//
+// -arch: The architecture, as in runtime.GOARCH.
+// -goarm: The ARM version, only used when arch is "arm".
+// Returns: Go's name and common alternatives, plus aliases registered with AddArchAlias.
+func archNamePatterns(arch, goarm string) []string {
 	// Create architecture mappings for common variants
 	var archPatterns []string
 
-	// Add the default Go architecture name
-	archPatterns = append(archPatterns, regexp.QuoteMeta(arch))
+	// Add the default Go architecture name; arm is handled below with its sub-architectures
+	if arch != "arm" {
+		archPatterns = append(archPatterns, regexp.QuoteMeta(arch))
+	}
 
 	// Add common alternative architecture names that are used in releases
 	// These handle different naming conventions used by various projects
@@ -156,60 +389,157 @@ func GetOSArch() {
 		) // Used by some projects (e.g., trivy uses Linux-32bit)
 	case "arm64":
 		archPatterns = append(archPatterns, "aarch64") // Common alternative for arm64
+	case "arm":
+		archPatterns = append(archPatterns, armArchPatterns(goarm)...) // e.g. armv7, armhf
+	}
+	archAliasesMu.RLock()
+	for _, alias := range archAliases[arch] {
+		archPatterns = append(archPatterns, regexp.QuoteMeta(alias)) // Registered with AddArchAlias
 	}
+	archAliasesMu.RUnlock()
+	return archPatterns
+}
 
+// compileOSArchPatterns compiles a regex for every combination of the OS and
+// architecture patterns. OS aliases must start a separator-delimited token.
+func compileOSArchPatterns(osPatterns, aliasPatterns, archPatterns []string) []*regexp.Regexp {
 	// Create all combinations of OS and architecture patterns
-	// This handles different formats that projects may use for naming assets
+	// This handles different formats that projects may use for naming assets.
+	// The architecture must be delimited by a separator or the start/end of the name,
+	// so e.g. "arm" doesn't match inside "arm64" and "amd64" assets aren't picked on arm64.
+	archStart := fmt.Sprintf("(?:^|.*%s)", archSeparator)
+	archEnd := fmt.Sprintf("(?:%s|$)", archSeparator)
 	var patterns []string
+	addPatterns := func(osPattern, osStart, osAfterArch, archPattern string) {
+		archToken := fmt.Sprintf("(?:^|%s)%s%s", archSeparator, archPattern, archEnd)
+		// Separators: -, _, / or just contains both words anywhere
+		// These cover formats like: linux-amd64, linux_amd64, linux/amd64
+		patterns = append(
+			patterns,
+			fmt.Sprintf("(?i)%s%s[-_/]%s%s", osStart, osPattern, archPattern, archEnd),
+		) // os<sep>arch
+		patterns = append(
+			patterns,
+			fmt.Sprintf("(?i)%s%s[-_/]%s.*", archStart, archPattern, osPattern),
+		) // arch<sep>os
+		patterns = append(
+			patterns,
+			fmt.Sprintf(
+				"(?i)(%s%s.*%s.*|.*%s%s%s.*)",
+				osStart,
+				osPattern,
+				archToken,
+				archToken,
+				osAfterArch,
+				osPattern,
+			),
+		) // Contains both, any order
+	}
 	for _, osPattern := range osPatterns {
 		for _, archPattern := range archPatterns {
-			// Separators: -, _, / or just contains both words anywhere
-			// These cover formats like: linux-amd64, linux_amd64, linux/amd64
-			patterns = append(
-				patterns,
-				fmt.Sprintf("(?i).*%s[-_/]%s.*", osPattern, archPattern),
-			) // os<sep>arch
-			patterns = append(
-				patterns,
-				fmt.Sprintf("(?i).*%s[-_/]%s.*", archPattern, osPattern),
-			) // arch<sep>os
-			patterns = append(
-				patterns,
-				fmt.Sprintf(
-					"(?i)(.*%s.*%s.*|.*%s.*%s.*)",
-					osPattern,
-					archPattern,
-					archPattern,
-					osPattern,
-				),
-			) // Contains both, any order
+			addPatterns(osPattern, ".*", ".*", archPattern)
+		}
+	}
+	// archToken may have consumed the separator in front of an alias
+	aliasAfterArch := fmt.Sprintf("(?:.*%s)?", archSeparator)
+	for _, aliasPattern := range aliasPatterns {
+		for _, archPattern := range archPatterns {
+			addPatterns(aliasPattern, archStart, aliasAfterArch, archPattern)
 		}
 	}
 
 	// Pre-compile all the patterns for better performance
-	osArchRegexes = make([]*regexp.Regexp, len(patterns))
-	Logger.Debugf("Compiling %d OS/Arch regex patterns...", len(patterns))
+	regexes := make([]*regexp.Regexp, len(patterns))
 	for i, pattern := range patterns {
-		osArchRegexes[i] = regexp.MustCompile(pattern)
+		regexes[i] = regexp.MustCompile(pattern)
 		Logger.Debugf("  Pattern %d: %s", i, pattern)
 	}
-	Logger.Debug("OS/Arch regex compilation complete.")
+	return regexes
 }
 
-// MatchFile checks if a filename matches the current OS and architecture patterns.
-// This determines if the given file is likely compatible with the current system.
+// AddArchAlias registers alias as another name release assets use for the Go
+// architecture goArch, e.g. "x64" for "amd64". Aliases take effect the next time the
+// patterns are compiled, so call GetOSArch or NewMatcher after registering them.
 //
-// -file: The filename to check against OS/architecture patterns.
-// Returns: true if the file matches any of the OS/architecture patterns, false otherwise.
+// -goArch: The architecture, as in runtime.GOARCH.
+// -alias: The name used in asset names, matched case-insensitively.
+// Returns: An error if either name is empty or contains whitespace.
+func AddArchAlias(goArch, alias string) error {
+	goArch, alias = strings.ToLower(goArch), strings.ToLower(alias)
+	for _, name := range []string{goArch, alias} {
+		if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+			return fmt.Errorf("invalid architecture alias '%s=%s'", goArch, alias)
+		}
+	}
+	archAliasesMu.Lock()
+	defer archAliasesMu.Unlock()
+	if !slices.Contains(archAliases[goArch], alias) {
+		archAliases[goArch] = append(archAliases[goArch], alias)
+	}
+	return nil
+}
+
+// WithoutFallback returns a copy of m whose MatchFallback accepts no assets, so
+// darwin/amd64 assets aren't used on Apple Silicon. m itself is left unchanged.
+func (m *Matcher) WithoutFallback() *Matcher {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.noFallback = true
+	return &c
+}
+
+// MatchFallbackFile checks if a filename matches an asset that runs on the current
+// system through emulation, see Matcher.MatchFallback.
+func MatchFallbackFile(file string) bool {
+	return platformMatcher.Load().MatchFallback(file)
+}
+
+// MatchFallback checks if a filename matches an asset that runs on the platform
+// through emulation, i.e. a darwin/amd64 asset on Apple Silicon, which Rosetta 2
+// translates. Only use it when no asset satisfies Match.
+//
+// -file: The filename to check against the fallback patterns.
+// Returns: true if the file is a usable fallback, false otherwise or when the fallback
+// is disabled with WithoutFallback.
+func (m *Matcher) MatchFallback(file string) bool {
+	if m == nil || m.noFallback {
+		return false
+	}
+	for _, re := range m.fallback {
+		if re.MatchString(file) {
+			Logger.Debugf("File '%s' matched fallback pattern: %s", file, re.String())
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFile checks if a filename matches the current OS and architecture patterns,
+// compiled with GetOSArch or GetOSArchFor. See Matcher.Match.
 func MatchFile(file string) bool {
 	// Ensure patterns have been compiled before checking
-	if len(osArchRegexes) == 0 {
+	m := platformMatcher.Load()
+	if m == nil {
 		Logger.Debug("Warning: OS/Arch regexes not initialized. Call GetOSArch() first.")
 		return false // No regexes to check against
 	}
+	return m.Match(file)
+}
+
+// Match checks if a filename matches the OS and architecture patterns of m.
+// This determines if the given file is likely compatible with the platform.
+//
+// -file: The filename to check against OS/architecture patterns.
+// Returns: true if the file matches any of the OS/architecture patterns, false otherwise.
+func (m *Matcher) Match(file string) bool {
+	if m == nil {
+		return false
+	}
 
 	// Check if the file matches any of the pre-compiled patterns
-	for i, re := range osArchRegexes {
+	for i, re := range m.regexes {
 		if re.MatchString(file) {
 			Logger.Debugf("File '%s' matched pattern %d: %s", file, i, re.String())
 			return true // Found a match
@@ -221,6 +551,67 @@ func MatchFile(file string) bool {
 	return false
 }
 
+// staticBuildRegex matches the name tokens of statically linked builds, e.g.
+// "tool-linux-amd64-static" or "tool-x86_64-unknown-linux-musl.tar.gz".
+var staticBuildRegex = regexp.MustCompile(`(?i)(?:^|[-_.])(?:static|musl[a-z]*)(?:[-_.]|$)`)
+
+// universalBuildRegex matches the name tokens of macOS universal binaries.
+var universalBuildRegex = regexp.MustCompile(
+	`(?i)(?:^|[-_.])(?:` + strings.Join(darwinUniversalArchPatterns, "|") + `)(?:[-_.]|$)`,
+)
+
+// IsStaticBuild reports whether an asset name marks a statically linked build, which
+// runs regardless of the system's C library.
+func IsStaticBuild(name string) bool {
+	return staticBuildRegex.MatchString(name)
+}
+
+// IsUniversalBuild reports whether an asset name marks a macOS universal binary, which
+// MatchFile accepts on both amd64 and arm64.
+func IsUniversalBuild(name string) bool {
+	return universalBuildRegex.MatchString(name)
+}
+
+// taggedChecksumRegex matches the BSD/GNU tagged format produced by `sha256sum --tag`
+// and BSD `sha256`, e.g. "SHA256 (tool.tar.gz) = abc123...".
+var taggedChecksumRegex = regexp.MustCompile(`^([A-Za-z0-9-]+)\s*\((.+)\)\s*=\s*([0-9A-Fa-f]+)$`)
+
+// parseChecksumLine parses a single checksum file entry, either in the classic
+// "<hash>  <filename>" (optionally "<algo>:<hash>  <filename>") layout or the tagged
+// "ALGO (filename) = hash" layout.
+//
+// -line: A trimmed, non-empty, non-comment line.
+// Returns: The algorithm (lowercase, only known for annotated lines), the checksum,
+// the normalized filename and false if the line isn't a checksum entry.
+func parseChecksumLine(line string) (algorithm, checksum, filename string, ok bool) {
+	if m := taggedChecksumRegex.FindStringSubmatch(line); m != nil {
+		algorithm, filename, checksum = strings.ToLower(m[1]), m[2], m[3]
+	} else {
+		parts := strings.Fields(line)
+		if len(parts) < 2 { //nolint:mnd
+			return "", "", "", false
+		}
+		checksum = parts[0]
+		// Some manifests annotate the hash with its algorithm, e.g. "sha256:abc123  file"
+		if prefix, rest, found := strings.Cut(checksum, ":"); found && IsKnownAlgorithm(prefix) {
+			algorithm, checksum = strings.ToLower(prefix), rest
+		}
+		// Filename in checksum files can be complex, often it's the last part.
+		// For `sha256sum` and `md5sum` output, it's usually the last non-option argument.
+		// A common pattern is `checksum  filename` or `checksum *filename`.
+		filename = parts[len(parts)-1]
+	}
+
+	// Normalize filename found in the checksum file
+	filename = strings.TrimPrefix(filename, "*") // Common for binary mode
+	filename = strings.TrimPrefix(filename, "./")
+	return algorithm, checksum, filename, true
+}
+
+// ErrChecksumNotFound is returned, wrapped, when a checksum file has no entry for the
+// requested file.
+var ErrChecksumNotFound = errors.New("checksum not found in checksum file")
+
 // ParseChecksumFile (your existing function)
 // Note: For matching, `targetFilename` should ideally be the base name of the file,
 // as checksum files usually list base names.
@@ -239,23 +630,12 @@ func ParseChecksumFile(checksumFilePath, targetFilename string) (string, error)
 			continue
 		}
 
-		parts := strings.Fields(line)
-		if len(parts) < 2 { //nolint:mnd
+		_, checksum, filenameInChecksum, ok := parseChecksumLine(line)
+		if !ok {
 			Logger.Debugf("skipping malformed line in checksum file: %s", line)
 			continue
 		}
 
-		checksum := parts[0]
-		// Filename in checksum files can be complex, often it's the last part,
-		// but some formats (like BSD sum) might have filename in middle.
-		// For `sha256sum` and `md5sum` output, it's usually the last non-option argument.
-		// A common pattern is `checksum  filename` or `checksum *filename`.
-		filenameInChecksum := parts[len(parts)-1]
-
-		// Normalize filename found in the checksum file
-		filenameInChecksum = strings.TrimPrefix(filenameInChecksum, "*") // Common for binary mode
-		filenameInChecksum = strings.TrimPrefix(filenameInChecksum, "./")
-
 		if filenameInChecksum == targetFilename {
 			Logger.Debug(
 				"found expected checksum '%s' for target '%s' in checksum file '%s'",
@@ -272,12 +652,131 @@ func ParseChecksumFile(checksumFilePath, targetFilename string) (string, error)
 	}
 
 	return "", fmt.Errorf(
-		"checksum for target '%s' not found in checksum file '%s'",
+		"%w: target '%s' in '%s'",
+		ErrChecksumNotFound,
 		targetFilename,
 		checksumFilePath,
 	)
 }
 
+// ParseChecksumFileMulti returns every checksum listed for targetFilename, keyed by
+// lowercase algorithm name, for manifests that annotate each line with its algorithm
+// (e.g. "SHA256 (file) = ..." next to "MD5 (file) = ..." or "sha256:...  file").
+// A checksum without an annotation is stored under the "" key.
+//
+// -checksumFilePath: Path to the checksum file.
+// -targetFilename: The base name of the asset to look up.
+// Returns: The checksums found, or an error if the file can't be read or lists none.
+func ParseChecksumFileMulti(checksumFilePath, targetFilename string) (map[string]string, error) {
+	safeChecksumFile := filepath.Clean(checksumFilePath)
+	file, err := os.Open(safeChecksumFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checksum file '%s': %w", safeChecksumFile, err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") { // Skip empty lines and comments
+			continue
+		}
+		algorithm, checksum, filename, ok := parseChecksumLine(line)
+		if !ok || filename != targetFilename {
+			continue
+		}
+		if _, seen := sums[algorithm]; !seen { // The first entry per algorithm wins
+			sums[algorithm] = checksum
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading checksum file '%s': %w", checksumFilePath, err)
+	}
+
+	if len(sums) == 0 {
+		return nil, fmt.Errorf(
+			"%w: target '%s' in '%s'",
+			ErrChecksumNotFound,
+			targetFilename,
+			checksumFilePath,
+		)
+	}
+	return sums, nil
+}
+
+// ResolveChecksumName returns the name targetFilename is listed under in a checksum file
+// that doesn't use its exact name. The candidates, in order, are:
+//   - targetFilename itself;
+//   - each of alternatives, e.g. the name of the archive a binary was extracted from;
+//   - an entry whose base name matches case-insensitively, e.g. "dist/Tool";
+//   - for a binary, the only non-archive entry for the same binary matcher accepts,
+//     e.g. "tool_1.0_linux_amd64" for "tool".
+//
+// -checksumFilePath: Path to the checksum file.
+// -targetFilename: The base name of the file to verify.
+// -matcher: The platform the file was built for; nil skips the last candidate.
+// -alternatives: Other exact names the file may be listed under.
+// Returns: The name to look up, which is targetFilename when no entry matches so the
+// lookup reports it as not found, or an error if the file can't be read.
+func ResolveChecksumName(
+	checksumFilePath, targetFilename string,
+	matcher *Matcher,
+	alternatives ...string,
+) (string, error) {
+	safeChecksumFile := filepath.Clean(checksumFilePath)
+	file, err := os.Open(safeChecksumFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open checksum file '%s': %w", safeChecksumFile, err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") { // Skip empty lines and comments
+			continue
+		}
+		if _, _, filename, ok := parseChecksumLine(line); ok && !slices.Contains(names, filename) {
+			names = append(names, filename)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("error reading checksum file '%s': %w", checksumFilePath, err)
+	}
+
+	for _, candidate := range append([]string{targetFilename}, alternatives...) {
+		if candidate != "" && slices.Contains(names, candidate) {
+			return candidate, nil
+		}
+	}
+	for _, name := range names {
+		if strings.EqualFold(filepath.Base(filepath.FromSlash(name)), targetFilename) {
+			return name, nil
+		}
+	}
+	if IsArchive(targetFilename) {
+		// Another archive of the same binary has different contents
+		return targetFilename, nil
+	}
+	binaryName := ParseBinaryName(targetFilename)
+	var matches []string
+	for _, name := range names {
+		base := filepath.Base(filepath.FromSlash(name))
+		if IsArchive(base) || IsChecksumFile(base) || IsSignatureFile(base) {
+			continue
+		}
+		if strings.EqualFold(ParseBinaryName(base), binaryName) && matcher.Match(base) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return targetFilename, nil
+}
+
 const (
 	// DefaultAlgorithmForGenericChecksums is the algorithm assumed for generic checksum files
 	// like "checksums.txt" when the algorithm cannot be derived from the filename.
@@ -307,7 +806,7 @@ func VerifyChecksum(
 	algoFromExt, found := GetAlgorithmFromFilename(checksumFilePath)
 	if found {
 		determinedAlgorithm = algoFromExt
-		Logger.Printf(
+		Logger.Infof(
 			"INFO: Using algorithm '%s' derived from checksum file extension: %s",
 			determinedAlgorithm,
 			checksumFilePath,
@@ -320,7 +819,7 @@ func VerifyChecksum(
 			)
 		}
 		determinedAlgorithm = defaultAlgoForGeneric
-		Logger.Printf("INFO: Checksum file '%s' has no algorithm extension. Using default/hint: '%s'", checksumFilePath, determinedAlgorithm)
+		Logger.Infof("INFO: Checksum file '%s' has no algorithm extension. Using default/hint: '%s'", checksumFilePath, determinedAlgorithm)
 	}
 
 	if _, err := GetHasher(determinedAlgorithm); err != nil {
@@ -343,7 +842,7 @@ func VerifyChecksum(
 	}
 
 	// Use assetPathOnDisk to calculate the hash of the actual local file
-	Logger.Printf(
+	Logger.Infof(
 		"INFO: Calculating %s checksum for local asset: %s",
 		strings.ToUpper(determinedAlgorithm),
 		assetPathOnDisk,
@@ -360,7 +859,7 @@ func VerifyChecksum(
 	}
 
 	if strings.EqualFold(expectedChecksum, actualChecksum) {
-		Logger.Printf(
+		Logger.Infof(
 			"SUCCESS: Checksum VALID for '%s' (original name: '%s'). Expected: %s, Actual: %s (Algorithm: %s)",
 			assetPathOnDisk,
 			assetNameInChecksumFile,
@@ -388,10 +887,13 @@ func VerifyChecksum(
 	)
 }
 
-func ChmodFile(filePath string) {
+// ChmodFile adds the execute bits for user, group and other to the file at filePath,
+// keeping its other permission bits.
+// Returns: An error if the file can't be stat'ed or its mode can't be changed.
+func ChmodFile(filePath string) error {
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
-		log.Fatalf("Failed to get file info for '%s': %v", filePath, err)
+		return fmt.Errorf("failed to get file info for '%s': %w", filePath, err)
 	}
 
 	// 2. Get the current permission mode
@@ -421,13 +923,13 @@ func ChmodFile(filePath string) {
 	// So, newMode (which is just permission bits) is fine here.
 	err = os.Chmod(filePath, newMode)
 	if err != nil {
-		Logger.Fatalf("Failed to chmod file '%s': %v", filePath, err)
+		return fmt.Errorf("failed to chmod file '%s': %w", filePath, err)
 	}
 
 	// 5. Verify new permissions (optional)
 	fileInfoAfter, err := os.Stat(filePath)
 	if err != nil {
-		Logger.Fatalf("Failed to get file info after chmod for '%s': %v", filePath, err)
+		return fmt.Errorf("failed to get file info after chmod for '%s': %w", filePath, err)
 	}
 	modeAfterChmod := fileInfoAfter.Mode()
 	Logger.Debugf(
@@ -447,14 +949,30 @@ func ChmodFile(filePath string) {
 	if modeAfterChmod&S_IXOTH != 0 {
 		Logger.Debug("Execute permission for Other is SET.")
 	}
+	return nil
 }
 
+// ParseBinaryName derives the name to save a binary as from its release asset name by
+// cutting it at the version, e.g. tool_v1.2.3_linux_amd64, tool-1.2-linux-amd64,
+// tool_2024.06.01_linux_amd64 and tool_20240601_linux_amd64 all yield "tool". Names
+// without a version have their trailing OS/arch tokens stripped instead, and as a last
+// resort are cut at the first '-' or '_'. A ".exe" extension is preserved, e.g.
+// tool_v1.2.3_windows_amd64.exe yields "tool.exe".
 func ParseBinaryName(assetName string) (binaryName string) {
-	regex := regexp.MustCompile(`[_-]v?\d+\.\d+\.\d+`)
+	if ext := filepath.Ext(assetName); strings.EqualFold(ext, exeSuffix) {
+		return parseBinaryName(strings.TrimSuffix(assetName, ext)) + ext
+	}
+	return parseBinaryName(assetName)
+}
 
-	match := regex.FindStringIndex(assetName)
+// parseBinaryName implements ParseBinaryName for asset names without a ".exe" extension.
+func parseBinaryName(assetName string) (binaryName string) {
+	match := binaryVersionRegex.FindStringIndex(assetName)
 
 	if match == nil {
+		if name, ok := trimPlatformSuffix(assetName); ok {
+			return name
+		}
 		// If the version pattern is not found, try and parse based off simpler regex
 		regex := regexp.MustCompile("[-|_]")
 		result := regex.Split(assetName, -1)
@@ -466,7 +984,82 @@ func ParseBinaryName(assetName string) (binaryName string) {
 	return assetName[0:match[0]]
 }
 
-// helper function for testing
-func resetOsArchRegexesForTesting() {
-	osArchRegexes = nil
+// exeSuffix is the extension Windows requires to run a binary.
+const exeSuffix = ".exe"
+
+// ExecutableName returns name with a ".exe" extension when goos is "windows", where a
+// binary without it can't be run, and name unchanged otherwise.
+func ExecutableName(name, goos string) string {
+	if goos != "windows" || strings.EqualFold(filepath.Ext(name), exeSuffix) {
+		return name
+	}
+	return name + exeSuffix
+}
+
+// VersionedName inserts "-<version>" into name before its ".exe" extension, if any,
+// e.g. tool-v1.2.3 or tool-v1.2.3.exe.
+func VersionedName(name, version string) string {
+	ext := filepath.Ext(name)
+	if !strings.EqualFold(ext, exeSuffix) {
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + "-" + version + ext
+}
+
+// trimPlatformSuffix strips the '-' or '_' separated OS/arch tokens (see platformTokens)
+// from the end of name, ignoring file extensions, e.g. tool-foo_linux_amd64.tar.gz
+// yields "tool-foo". The first token is always kept.
+// Returns: The trimmed name and whether any token was stripped.
+func trimPlatformSuffix(name string) (string, bool) {
+	trimmed := false
+	for {
+		i := strings.LastIndexAny(name, "-_")
+		if i <= 0 {
+			return name, trimmed
+		}
+		token, _, _ := strings.Cut(name[i+1:], ".")
+		if !platformTokens[strings.ToLower(token)] {
+			return name, trimmed
+		}
+		name, trimmed = name[:i], true
+	}
+}
+
+// IsDirOnPath reports whether dir is one of the directories listed in the PATH
+// environment variable. Paths are compared after making them absolute and cleaning
+// them; on Windows the comparison ignores case.
+func IsDirOnPath(dir string) bool {
+	want, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry == "" {
+			continue
+		}
+		got, err := filepath.Abs(entry)
+		if err != nil {
+			continue
+		}
+		if got == want || (runtime.GOOS == "windows" && strings.EqualFold(got, want)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandPath expands environment variables ($HOME/bin, ${XDG_BIN_HOME}) in path and
+// a leading "~" (~/bin, but not ~user/bin) to the current user's home directory.
+// Returns: The expanded path, or an error if the home directory can't be determined.
+func ExpandPath(path string) (string, error) {
+	path = os.ExpandEnv(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") &&
+		!(runtime.GOOS == "windows" && strings.HasPrefix(path, `~\`)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand '~' in '%s': %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
 }
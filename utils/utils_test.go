@@ -5,7 +5,10 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -168,6 +171,16 @@ func Test_IsChecksumFile(t *testing.T) {
 			args: args{"readme.txt"},
 			want: false,
 		},
+		{
+			name: "signature of checksum file",
+			args: args{"checksums.txt.sig"},
+			want: false,
+		},
+		{
+			name: "certificate of checksum file",
+			args: args{"checksums.txt.pem"},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -251,10 +264,318 @@ func TestMatchFile(t *testing.T) {
 	}
 }
 
+func TestMatchFileARM(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name  string
+		goarm string
+		file  string
+		want  bool
+	}{
+		{name: "armv7", goarm: "7", file: "tool_1.0.0_linux_armv7.tar.gz", want: true},
+		{name: "armv7l", goarm: "7", file: "tool_1.0.0_Linux_armv7l.tar.gz", want: true},
+		{name: "armhf", goarm: "7", file: "tool_1.0.0_linux_armhf.deb", want: true},
+		{name: "bare arm", goarm: "7", file: "tool_1.0.0_linux_arm.tar.gz", want: true},
+		{name: "bare arm at end", goarm: "7", file: "tool-linux-arm", want: true},
+		{name: "arm64 on armv7", goarm: "7", file: "tool_1.0.0_linux_arm64.tar.gz", want: false},
+		{name: "armv6 on armv7", goarm: "7", file: "tool_1.0.0_linux_armv6.tar.gz", want: false},
+		{name: "armv6", goarm: "6", file: "tool_1.0.0_linux_armv6.tar.gz", want: true},
+		{name: "armv7 on armv6", goarm: "6", file: "tool_1.0.0_linux_armv7.tar.gz", want: false},
+		{name: "arm64 on armv6", goarm: "6", file: "tool_1.0.0_linux_arm64.tar.gz", want: false},
+		{name: "armel on armv5", goarm: "5", file: "tool_1.0.0_linux_armel.deb", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newMatcher("linux", "arm", tt.goarm).Match(tt.file); got != tt.want {
+				t.Errorf("Match(%q) with GOARM=%s = %v, want %v", tt.file, tt.goarm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFileRejectsOtherArch(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name string
+		arch string
+		file string
+		want bool
+	}{
+		{name: "amd64 accepts amd64", arch: "amd64", file: "tool_1.0.0_linux_amd64.tar.gz", want: true},
+		{name: "amd64 accepts x86_64 first", arch: "amd64", file: "tool-x86_64-linux.tgz", want: true},
+		{name: "amd64 rejects arm64", arch: "amd64", file: "tool_1.0.0_linux_arm64.tar.gz", want: false},
+		{name: "amd64 rejects aarch64", arch: "amd64", file: "tool-aarch64-linux-gnu", want: false},
+		{name: "amd64 rejects suffixed arch", arch: "amd64", file: "tool_linux_amd64v3", want: false},
+		{name: "arm64 accepts arm64", arch: "arm64", file: "tool_1.0.0_linux_arm64.tar.gz", want: true},
+		{name: "arm64 accepts aarch64", arch: "arm64", file: "tool-aarch64-linux-gnu", want: true},
+		{name: "arm64 rejects amd64", arch: "arm64", file: "tool_1.0.0_linux_amd64.tar.gz", want: false},
+		{name: "arm64 rejects x86_64", arch: "arm64", file: "tool_Linux_x86_64.tgz", want: false},
+		{name: "386 rejects amd64", arch: "386", file: "tool_1.0.0_linux_amd64.tar.gz", want: false},
+		{name: "386 accepts i386", arch: "386", file: "tool_1.0.0_linux_i386.tar.gz", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newMatcher("linux", tt.arch, "").Match(tt.file); got != tt.want {
+				t.Errorf("Match(%q) on %s = %v, want %v", tt.file, tt.arch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddArchAlias(t *testing.T) {
+	CreateLogger(true)
+	t.Cleanup(func() { archAliases = map[string][]string{} })
+
+	if err := AddArchAlias("AMD64", "x64"); err != nil {
+		t.Fatalf("AddArchAlias() error = %v", err)
+	}
+	if err := AddArchAlias("amd64", "x64"); err != nil { // Registering twice is harmless
+		t.Fatalf("AddArchAlias() error = %v", err)
+	}
+	for _, invalid := range [][2]string{{"amd64", ""}, {"", "x64"}, {"amd64", "x 64"}} {
+		if err := AddArchAlias(invalid[0], invalid[1]); err == nil {
+			t.Errorf("AddArchAlias(%q, %q) succeeded, want error", invalid[0], invalid[1])
+		}
+	}
+	if got := archAliases["amd64"]; len(got) != 1 {
+		t.Errorf("archAliases[amd64] = %v, want [x64]", got)
+	}
+
+	tests := []struct {
+		name string
+		arch string
+		file string
+		want bool
+	}{
+		{name: "alias on amd64", arch: "amd64", file: "tool_linux_x64.tar.gz", want: true},
+		{name: "alias is case-insensitive", arch: "amd64", file: "Tool-Linux-X64.zip", want: true},
+		{name: "built-in names still match", arch: "amd64", file: "tool_linux_x86_64.tar.gz", want: true},
+		{name: "alias not used for arm64", arch: "arm64", file: "tool_linux_x64.tar.gz", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newMatcher("linux", tt.arch, "").Match(tt.file); got != tt.want {
+				t.Errorf("Match(%q) on %s = %v, want %v", tt.file, tt.arch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFileOSAliases(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name string
+		os   string
+		arch string
+		file string
+		want bool
+	}{
+		{name: "osx on darwin", os: "darwin", arch: "arm64", file: "tool_osx_arm64", want: true},
+		{name: "arch before osx", os: "darwin", arch: "arm64", file: "tool-arm64-osx.tar.gz", want: true},
+		{name: "macos on darwin", os: "darwin", arch: "arm64", file: "tool_macos_arm64.tar.gz", want: true},
+		{name: "apple on darwin", os: "darwin", arch: "amd64", file: "tool_x86_64_apple.zip", want: true},
+		{name: "win on windows", os: "windows", arch: "amd64", file: "tool-win-x86_64.zip", want: true},
+		{name: "win64 on windows/amd64", os: "windows", arch: "amd64", file: "tool_win64.zip", want: true},
+		{name: "win32 on windows/386", os: "windows", arch: "386", file: "tool_win32.zip", want: true},
+		{name: "win32 on windows/amd64", os: "windows", arch: "amd64", file: "tool_win32.zip", want: false},
+		{name: "darwin on windows", os: "windows", arch: "amd64", file: "tool_darwin_amd64.tar.gz", want: false},
+		{name: "darwin after arch on windows", os: "windows", arch: "amd64", file: "tool_amd64_darwin", want: false},
+		{name: "osx on linux", os: "linux", arch: "arm64", file: "tool_osx_arm64", want: false},
+		{name: "alias inside a word", os: "darwin", arch: "arm64", file: "pineapple_arm64", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newMatcher(tt.os, tt.arch, "").Match(tt.file); got != tt.want {
+				t.Errorf("Match(%q) on %s/%s = %v, want %v", tt.file, tt.os, tt.arch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOSArchFor(t *testing.T) {
+	CreateLogger(true)
+	t.Cleanup(GetOSArch)
+	GetOSArchFor("Windows", "ARM64")
+
+	tests := []struct {
+		name string
+		file string
+		want bool
+	}{
+		{name: "windows arm64 zip", file: "tool_1.0.0_windows_arm64.zip", want: true},
+		{name: "win aarch64", file: "tool-win-aarch64.zip", want: true},
+		{name: "windows amd64", file: "tool_1.0.0_windows_amd64.zip", want: false},
+		{name: "win64 is amd64", file: "tool_win64.zip", want: false},
+		{name: "linux arm64", file: "tool_1.0.0_linux_arm64.tar.gz", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchFile(tt.file); got != tt.want {
+				t.Errorf("MatchFile(%q) on windows/arm64 = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMatcher(t *testing.T) {
+	CreateLogger(true)
+	GetOSArchFor("linux", "amd64")
+	t.Cleanup(GetOSArch)
+
+	// Matchers for several platforms can be used side by side, concurrently
+	platforms := map[string]string{
+		"linux/amd64":   "tool_linux_amd64.tar.gz",
+		"Linux/ARM64":   "tool_linux_arm64.tar.gz",
+		"windows/amd64": "tool_windows_amd64.zip",
+		"darwin/arm64":  "tool_darwin_arm64.tar.gz",
+	}
+	var wg sync.WaitGroup
+	for platform := range platforms {
+		osName, arch, _ := strings.Cut(platform, "/")
+		m := NewMatcher(osName, arch)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for other, otherFile := range platforms {
+				if got, want := m.Match(otherFile), other == platform; got != want {
+					t.Errorf("NewMatcher(%q).Match(%q) = %v, want %v", platform, otherFile, got,
+						want)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if !MatchFile("tool_linux_amd64.tar.gz") || MatchFile("tool_linux_arm64.tar.gz") {
+		t.Error("NewMatcher() changed the patterns MatchFile uses")
+	}
+
+	var zero *Matcher
+	if zero.Match("tool_linux_amd64.tar.gz") || zero.MatchFallback("tool_darwin_amd64.tar.gz") {
+		t.Error("nil Matcher matched a file")
+	}
+}
+
+func TestMatchFileDarwinUniversal(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name string
+		os   string
+		arch string
+		file string
+		want bool
+	}{
+		{name: "universal on arm64", os: "darwin", arch: "arm64", file: "tool_darwin_universal.tar.gz", want: true},
+		{name: "universal2 on arm64", os: "darwin", arch: "arm64", file: "tool-macos-universal2.zip", want: true},
+		{name: "all on arm64", os: "darwin", arch: "arm64", file: "tool_1.0.0_darwin_all.tar.gz", want: true},
+		{name: "universal on amd64", os: "darwin", arch: "amd64", file: "tool_darwin_universal.tar.gz", want: true},
+		{name: "amd64 is not native on arm64", os: "darwin", arch: "arm64", file: "tool_darwin_amd64.tar.gz", want: false},
+		{name: "no universal on linux", os: "linux", arch: "arm64", file: "tool_linux_all.tar.gz", want: false},
+		{name: "all inside a word", os: "darwin", arch: "arm64", file: "installer_darwin.pkg", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newMatcher(tt.os, tt.arch, "").Match(tt.file); got != tt.want {
+				t.Errorf("Match(%q) on %s/%s = %v, want %v", tt.file, tt.os, tt.arch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFallbackFile(t *testing.T) {
+	CreateLogger(true)
+
+	tests := []struct {
+		name     string
+		os       string
+		arch     string
+		file     string
+		disabled bool
+		want     bool
+	}{
+		{name: "amd64 on apple silicon", os: "darwin", arch: "arm64", file: "tool_darwin_amd64.tar.gz", want: true},
+		{name: "x86_64 on apple silicon", os: "darwin", arch: "arm64", file: "tool-x86_64-apple-darwin.tar.gz", want: true},
+		{
+			name:     "disabled",
+			os:       "darwin",
+			arch:     "arm64",
+			file:     "tool_darwin_amd64.tar.gz",
+			disabled: true,
+			want:     false,
+		},
+		{name: "linux has no fallback", os: "linux", arch: "arm64", file: "tool_linux_amd64.tar.gz", want: false},
+		{name: "intel mac has no fallback", os: "darwin", arch: "amd64", file: "tool_darwin_arm64.tar.gz", want: false},
+		{name: "other os", os: "darwin", arch: "arm64", file: "tool_linux_amd64.tar.gz", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMatcher(tt.os, tt.arch, "")
+			if tt.disabled {
+				m = m.WithoutFallback()
+			}
+			if got := m.MatchFallback(tt.file); got != tt.want {
+				t.Errorf(
+					"MatchFallback(%q) on %s/%s = %v, want %v",
+					tt.file, tt.os, tt.arch, got, tt.want,
+				)
+			}
+		})
+	}
+}
+
+func TestIsStaticBuild(t *testing.T) {
+	tests := map[string]bool{
+		"tool-linux-amd64-static":               true,
+		"tool_static_linux_amd64.tar.gz":        true,
+		"tool-x86_64-unknown-linux-musl.tar.gz": true,
+		"tool-armv7-unknown-linux-musleabihf":   true,
+		"tool-x86_64-unknown-linux-gnu.tar.gz":  false,
+		"tool_linux_amd64.tar.gz":               false,
+		"staticcheck_linux_amd64.tar.gz":        false,
+	}
+	for name, want := range tests {
+		if got := IsStaticBuild(name); got != want {
+			t.Errorf("IsStaticBuild(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestIsUniversalBuild(t *testing.T) {
+	tests := map[string]bool{
+		"tool_darwin_universal.tar.gz": true,
+		"tool-macos-universal2.zip":    true,
+		"tool_1.0.0_darwin_all.tar.gz": true,
+		"tool_darwin_arm64.tar.gz":     false,
+		"tool-allinone_darwin_arm64":   false,
+	}
+	for name, want := range tests {
+		if got := IsUniversalBuild(name); got != want {
+			t.Errorf("IsUniversalBuild(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func Test_normalizeGOARM(t *testing.T) {
+	tests := map[string]string{
+		"7":           "7",
+		"6,softfloat": "6",
+		" 5 ":         "5",
+	}
+	for in, want := range tests {
+		if got := normalizeGOARM(in); got != want {
+			t.Errorf("normalizeGOARM(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestMatchFileNoGetOSArch(t *testing.T) {
 	CreateLogger(true)
 
-	resetOsArchRegexesForTesting()
+	defer platformMatcher.Store(platformMatcher.Load())
+	platformMatcher.Store(nil)
 
 	type args struct {
 		file string
@@ -357,6 +678,45 @@ func TestParseArgs(t *testing.T) {
 			want:    ParsedArgs{},
 			wantErr: true,
 		},
+		{
+			name: "owner/repo@^1.2.0",
+			args: args{argString: "owner/repo@^1.2.0"},
+			want: ParsedArgs{
+				Owner:      "owner",
+				Repo:       "repo",
+				Version:    "^1.2.0",
+				Constraint: "^1.2.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "owner/repo@commit",
+			args: args{argString: "owner/repo@3F2A9C1"},
+			want: ParsedArgs{
+				Owner:   "owner",
+				Repo:    "repo",
+				Version: "3F2A9C1",
+				Commit:  "3f2a9c1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "owner/repo@>=2.0.0 <3.0.0",
+			args: args{argString: "owner/repo@>=2.0.0 <3.0.0"},
+			want: ParsedArgs{
+				Owner:      "owner",
+				Repo:       "repo",
+				Version:    ">=2.0.0 <3.0.0",
+				Constraint: ">=2.0.0 <3.0.0",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "owner/repo@^not-a-version",
+			args:    args{argString: "owner/repo@^not-a-version"},
+			want:    ParsedArgs{},
+			wantErr: true,
+		},
 		{
 			name:    "owner\repo\\latest",
 			args:    args{argString: "owner\repo\foo\\latest"},
@@ -496,6 +856,179 @@ func TestParseChecksumFile(t *testing.T) {
 	}
 }
 
+func Test_parseChecksumLine(t *testing.T) {
+	hash := "a19aed32eaecf9f67274abd4e96fa97955ae82d04b37ff749f2e7b39815ef15c"
+	tests := []struct {
+		name         string
+		line         string
+		wantAlgo     string
+		wantChecksum string
+		wantFile     string
+		wantOK       bool
+	}{
+		{
+			name:         "classic two fields",
+			line:         hash + "  tool_linux_amd64.tar.gz",
+			wantChecksum: hash,
+			wantFile:     "tool_linux_amd64.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name:         "classic binary mode",
+			line:         hash + " *tool_linux_amd64.tar.gz",
+			wantChecksum: hash,
+			wantFile:     "tool_linux_amd64.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name:         "classic relative path",
+			line:         hash + "  ./tool_linux_amd64.tar.gz",
+			wantChecksum: hash,
+			wantFile:     "tool_linux_amd64.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name:         "GNU tagged",
+			line:         "SHA256 (tool_linux_amd64.tar.gz) = " + hash,
+			wantAlgo:     "sha256",
+			wantChecksum: hash,
+			wantFile:     "tool_linux_amd64.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name:         "BSD tagged",
+			line:         "sha256 (tool_linux_amd64.tar.gz) = " + hash,
+			wantAlgo:     "sha256",
+			wantChecksum: hash,
+			wantFile:     "tool_linux_amd64.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name:         "tagged with spaces in filename",
+			line:         "SHA512 (my tool.tar.gz)=" + hash,
+			wantAlgo:     "sha512",
+			wantChecksum: hash,
+			wantFile:     "my tool.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name:         "tagged binary mode",
+			line:         "SHA256 (*tool.tar.gz) = " + hash,
+			wantAlgo:     "sha256",
+			wantChecksum: hash,
+			wantFile:     "tool.tar.gz",
+			wantOK:       true,
+		},
+		{
+			name: "hash only",
+			line: hash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algo, checksum, file, ok := parseChecksumLine(tt.line)
+			if ok != tt.wantOK || algo != tt.wantAlgo || checksum != tt.wantChecksum ||
+				file != tt.wantFile {
+				t.Errorf(
+					"parseChecksumLine() = (%q, %q, %q, %v), want (%q, %q, %q, %v)",
+					algo, checksum, file, ok,
+					tt.wantAlgo, tt.wantChecksum, tt.wantFile, tt.wantOK,
+				)
+			}
+		})
+	}
+}
+
+func TestParseChecksumFile_Tagged(t *testing.T) {
+	CreateLogger(true)
+	hash := "a19aed32eaecf9f67274abd4e96fa97955ae82d04b37ff749f2e7b39815ef15c"
+	checksumFile := filepath.Join(t.TempDir(), "SHA256SUMS")
+	content := "SHA256 (other.tar.gz) = 0000\nSHA256 (tool.tar.gz) = " + hash + "\n"
+	if err := os.WriteFile(checksumFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	got, err := ParseChecksumFile(checksumFile, "tool.tar.gz")
+	if err != nil {
+		t.Fatalf("ParseChecksumFile() error = %v", err)
+	}
+	if got != hash {
+		t.Errorf("ParseChecksumFile() = %v, want %v", got, hash)
+	}
+}
+
+func TestResolveChecksumName(t *testing.T) {
+	CreateLogger(true)
+	matcher := NewMatcher("linux", "amd64")
+
+	tests := []struct {
+		name         string
+		content      string
+		target       string
+		alternatives []string
+		want         string
+	}{
+		{
+			name:    "exact name",
+			content: "aaaa  tool_1.0_linux_amd64.tar.gz\nbbbb  tool\n",
+			target:  "tool",
+			want:    "tool",
+		},
+		{
+			name:         "archive the binary was extracted from",
+			content:      "aaaa  tool_1.0_linux_amd64.tar.gz\nbbbb  tool_1.0_linux_amd64\n",
+			target:       "tool",
+			alternatives: []string{"tool_1.0_linux_amd64"},
+			want:         "tool_1.0_linux_amd64",
+		},
+		{
+			name:    "directory and case differ",
+			content: "aaaa  dist/Tool\n",
+			target:  "tool",
+			want:    "dist/Tool",
+		},
+		{
+			name:    "platform-qualified binary",
+			content: "aaaa  tool_1.0_darwin_arm64\nbbbb  tool_1.0_linux_amd64\n",
+			target:  "tool",
+			want:    "tool_1.0_linux_amd64",
+		},
+		{
+			name:    "only the archive is listed",
+			content: "aaaa  tool_1.0_linux_amd64.tar.gz\n",
+			target:  "tool",
+			want:    "tool",
+		},
+		{
+			name:    "another archive format",
+			content: "aaaa  tool_1.0_linux_amd64.zip\n",
+			target:  "tool_1.0_linux_amd64.tar.gz",
+			want:    "tool_1.0_linux_amd64.tar.gz",
+		},
+		{
+			name:    "ambiguous platform binaries",
+			content: "aaaa  tool_1.0_linux_amd64\nbbbb  tool-1.0-linux-x86_64\n",
+			target:  "tool",
+			want:    "tool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksumFile := filepath.Join(t.TempDir(), "checksums.txt")
+			if err := os.WriteFile(checksumFile, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			got, err := ResolveChecksumName(checksumFile, tt.target, matcher, tt.alternatives...)
+			if err != nil {
+				t.Fatalf("ResolveChecksumName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveChecksumName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseBinaryName(t *testing.T) {
 	type args struct {
 		file string
@@ -560,6 +1093,51 @@ func TestParseBinaryName(t *testing.T) {
 			args: args{"binary-windows-amd64"},
 			want: "binary",
 		},
+		{
+			name: "calendar version",
+			args: args{"tool_2024.06.01_linux_amd64"},
+			want: "tool",
+		},
+		{
+			name: "two-part version",
+			args: args{"app-1.2-linux-amd64"},
+			want: "app",
+		},
+		{
+			name: "date tag",
+			args: args{"tool_20240601_linux_amd64.tar.gz"},
+			want: "tool",
+		},
+		{
+			name: "hyphenated binary without version",
+			args: args{"binary-foo_linux_x86_64.tar.gz"},
+			want: "binary-foo",
+		},
+		{
+			name: "hyphenated binary without version with hyphens",
+			args: args{"binary-foo-darwin-arm64"},
+			want: "binary-foo",
+		},
+		{
+			name: "rust target triple",
+			args: args{"tool-x86_64-unknown-linux-gnu.tar.gz"},
+			want: "tool",
+		},
+		{
+			name: "windows binary",
+			args: args{"tool_windows_amd64.exe"},
+			want: "tool.exe",
+		},
+		{
+			name: "windows binary with version",
+			args: args{"tool_v1.2.3_windows_amd64.exe"},
+			want: "tool.exe",
+		},
+		{
+			name: "digits in name",
+			args: args{"k9s_Linux_amd64.tar.gz"},
+			want: "k9s",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -569,3 +1147,188 @@ func TestParseBinaryName(t *testing.T) {
 		})
 	}
 }
+
+func TestChmodFile(t *testing.T) {
+	CreateLogger(false)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "tool")
+	if err := os.WriteFile(existing, []byte("#!/bin/sh\n"), 0o640); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantMode os.FileMode
+		wantErr  bool
+	}{
+		{name: "adds execute bits", path: existing, wantMode: 0o751},
+		{name: "nonexistent path", path: filepath.Join(dir, "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ChmodFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChmodFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			info, err := os.Stat(tt.path)
+			if err != nil {
+				t.Fatalf("Failed to stat file: %v", err)
+			}
+			if info.Mode().Perm() != tt.wantMode {
+				t.Errorf("ChmodFile() mode = %04o, want %04o", info.Mode().Perm(), tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestIsDirOnPath(t *testing.T) {
+	onPath := t.TempDir()
+	other := t.TempDir()
+	// An empty entry means the current directory and must not match everything
+	pathList := []string{"", onPath + string(filepath.Separator)}
+	t.Setenv("PATH", strings.Join(pathList, string(os.PathListSeparator)))
+
+	tests := []struct {
+		name string
+		dir  string
+		want bool
+	}{
+		{name: "listed", dir: onPath, want: true},
+		{name: "listed with trailing separator", dir: onPath + string(filepath.Separator), want: true},
+		{name: "not listed", dir: other, want: false},
+		{name: "subdirectory", dir: filepath.Join(onPath, "bin"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDirOnPath(tt.dir); got != tt.want {
+				t.Errorf("IsDirOnPath(%q) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCommitSHA(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "3f2a9c1", want: true},
+		{version: "3F2A9C1", want: true},
+		{version: "0123456789abcdef0123456789abcdef01234567", want: true},
+		{version: "3f2a9c", want: false},                                    // Too short
+		{version: "0123456789abcdef0123456789abcdef012345678", want: false}, // Too long
+		{version: "20240101", want: false},                                  // Date-based tag
+		{version: "v1.2.3", want: false},
+		{version: "deadbeefz", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := IsCommitSHA(tt.version); got != tt.want {
+				t.Errorf("IsCommitSHA(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutableName(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		want string
+	}{
+		{name: "tool", goos: "windows", want: "tool.exe"},
+		{name: "tool.exe", goos: "windows", want: "tool.exe"},
+		{name: "tool.EXE", goos: "windows", want: "tool.EXE"},
+		{name: "tool", goos: "linux", want: "tool"},
+		{name: "tool.exe", goos: "linux", want: "tool.exe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.goos, func(t *testing.T) {
+			if got := ExecutableName(tt.name, tt.goos); got != tt.want {
+				t.Errorf("ExecutableName(%q, %q) = %q, want %q", tt.name, tt.goos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionedName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "tool", want: "tool-v1.2.3"},
+		{name: "tool.exe", want: "tool-v1.2.3.exe"},
+		{name: "tool.sh", want: "tool.sh-v1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VersionedName(tt.name, "v1.2.3"); got != tt.want {
+				t.Errorf("VersionedName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("GH_INSTALL_TEST_DIR", "tools")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "~", want: home},
+		{path: "~/bin", want: filepath.Join(home, "bin")},
+		{path: "$HOME/bin", want: home + "/bin"},
+		{path: "/opt/${GH_INSTALL_TEST_DIR}/bin", want: "/opt/tools/bin"},
+		{path: "~user/bin", want: "~user/bin"},
+		{path: "bin/~", want: "bin/~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ExpandPath(tt.path)
+			if err != nil {
+				t.Fatalf("ExpandPath(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOwnerRepo(t *testing.T) {
+	tests := []struct {
+		s         string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{s: "owner/repo", wantOwner: "owner", wantRepo: "repo"},
+		{s: "owner", wantErr: true},
+		{s: "owner/", wantErr: true},
+		{s: "/repo", wantErr: true},
+		{s: "owner/repo/extra", wantErr: true},
+		{s: "owner/repo@v1.0.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			owner, repo, err := ParseOwnerRepo(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOwnerRepo(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf(
+					"ParseOwnerRepo(%q) = %q, %q, want %q, %q",
+					tt.s, owner, repo, tt.wantOwner, tt.wantRepo,
+				)
+			}
+		})
+	}
+}